@@ -1,11 +1,13 @@
 package apps
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -13,18 +15,18 @@ import (
 // Deps holds injectable dependencies for testing.
 type Deps struct {
 	GetToken func() (string, error)
-	ListApps func(token string) ([]api.App, error)
-	GetApp   func(token, slug string) (*api.App, error)
+	ListApps func(ctx context.Context, token string) ([]api.App, error)
+	GetApp   func(ctx context.Context, token, slug string) (*api.App, error)
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		ListApps: func(token string) ([]api.App, error) {
-			return api.NewClient(token).ListApps()
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) {
+			return api.NewClient(token).ListApps(ctx)
 		},
-		GetApp: func(token, slug string) (*api.App, error) {
-			return api.NewClient(token).GetApp(slug)
+		GetApp: func(ctx context.Context, token, slug string) (*api.App, error) {
+			return api.NewClient(token).GetApp(ctx, slug)
 		},
 	}
 }
@@ -54,6 +56,7 @@ func NewInfoCmd() *cobra.Command {
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -64,7 +67,7 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner("Fetching eggs...")
 	sp.Start()
-	appList, err := deps.ListApps(token)
+	appList, err := deps.ListApps(ctx, token)
 	sp.Stop()
 
 	if err != nil {
@@ -89,6 +92,7 @@ func runList(cmd *cobra.Command, args []string) error {
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -101,7 +105,7 @@ func runInfo(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner("Fetching egg details...")
 	sp.Start()
-	app, err := deps.GetApp(token, slug)
+	app, err := deps.GetApp(ctx, token, slug)
 	sp.Stop()
 
 	if err != nil {
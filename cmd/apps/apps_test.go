@@ -2,6 +2,7 @@ package apps
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -56,7 +57,7 @@ func TestRunList_TokenError(t *testing.T) {
 func TestRunList_Empty(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		ListApps: func(token string) ([]api.App, error) { return nil, nil },
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) { return nil, nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -74,7 +75,7 @@ func TestRunList_Empty(t *testing.T) {
 func TestRunList_ShowsApps(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		ListApps: func(token string) ([]api.App, error) {
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) {
 			return []api.App{
 				{Slug: "myapp", Name: "My App", Status: "running", URL: "https://myapp.gethatch.eu"},
 				{Slug: "other", Name: "Other App", Status: "stopped", URL: "https://other.gethatch.eu"},
@@ -103,7 +104,7 @@ func TestRunList_ShowsApps(t *testing.T) {
 func TestRunList_GeneratesURLFromSlug(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		ListApps: func(token string) ([]api.App, error) {
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) {
 			return []api.App{
 				{Slug: "nourl-app", Name: "No URL App", Status: "running", URL: ""},
 			}, nil
@@ -125,7 +126,7 @@ func TestRunList_GeneratesURLFromSlug(t *testing.T) {
 func TestRunList_APIError(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		ListApps: func(token string) ([]api.App, error) {
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) {
 			return nil, fmt.Errorf("connection refused")
 		},
 	}
@@ -158,7 +159,7 @@ func TestRunInfo_NotLoggedIn(t *testing.T) {
 func TestRunInfo_ShowsDetails(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		GetApp: func(token, slug string) (*api.App, error) {
+		GetApp: func(ctx context.Context, token, slug string) (*api.App, error) {
 			if slug != "myapp" {
 				t.Fatalf("unexpected slug: %s", slug)
 			}
@@ -193,7 +194,7 @@ func TestRunInfo_ShowsDetails(t *testing.T) {
 func TestRunInfo_APIError(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		GetApp: func(token, slug string) (*api.App, error) {
+		GetApp: func(ctx context.Context, token, slug string) (*api.App, error) {
 			return nil, fmt.Errorf("not found")
 		},
 	}
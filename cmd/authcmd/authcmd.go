@@ -8,6 +8,7 @@ import (
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -32,7 +33,7 @@ type Deps struct {
 	OpenBrowser   func(url string) error
 	SaveToken     func(token string) error
 	ClearToken    func() error
-	ListKeys      func(token string) ([]api.APIKey, error)
+	ListKeys      func(ctx context.Context, token string) ([]api.APIKey, error)
 	GetTokenSource func() string
 }
 
@@ -47,8 +48,8 @@ func defaultDeps() *Deps {
 		OpenBrowser: auth.OpenBrowser,
 		SaveToken:   auth.SaveToken,
 		ClearToken:  auth.ClearToken,
-		ListKeys: func(token string) ([]api.APIKey, error) {
-			return api.NewClient(token).ListKeys()
+		ListKeys: func(ctx context.Context, token string) ([]api.APIKey, error) {
+			return api.NewClient(token).ListKeys(ctx)
 		},
 		GetTokenSource: getTokenSource,
 	}
@@ -182,6 +183,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 }
 
 func runKeys(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -192,7 +194,7 @@ func runKeys(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner("Fetching API keys...")
 	sp.Start()
-	keys, err := deps.ListKeys(token)
+	keys, err := deps.ListKeys(ctx, token)
 	sp.Stop()
 
 	if err != nil {
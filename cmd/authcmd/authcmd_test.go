@@ -215,7 +215,7 @@ func TestAuthKeysNotLoggedIn(t *testing.T) {
 func TestAuthKeysSuccess(t *testing.T) {
 	restore := setMockDeps(&Deps{
 		GetToken: func() (string, error) { return "test-token", nil },
-		ListKeys: func(token string) ([]api.APIKey, error) {
+		ListKeys: func(ctx context.Context, token string) ([]api.APIKey, error) {
 			return []api.APIKey{
 				{
 					ID:        "key-1",
@@ -241,7 +241,7 @@ func TestAuthKeysSuccess(t *testing.T) {
 func TestAuthKeysEmpty(t *testing.T) {
 	restore := setMockDeps(&Deps{
 		GetToken: func() (string, error) { return "test-token", nil },
-		ListKeys: func(token string) ([]api.APIKey, error) {
+		ListKeys: func(ctx context.Context, token string) ([]api.APIKey, error) {
 			return []api.APIKey{}, nil
 		},
 	})
@@ -260,7 +260,7 @@ func TestAuthKeysEmpty(t *testing.T) {
 func TestAuthKeysAPIError(t *testing.T) {
 	restore := setMockDeps(&Deps{
 		GetToken: func() (string, error) { return "test-token", nil },
-		ListKeys: func(token string) ([]api.APIKey, error) {
+		ListKeys: func(ctx context.Context, token string) ([]api.APIKey, error) {
 			return nil, fmt.Errorf("API error")
 		},
 	})
@@ -8,6 +8,7 @@ import (
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/spf13/cobra"
 )
@@ -66,7 +67,7 @@ This opens a Stripe checkout page in your browser to complete payment.`,
 
 			fmt.Fprintf(os.Stderr, "  Creating boost checkout for %s (%s)...\n", slug, duration)
 
-			result, err := client.BoostCheckout(slug, duration)
+			result, err := client.BoostCheckout(interrupt.FromCommand(cmd), slug, duration)
 			if err != nil {
 				return fmt.Errorf("creating boost checkout: %w", err)
 			}
@@ -1,11 +1,13 @@
 package credits
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -26,6 +28,7 @@ to give them temporary boost status without payment.`,
 }
 
 func listCredits(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := auth.GetToken()
 	if err != nil {
 		return fmt.Errorf("not logged in: %w (run 'hatch login' first)", err)
@@ -35,7 +38,7 @@ func listCredits(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner("Fetching boost credits...")
 	sp.Start()
-	credits, err := client.ListBoostCredits()
+	credits, err := client.ListBoostCredits(ctx)
 	sp.Stop()
 
 	if err != nil {
@@ -80,7 +83,7 @@ Examples:
   hatch credits apply --type week  # Apply a week credit (if available)`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runApply(args, creditType)
+			return runApply(interrupt.FromCommand(cmd), args, creditType)
 		},
 	}
 
@@ -89,7 +92,7 @@ Examples:
 	return cmd
 }
 
-func runApply(args []string, creditType string) error {
+func runApply(ctx context.Context, args []string, creditType string) error {
 	token, err := auth.GetToken()
 	if err != nil {
 		return fmt.Errorf("not logged in: %w (run 'hatch login' first)", err)
@@ -111,7 +114,7 @@ func runApply(args []string, creditType string) error {
 	// Fetch available credits
 	sp := ui.NewSpinner("Fetching available credits...")
 	sp.Start()
-	credits, err := client.ListBoostCredits()
+	credits, err := client.ListBoostCredits(ctx)
 	sp.Stop()
 
 	if err != nil {
@@ -143,7 +146,7 @@ func runApply(args []string, creditType string) error {
 	// Redeem the credit
 	sp = ui.NewSpinner(fmt.Sprintf("Applying %s boost credit to %s...", selectedCredit.Type, slug))
 	sp.Start()
-	result, err := client.RedeemBoostCredit(selectedCredit.ID, slug)
+	result, err := client.RedeemBoostCredit(ctx, selectedCredit.ID, slug)
 	sp.Stop()
 
 	if err != nil {
@@ -1,20 +1,19 @@
 package db
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
-	"os/signal"
 	"strings"
-	"sync"
-	"syscall"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/gorilla/websocket"
@@ -100,6 +99,7 @@ Use --no-psql to only start the tunnel without launching psql.`,
 }
 
 func runConnect(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -125,11 +125,10 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	}
 
 	addr := fmt.Sprintf("%s:%d", host, port)
-	listener, err := deps.Listen("tcp", addr)
+	tunnel, err := StartTunnel(token, slug, addr, false)
 	if err != nil {
-		return fmt.Errorf("binding %s: %w", addr, err)
+		return err
 	}
-	defer listener.Close()
 
 	// Warn if binding to non-loopback address
 	if host != "localhost" && host != "127.0.0.1" && host != "::1" {
@@ -139,7 +138,7 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	// Fetch database credentials for psql connection
 	client := api.NewClient(token)
 	var creds *dbCreds
-	dbURL, err := client.GetDatabaseURL(slug)
+	dbURL, err := client.GetDatabaseURL(ctx, slug)
 	if err == nil && dbURL != "" {
 		creds = parseDBURL(dbURL)
 	}
@@ -157,18 +156,20 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	ui.Info(fmt.Sprintf("Database proxy for %s listening on %s", ui.Bold(slug), ui.Bold(addr)))
 	fmt.Println()
 
-	// Handle shutdown signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	// Auto-launch psql unless --no-psql is set
+	// Auto-launch psql unless --no-psql is set. psql gets the same Ctrl-C,
+	// which cancels its query, so the proxy stays up until psql exits.
+	psqlDone := make(chan struct{})
+	stop := ctx.Done()
 	if !launchPsql {
+		stop = nil
+		release := interrupt.Hold()
 		go func() {
+			defer release()
 			if err := deps.RunPsql(host, port, creds, psqlArgs); err != nil {
 				ui.Error(fmt.Sprintf("psql: %v", err))
 			}
 			// After psql exits, shut down the proxy
-			sigCh <- syscall.SIGINT
+			close(psqlDone)
 		}()
 	} else {
 		if creds != nil {
@@ -178,85 +179,15 @@ func runConnect(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Accept connections until signal
-	go func() {
-		<-sigCh
-		fmt.Println()
-		ui.Info("Shutting down...")
-		listener.Close()
-	}()
-
-	wsURL := wsURLForSlug(slug)
-	header := http.Header{"Authorization": {"Bearer " + token}}
-
-	for {
-		conn, err := listener.Accept()
-		if err != nil {
-			// Listener closed (shutdown)
-			return nil
-		}
-		go handleConn(conn, wsURL, header)
+	// Forward connections until interrupted or psql exits
+	select {
+	case <-stop:
+	case <-psqlDone:
 	}
-}
-
-func handleConn(tcpConn net.Conn, wsURL string, header http.Header) {
-	defer tcpConn.Close()
-
-	wsConn, _, err := deps.DialWS(wsURL, header)
-	if err != nil {
-		ui.Error(fmt.Sprintf("WebSocket dial: %v", err))
-		return
-	}
-	defer wsConn.Close()
-
-	ui.Info("Client connected, tunnel active")
-
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// TCP -> WebSocket
-	go func() {
-		defer wg.Done()
-		buf := make([]byte, 32*1024)
-		for {
-			n, err := tcpConn.Read(buf)
-			if err != nil {
-				if err != io.EOF {
-					ui.Error(fmt.Sprintf("TCP read: %v", err))
-				}
-				wsConn.WriteMessage(websocket.CloseMessage,
-					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-				return
-			}
-			if err := wsConn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
-				return
-			}
-		}
-	}()
-
-	// WebSocket -> TCP
-	go func() {
-		defer wg.Done()
-		for {
-			_, data, err := wsConn.ReadMessage()
-			if err != nil {
-				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-					// Only log if not a normal close
-					if err != io.EOF {
-						ui.Error(fmt.Sprintf("WebSocket read: %v", err))
-					}
-				}
-				tcpConn.Close()
-				return
-			}
-			if _, err := tcpConn.Write(data); err != nil {
-				return
-			}
-		}
-	}()
-
-	wg.Wait()
-	ui.Info("Client disconnected")
+	fmt.Println()
+	ui.Info("Shutting down...")
+	tunnel.Close()
+	return nil
 }
 
 func wsURLForSlug(slug string) string {
@@ -350,30 +281,38 @@ func writeEnvFile(dbURL string, overwrite bool) error {
 	const envPath = ".env"
 	const key = "DATABASE_URL"
 
-	existing, _ := os.ReadFile(envPath)
-	lines := strings.Split(string(existing), "\n")
+	existing, err := os.ReadFile(envPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading .env: %w", err)
+	}
+	entries, err := dotenv.Parse(bytes.NewReader(existing), dotenv.Options{})
+	if err != nil {
+		return fmt.Errorf("parsing .env: %w", err)
+	}
 
 	// Check if DATABASE_URL already exists
-	foundIdx := -1
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, key+"=") || strings.HasPrefix(trimmed, key+" =") {
-			foundIdx = i
-			break
+	var found *dotenv.Entry
+	for i := range entries {
+		if entries[i].Key == key {
+			found = &entries[i]
 		}
 	}
 
-	if foundIdx >= 0 && !overwrite {
+	if found != nil && !overwrite {
 		return fmt.Errorf("DATABASE_URL already exists in .env. Use --overwrite-env to replace it")
 	}
 
-	if foundIdx >= 0 {
-		lines[foundIdx] = key + "=" + dbURL
+	line := key + "=" + dotenv.Quote(dbURL)
+	lines := strings.Split(string(existing), "\n")
+	if found != nil {
+		// Replace every line of the assignment, which may be a multi-line value
+		rest := append([]string{line}, lines[found.EndLine:]...)
+		lines = append(lines[:found.Line-1], rest...)
 	} else {
 		if len(lines) > 0 && lines[len(lines)-1] != "" {
 			lines = append(lines, "")
 		}
-		lines = append(lines, key+"="+dbURL)
+		lines = append(lines, line)
 	}
 
 	return os.WriteFile(envPath, []byte(strings.Join(lines, "\n")), 0644)
@@ -393,6 +332,7 @@ Free tier limits: 50 MB storage, 10,000 rows.`,
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -409,7 +349,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	ui.Info(fmt.Sprintf("Provisioning PostgreSQL database for %s...", ui.Bold(slug)))
 
 	client := api.NewClient(token)
-	addon, err := client.AddAddon(slug, "postgresql")
+	addon, err := client.AddAddon(ctx, slug, "postgresql")
 	if err != nil {
 		return fmt.Errorf("provisioning database: %w", err)
 	}
@@ -440,6 +380,7 @@ func newInfoCmd() *cobra.Command {
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -454,7 +395,7 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	client := api.NewClient(token)
-	addons, err := client.ListAddons(slug)
+	addons, err := client.ListAddons(ctx, slug)
 	if err != nil {
 		return fmt.Errorf("fetching addons: %w", err)
 	}
@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	"net"
+	"os"
 	"strings"
 	"testing"
 )
@@ -158,3 +159,26 @@ func TestDefaultDeps(t *testing.T) {
 		t.Fatal("RunPsql not set")
 	}
 }
+
+func TestWriteEnvFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	existing := "A=1\nexport DATABASE_URL=\"postgres://old\nsecond-line\"\nB=2\n"
+	if err := os.WriteFile(".env", []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeEnvFile("postgres://new", false)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected exported key to be detected, got: %v", err)
+	}
+
+	if err := writeEnvFile("postgres://u:p@h/db?x=1&y=$2", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(".env")
+	want := "A=1\nDATABASE_URL='postgres://u:p@h/db?x=1&y=$2'\nB=2\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
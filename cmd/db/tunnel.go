@@ -0,0 +1,170 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/gorilla/websocket"
+)
+
+// Tunnel forwards local TCP connections to an egg's database over a
+// WebSocket, one WebSocket per connection.
+type Tunnel struct {
+	listener net.Listener
+	wsURL    string
+	header   http.Header
+	quiet    bool
+
+	mu     sync.Mutex
+	closed bool
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+}
+
+// StartTunnel listens on addr and starts forwarding connections to slug's
+// database. Use port 0 in addr to pick a free port. With quiet set, nothing
+// is printed, so the tunnel can run next to another process's output.
+func StartTunnel(token, slug, addr string, quiet bool) (*Tunnel, error) {
+	listener, err := deps.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("binding %s: %w", addr, err)
+	}
+
+	t := &Tunnel{
+		listener: listener,
+		wsURL:    wsURLForSlug(slug),
+		header:   http.Header{"Authorization": {"Bearer " + token}},
+		quiet:    quiet,
+		conns:    map[net.Conn]struct{}{},
+	}
+	t.wg.Add(1)
+	go t.serve()
+	return t, nil
+}
+
+// Addr returns the local address the tunnel listens on.
+func (t *Tunnel) Addr() net.Addr {
+	return t.listener.Addr()
+}
+
+// Close stops accepting connections, closes open ones, and waits until all
+// forwarding has stopped.
+func (t *Tunnel) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	for c := range t.conns {
+		c.Close()
+	}
+	t.mu.Unlock()
+
+	err := t.listener.Close()
+	t.wg.Wait()
+	return err
+}
+
+func (t *Tunnel) serve() {
+	defer t.wg.Done()
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			// Listener closed (shutdown)
+			return
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			conn.Close()
+			return
+		}
+		t.conns[conn] = struct{}{}
+		t.mu.Unlock()
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.handleConn(conn)
+
+			t.mu.Lock()
+			delete(t.conns, conn)
+			t.mu.Unlock()
+		}()
+	}
+}
+
+func (t *Tunnel) logf(fn func(string), format string, args ...interface{}) {
+	if !t.quiet {
+		fn(fmt.Sprintf(format, args...))
+	}
+}
+
+func (t *Tunnel) handleConn(tcpConn net.Conn) {
+	defer tcpConn.Close()
+
+	wsConn, _, err := deps.DialWS(t.wsURL, t.header)
+	if err != nil {
+		t.logf(ui.Error, "WebSocket dial: %v", err)
+		return
+	}
+	defer wsConn.Close()
+
+	t.logf(ui.Info, "Client connected, tunnel active")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// TCP -> WebSocket
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := tcpConn.Read(buf)
+			if err != nil {
+				if err != io.EOF && !t.isClosed() {
+					t.logf(ui.Error, "TCP read: %v", err)
+				}
+				wsConn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				wsConn.Close()
+				return
+			}
+			if err := wsConn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	// WebSocket -> TCP
+	go func() {
+		defer wg.Done()
+		for {
+			_, data, err := wsConn.ReadMessage()
+			if err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					// Only log if not a normal close
+					if err != io.EOF && !t.isClosed() {
+						t.logf(ui.Error, "WebSocket read: %v", err)
+					}
+				}
+				tcpConn.Close()
+				return
+			}
+			if _, err := tcpConn.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	t.logf(ui.Info, "Client disconnected")
+}
+
+func (t *Tunnel) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newEchoWSServer returns a WebSocket server that echoes binary messages and
+// records the Authorization header of the last upgrade.
+func newEchoWSServer(t *testing.T, gotAuth *string) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotAuth = r.Header.Get("Authorization")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestTunnel_ForwardsAndClosesCleanly(t *testing.T) {
+	var gotAuth string
+	wsURL := newEchoWSServer(t, &gotAuth)
+
+	deps = &Deps{
+		Listen: net.Listen,
+		DialWS: func(url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+			return websocket.DefaultDialer.Dial(wsURL, header)
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	tunnel, err := StartTunnel("tok123", "myapp", "127.0.0.1:0", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", tunnel.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("reading echo: %v", err)
+	}
+	if string(buf) != "ping" {
+		t.Errorf("expected echo, got %q", buf)
+	}
+	if gotAuth != "Bearer tok123" {
+		t.Errorf("expected bearer token on tunnel, got %q", gotAuth)
+	}
+
+	// Close must tear down the open connection instead of waiting for it.
+	done := make(chan struct{})
+	go func() {
+		tunnel.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return while a connection was open")
+	}
+
+	if _, err := net.DialTimeout("tcp", tunnel.Addr().String(), 200*time.Millisecond); err == nil {
+		t.Error("expected the listener to be closed")
+	}
+}
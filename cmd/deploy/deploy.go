@@ -1,7 +1,7 @@
 package deploy
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +10,7 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -59,8 +60,10 @@ func readHatchConfig(dir string) (*HatchConfig, error) {
 
 // APIClient is the interface for the Hatch API.
 type APIClient interface {
-	CreateApp(name string) (*api.App, error)
-	UploadArtifact(slug string, artifact []byte, runtime, startCommand string) error
+	CreateApp(ctx context.Context, name string) (*api.App, error)
+	UploadArtifact(ctx context.Context, slug string, artifact []byte, format api.ArtifactFormat, runtime, startCommand string) error
+	GetEnvVars(ctx context.Context, slug string) ([]api.EnvVar, error)
+	SetEnvVars(ctx context.Context, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error)
 }
 
 // Deps holds injectable dependencies for testing.
@@ -75,12 +78,20 @@ type realAPIClient struct {
 	client *api.Client
 }
 
-func (r *realAPIClient) CreateApp(name string) (*api.App, error) {
-	return r.client.CreateApp(name)
+func (r *realAPIClient) CreateApp(ctx context.Context, name string) (*api.App, error) {
+	return r.client.CreateApp(ctx, name)
 }
 
-func (r *realAPIClient) UploadArtifact(slug string, artifact []byte, runtime, startCommand string) error {
-	return r.client.UploadArtifact(slug, bytes.NewReader(artifact), runtime, startCommand)
+func (r *realAPIClient) UploadArtifact(ctx context.Context, slug string, artifact []byte, format api.ArtifactFormat, runtime, startCommand string) error {
+	return uploadResumable(ctx, r.client, slug, artifact, format, runtime, startCommand)
+}
+
+func (r *realAPIClient) GetEnvVars(ctx context.Context, slug string) ([]api.EnvVar, error) {
+	return r.client.GetEnvVars(ctx, slug)
+}
+
+func (r *realAPIClient) SetEnvVars(ctx context.Context, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+	return r.client.SetEnvVars(ctx, slug, vars, unset)
 }
 
 func defaultDeps() *Deps {
@@ -101,6 +112,11 @@ var (
 	deployTarget string
 	runtime      string
 	startCommand string
+	symlinks     string
+	compression  string
+	level        int
+	dryRun       bool
+	skipEnvCheck bool
 )
 
 func NewCmd() *cobra.Command {
@@ -128,6 +144,28 @@ Artifact filtering:
   For static/php runtimes deploying from a project root, a .hatchignore
   is required. Other runtimes will warn but proceed.
 
+  Symlinks are archived as links by default, including ones that point
+  outside the deploy target. Use --symlinks=follow to copy the contents of
+  those links into the artifact instead, or --symlinks=error to fail.
+  Every escaping, skipped or followed link is listed in the output. Use
+  --dry-run to see the report without uploading.
+
+Compression:
+  Artifacts are compressed in parallel on all CPU cores. Use
+  --compression zstd for faster, smaller artifacts; if the API does not
+  accept zstd the CLI falls back to gzip automatically.
+
+Uploads:
+  Artifacts are uploaded in checksummed chunks, and failed chunks are
+  retried like other API requests. If an upload is interrupted, run the
+  same deploy again to resume it; progress is kept in ~/.hatch/uploads.
+
+Required env vars:
+  If .hatch.toml has an [env.required] table or the directory has a
+  .env.schema file, the egg's env vars are checked against it before
+  uploading. Missing keys with a default are set to it; other missing or
+  malformed keys fail the deploy. Use --skip-env-check to deploy anyway.
+
 Platform constraints:
   - Container runs linux/amd64
   - App must listen on PORT env var (always 8080)
@@ -160,6 +198,11 @@ Examples:
 	cmd.Flags().StringVar(&deployTarget, "deploy-target", "", "path to the build output directory (required)")
 	cmd.Flags().StringVar(&runtime, "runtime", "", "base container image: node, python, go, or static (required)")
 	cmd.Flags().StringVar(&startCommand, "start-command", "", "command to start the app (required for non-static runtimes)")
+	cmd.Flags().StringVar(&symlinks, "symlinks", "preserve", "handling of symlinks outside the deploy target: preserve (keep as links), follow, or error")
+	cmd.Flags().StringVar(&compression, "compression", "gzip", "artifact compression: gzip or zstd")
+	cmd.Flags().IntVar(&level, "compression-level", 0, "compression level (gzip 1-9, zstd 1-22; 0 = default)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "build the artifact and print its report without uploading")
+	cmd.Flags().BoolVar(&skipEnvCheck, "skip-env-check", false, "deploy even if the egg's env vars don't match the required-env schema")
 	return cmd
 }
 
@@ -181,13 +224,18 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
 	}
 
-	return RunArtifactDeploy(ArtifactDeployConfig{
+	return RunArtifactDeploy(interrupt.FromCommand(cmd), ArtifactDeployConfig{
 		Token:        token,
 		AppName:      appName,
 		Domain:       domainName,
 		DeployTarget: deployTarget,
 		Runtime:      runtime,
 		StartCommand: startCommand,
+		Symlinks:     symlinks,
+		Compression:  compression,
+		Level:        level,
+		DryRun:       dryRun,
+		SkipEnvCheck: skipEnvCheck,
 	})
 }
 
@@ -207,7 +255,7 @@ func writeHatchConfig(dir, slug, name string) error {
 
 // resolveApp resolves or creates an app, returning the slug and name.
 // The caller is responsible for writing .hatch.toml after a successful deploy.
-func resolveApp(client APIClient, appSlug, appNameOverride, dir string) (string, string, error) {
+func resolveApp(ctx context.Context, client APIClient, appSlug, appNameOverride, dir string) (string, string, error) {
 	// If explicit slug provided, use it
 	if appSlug != "" {
 		return appSlug, "", nil
@@ -235,7 +283,7 @@ func resolveApp(client APIClient, appSlug, appNameOverride, dir string) (string,
 	}
 
 	ui.Info(fmt.Sprintf("Creating new egg: %s", name))
-	app, err := client.CreateApp(name)
+	app, err := client.CreateApp(ctx, name)
 	if err != nil {
 		return "", "", fmt.Errorf("creating egg: %w", err)
 	}
@@ -2,6 +2,7 @@ package deploy
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -14,23 +15,39 @@ import (
 // mockAPIClient implements the APIClient interface for testing.
 type mockAPIClient struct {
 	createAppFn      func(name string) (*api.App, error)
-	uploadArtifactFn func(slug string, artifact []byte, runtime, startCommand string) error
+	uploadArtifactFn func(slug string, artifact []byte, format api.ArtifactFormat, runtime, startCommand string) error
+	getEnvVarsFn     func(slug string) ([]api.EnvVar, error)
+	setEnvVarsFn     func(slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error)
 }
 
-func (m *mockAPIClient) CreateApp(name string) (*api.App, error) {
+func (m *mockAPIClient) CreateApp(ctx context.Context, name string) (*api.App, error) {
 	if m.createAppFn != nil {
 		return m.createAppFn(name)
 	}
 	return &api.App{Slug: name + "-abc1", Name: name}, nil
 }
 
-func (m *mockAPIClient) UploadArtifact(slug string, artifact []byte, runtime, startCommand string) error {
+func (m *mockAPIClient) UploadArtifact(ctx context.Context, slug string, artifact []byte, format api.ArtifactFormat, runtime, startCommand string) error {
 	if m.uploadArtifactFn != nil {
-		return m.uploadArtifactFn(slug, artifact, runtime, startCommand)
+		return m.uploadArtifactFn(slug, artifact, format, runtime, startCommand)
 	}
 	return nil
 }
 
+func (m *mockAPIClient) GetEnvVars(ctx context.Context, slug string) ([]api.EnvVar, error) {
+	if m.getEnvVarsFn != nil {
+		return m.getEnvVarsFn(slug)
+	}
+	return nil, nil
+}
+
+func (m *mockAPIClient) SetEnvVars(ctx context.Context, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+	if m.setEnvVarsFn != nil {
+		return m.setEnvVarsFn(slug, vars, unset)
+	}
+	return &api.EnvUpdateResult{}, nil
+}
+
 func newMockAPIClient(mock *mockAPIClient) func(token string) APIClient {
 	return func(token string) APIClient {
 		return mock
@@ -161,7 +178,7 @@ func TestRunDeploy_ArtifactMode_StaticSuccess(t *testing.T) {
 		GetToken:     func() (string, error) { return "tok123", nil },
 		GetCwd:       func() (string, error) { return tmp, nil },
 		NewAPIClient: newMockAPIClient(&mockAPIClient{
-			uploadArtifactFn: func(slug string, artifact []byte, rt, sc string) error {
+			uploadArtifactFn: func(slug string, artifact []byte, format api.ArtifactFormat, rt, sc string) error {
 				uploadedSlug = slug
 				uploadedRuntime = rt
 				return nil
@@ -189,6 +206,82 @@ func TestRunDeploy_ArtifactMode_StaticSuccess(t *testing.T) {
 	}
 }
 
+func TestRunDeploy_ArtifactMode_DryRunSkipsUpload(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "index.html"), []byte("<h1>hi</h1>"), 0644)
+	os.Symlink("/etc/hosts", filepath.Join(tmp, "hosts"))
+
+	uploaded := false
+	created := false
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetCwd:   func() (string, error) { return tmp, nil },
+		NewAPIClient: newMockAPIClient(&mockAPIClient{
+			createAppFn: func(name string) (*api.App, error) {
+				created = true
+				return &api.App{Slug: name}, nil
+			},
+			uploadArtifactFn: func(slug string, artifact []byte, format api.ArtifactFormat, rt, sc string) error {
+				uploaded = true
+				return nil
+			},
+		}),
+	}
+	defer func() { deps = defaultDeps(); deployTarget = ""; runtime = ""; dryRun = false }()
+
+	deployTarget = tmp
+	runtime = "static"
+	dryRun = true
+
+	output := captureOutput(func() {
+		if err := runDeploy(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if uploaded || created {
+		t.Fatal("dry run must not create an egg or upload")
+	}
+	if !contains(output, "hosts -> /etc/hosts (preserved") {
+		t.Errorf("expected escaping symlink in dry-run report, got: %s", output)
+	}
+}
+
+func TestRunDeploy_ArtifactMode_ZstdFallsBackToGzip(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "index.html"), []byte("<h1>hi</h1>"), 0644)
+
+	var formats []api.ArtifactFormat
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetCwd:   func() (string, error) { return tmp, nil },
+		NewAPIClient: newMockAPIClient(&mockAPIClient{
+			uploadArtifactFn: func(slug string, artifact []byte, format api.ArtifactFormat, rt, sc string) error {
+				formats = append(formats, format)
+				if format == api.ArtifactZstd {
+					return fmt.Errorf("%w: zstd", api.ErrUnsupportedArtifactFormat)
+				}
+				return nil
+			},
+		}),
+	}
+	defer func() { deps = defaultDeps(); deployTarget = ""; runtime = ""; compression = "" }()
+
+	deployTarget = tmp
+	runtime = "static"
+	compression = "zstd"
+
+	captureOutput(func() {
+		if err := runDeploy(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if len(formats) != 2 || formats[0] != api.ArtifactZstd || formats[1] != api.ArtifactGzip {
+		t.Fatalf("expected zstd upload then gzip retry, got %v", formats)
+	}
+}
+
 func TestRunDeploy_ArtifactMode_ReadsHatchToml(t *testing.T) {
 	tmp := t.TempDir()
 
@@ -201,7 +294,7 @@ func TestRunDeploy_ArtifactMode_ReadsHatchToml(t *testing.T) {
 		GetToken:     func() (string, error) { return "tok123", nil },
 		GetCwd:       func() (string, error) { return tmp, nil },
 		NewAPIClient: newMockAPIClient(&mockAPIClient{
-			uploadArtifactFn: func(slug string, artifact []byte, rt, sc string) error {
+			uploadArtifactFn: func(slug string, artifact []byte, format api.ArtifactFormat, rt, sc string) error {
 				uploadedSlug = slug
 				return nil
 			},
@@ -271,115 +364,6 @@ func contains(s, substr string) bool {
 	return false
 }
 
-// --- .hatchignore integration tests ---
-
-func TestCreateTarGz_DefaultsExcludeGitAndEnv(t *testing.T) {
-	tmp := t.TempDir()
-	os.MkdirAll(filepath.Join(tmp, ".git"), 0755)
-	os.WriteFile(filepath.Join(tmp, ".env"), []byte("SECRET=x"), 0644)
-	os.WriteFile(filepath.Join(tmp, ".env.local"), []byte("LOCAL=y"), 0644)
-	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("console.log('hi')"), 0644)
-
-	artifact, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(artifact) == 0 {
-		t.Fatal("expected non-empty artifact")
-	}
-
-	// .git/ and .env* should be excluded
-	foundGit := false
-	foundEnv := false
-	for _, e := range excluded {
-		if e == ".git/" {
-			foundGit = true
-		}
-		if e == ".env" || e == ".env.local" {
-			foundEnv = true
-		}
-	}
-	if !foundGit {
-		t.Errorf("expected .git/ in excluded list, got: %v", excluded)
-	}
-	if !foundEnv {
-		t.Errorf("expected .env files in excluded list, got: %v", excluded)
-	}
-}
-
-func TestCreateTarGz_HatchignoreExcludesNodeModules(t *testing.T) {
-	tmp := t.TempDir()
-	os.MkdirAll(filepath.Join(tmp, "node_modules", "express"), 0755)
-	os.WriteFile(filepath.Join(tmp, "node_modules", "express", "index.js"), []byte("module.exports = {}"), 0644)
-	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("require('express')"), 0644)
-	os.WriteFile(filepath.Join(tmp, ".hatchignore"), []byte("node_modules/\n"), 0644)
-
-	_, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	found := false
-	for _, e := range excluded {
-		if e == "node_modules/" {
-			found = true
-		}
-	}
-	if !found {
-		t.Errorf("expected node_modules/ in excluded list, got: %v", excluded)
-	}
-}
-
-func TestCreateTarGz_NitroNotExcludedByDefault(t *testing.T) {
-	// Regression test: .nitro inside node_modules must NOT be excluded
-	tmp := t.TempDir()
-	nitroDir := filepath.Join(tmp, "node_modules", ".nitro")
-	os.MkdirAll(nitroDir, 0755)
-	os.WriteFile(filepath.Join(nitroDir, "index.js"), []byte("// nitro"), 0644)
-	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// server"), 0644)
-
-	_, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	for _, e := range excluded {
-		if contains(e, ".nitro") {
-			t.Errorf(".nitro should NOT be excluded by defaults, but found in excluded: %v", excluded)
-		}
-	}
-}
-
-func TestCreateTarGz_HatchignoreNegation(t *testing.T) {
-	tmp := t.TempDir()
-	os.WriteFile(filepath.Join(tmp, "app.log"), []byte("log"), 0644)
-	os.WriteFile(filepath.Join(tmp, "important.log"), []byte("keep"), 0644)
-	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// srv"), 0644)
-	os.WriteFile(filepath.Join(tmp, ".hatchignore"), []byte("*.log\n!important.log\n"), 0644)
-
-	_, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	foundApp := false
-	foundImportant := false
-	for _, e := range excluded {
-		if e == "app.log" {
-			foundApp = true
-		}
-		if e == "important.log" {
-			foundImportant = true
-		}
-	}
-	if !foundApp {
-		t.Errorf("expected app.log in excluded list, got: %v", excluded)
-	}
-	if foundImportant {
-		t.Errorf("expected important.log NOT in excluded (negated), got: %v", excluded)
-	}
-}
-
 func TestIsSourceDirectory(t *testing.T) {
 	// Node project
 	tmp := t.TempDir()
@@ -557,3 +541,79 @@ func TestValidRuntimes(t *testing.T) {
 		}
 	}
 }
+
+func TestRunDeploy_RequiredEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		vars       []api.EnvVar
+		skip       bool
+		wantErr    string
+		wantUpload bool
+		wantSet    map[string]string
+	}{
+		{
+			name:    "missing and malformed keys block the upload",
+			vars:    []api.EnvVar{{Key: "DATABASE_URL", Value: "not-a-url"}},
+			wantErr: "2 env var(s) do not match the schema:\n  DATABASE_URL: not a valid url\n  STRIPE_SECRET: missing",
+		},
+		{
+			name:       "defaults are set before the upload",
+			vars:       []api.EnvVar{{Key: "DATABASE_URL", Value: "postgres://db:5432/app"}, {Key: "STRIPE_SECRET", Value: "sk"}},
+			wantUpload: true,
+			wantSet:    map[string]string{"PORT": "8080"},
+		},
+		{
+			name:       "skip-env-check deploys anyway",
+			skip:       true,
+			wantUpload: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			os.WriteFile(filepath.Join(tmp, ".hatch.toml"), []byte("[app]\nslug = \"mysite-x1y2\"\nname = \"mysite\"\n"), 0644)
+			os.WriteFile(filepath.Join(tmp, ".env.schema"), []byte("STRIPE_SECRET\nDATABASE_URL url\nPORT int default=8080\n"), 0644)
+			t.Chdir(tmp)
+
+			uploaded := false
+			var set map[string]string
+			deps = &Deps{
+				GetToken: func() (string, error) { return "tok123", nil },
+				GetCwd:   func() (string, error) { return tmp, nil },
+				NewAPIClient: newMockAPIClient(&mockAPIClient{
+					uploadArtifactFn: func(slug string, artifact []byte, format api.ArtifactFormat, rt, sc string) error {
+						uploaded = true
+						return nil
+					},
+					getEnvVarsFn: func(slug string) ([]api.EnvVar, error) { return tt.vars, nil },
+					setEnvVarsFn: func(slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+						set = vars
+						return &api.EnvUpdateResult{}, nil
+					},
+				}),
+			}
+			defer func() { deps = defaultDeps(); deployTarget = ""; runtime = ""; skipEnvCheck = false }()
+
+			deployTarget = tmp
+			runtime = "static"
+			skipEnvCheck = tt.skip
+
+			var err error
+			captureOutput(func() { err = runDeploy(nil, nil) })
+
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if uploaded != tt.wantUpload {
+				t.Errorf("uploaded = %v, want %v", uploaded, tt.wantUpload)
+			}
+			if len(set) != len(tt.wantSet) || set["PORT"] != tt.wantSet["PORT"] {
+				t.Errorf("set defaults = %v, want %v", set, tt.wantSet)
+			}
+		})
+	}
+}
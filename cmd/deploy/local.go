@@ -1,17 +1,16 @@
 package deploy
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
-	"github.com/EscapeVelocityOperations/hatch-cli/internal/ignore"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/artifact"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envschema"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"golang.org/x/term"
 )
@@ -25,6 +24,11 @@ type ArtifactDeployConfig struct {
 	Runtime      string
 	StartCommand string
 	AppSlug      string // Explicit slug (optional, reads .hatch.toml if empty)
+	Symlinks     string // preserve (default), follow, or error
+	Compression  string // gzip (default) or zstd
+	Level        int    // Compression level (0 = format default)
+	DryRun       bool   // Build the artifact and report on it without uploading
+	SkipEnvCheck bool   // Don't check the egg's env vars against the schema
 }
 
 // validRuntimes lists accepted runtime values.
@@ -33,7 +37,7 @@ var validRuntimes = map[string]bool{
 }
 
 // RunArtifactDeploy deploys a pre-built directory as an artifact.
-func RunArtifactDeploy(cfg ArtifactDeployConfig) error {
+func RunArtifactDeploy(ctx context.Context, cfg ArtifactDeployConfig) error {
 	// Inform interactive users that Hatch is designed for AI agents
 	if term.IsTerminal(int(os.Stdout.Fd())) {
 		ui.Info("Hatch is designed for AI agents. Manual usage is supported, but your agent should handle deployment in production.")
@@ -52,6 +56,18 @@ func RunArtifactDeploy(cfg ArtifactDeployConfig) error {
 		return fmt.Errorf("--start-command is required for runtime %q", cfg.Runtime)
 	}
 
+	symlinks, err := artifact.ParseSymlinkMode(cfg.Symlinks)
+	if err != nil {
+		return err
+	}
+	format, err := artifact.ParseFormat(cfg.Compression)
+	if err != nil {
+		return err
+	}
+	if err := artifact.ValidateLevel(format, cfg.Level); err != nil {
+		return err
+	}
+
 	// Validate deploy-target directory exists
 	targetInfo, err := os.Stat(cfg.DeployTarget)
 	if err != nil {
@@ -77,29 +93,68 @@ func RunArtifactDeploy(cfg ArtifactDeployConfig) error {
 		return err
 	}
 
-	// Create tar.gz from directory
+	// Load the required-env schema early so a broken one fails fast
+	var schema *envschema.Schema
+	if !cfg.SkipEnvCheck {
+		schema, err = envschema.Load(".")
+		if err != nil {
+			return fmt.Errorf("reading required-env schema: %w", err)
+		}
+	}
+
+	// Create compressed tarball from directory
 	ui.Info("Creating artifact from " + cfg.DeployTarget)
-	artifact, excluded, err := createTarGz(cfg.DeployTarget)
+	opts := artifact.Options{Symlinks: symlinks, Format: format, Level: cfg.Level}
+	data, report, err := artifact.Create(cfg.DeployTarget, opts)
 	if err != nil {
 		return fmt.Errorf("creating artifact: %w", err)
 	}
-	if len(excluded) > 0 {
-		fmt.Println(ui.Dim("  Excluded: " + strings.Join(excluded, ", ")))
+	printArtifactReport(report)
+	ui.Info(fmt.Sprintf("Artifact size: %.2f MB (%s)", float64(len(data))/1024/1024, format))
+
+	if cfg.DryRun {
+		ui.Success("Dry run complete. Nothing was uploaded.")
+		return nil
 	}
-	ui.Info(fmt.Sprintf("Artifact size: %.2f MB", float64(len(artifact))/1024/1024))
 
 	// Resolve app
 	client := deps.NewAPIClient(cfg.Token)
-	slug, name, err := resolveApp(client, cfg.AppSlug, cfg.AppName, ".")
+	slug, name, err := resolveApp(ctx, client, cfg.AppSlug, cfg.AppName, ".")
 	if err != nil {
 		return err
 	}
 
+	if schema != nil {
+		if err := checkRequiredEnv(ctx, client, slug, schema); err != nil {
+			// Remember a newly created egg so that 'hatch env set' and the
+			// next deploy target it
+			if name != "" {
+				if werr := writeHatchConfig(cfg.DeployTarget, slug, name); werr != nil {
+					ui.Warn(fmt.Sprintf("Could not write .hatch.toml: %v", werr))
+				}
+			}
+			return err
+		}
+	}
+
 	// Upload
 	sp := ui.NewSpinner("Uploading artifact...")
 	sp.Start()
-	err = client.UploadArtifact(slug, artifact, cfg.Runtime, cfg.StartCommand)
+	err = client.UploadArtifact(ctx, slug, data, format, cfg.Runtime, cfg.StartCommand)
 	sp.Stop()
+	if errors.Is(err, api.ErrUnsupportedArtifactFormat) && format != api.ArtifactGzip {
+		// Older API versions only accept gzip; rebuild and retry once.
+		ui.Warn(fmt.Sprintf("The API does not accept %s artifacts, falling back to gzip", format))
+		opts.Format, opts.Level = api.ArtifactGzip, 0
+		data, _, err = artifact.Create(cfg.DeployTarget, opts)
+		if err != nil {
+			return fmt.Errorf("creating artifact: %w", err)
+		}
+		sp = ui.NewSpinner("Uploading artifact...")
+		sp.Start()
+		err = client.UploadArtifact(ctx, slug, data, api.ArtifactGzip, cfg.Runtime, cfg.StartCommand)
+		sp.Stop()
+	}
 	if err != nil {
 		return fmt.Errorf("uploading artifact: %w", err)
 	}
@@ -117,12 +172,35 @@ func RunArtifactDeploy(cfg ArtifactDeployConfig) error {
 	// Set custom domain if specified
 	if cfg.Domain != "" {
 		realClient := api.NewClient(cfg.Token)
-		configureDomain(realClient, slug, cfg.Domain)
+		configureDomain(ctx, realClient, slug, cfg.Domain)
 	}
 
 	return nil
 }
 
+// checkRequiredEnv verifies the egg's env vars against schema, setting
+// defaults for missing keys that have one.
+func checkRequiredEnv(ctx context.Context, client APIClient, slug string, schema *envschema.Schema) error {
+	vars, err := client.GetEnvVars(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("checking required env vars: %w", err)
+	}
+
+	res := schema.Check(vars)
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("%s: %w\n\nSet them with 'hatch env set --app %s' and deploy again, or use --skip-env-check", slug, err, slug)
+	}
+
+	if len(res.Defaults) > 0 {
+		if _, err := client.SetEnvVars(ctx, slug, res.Defaults, nil); err != nil {
+			return fmt.Errorf("setting env var defaults: %w", err)
+		}
+		ui.Info(fmt.Sprintf("Set schema defaults for %s", strings.Join(res.DefaultKeys(), ", ")))
+	}
+	ui.Success(fmt.Sprintf("Env vars match the schema (%d required)", len(schema.Rules)))
+	return nil
+}
+
 // parseEntrypoint extracts the file path from a start command.
 // e.g. "node server/index.mjs" -> "server/index.mjs"
 // e.g. "python -m uvicorn main:app" -> "" (skip validation for -m flag)
@@ -145,9 +223,9 @@ func parseEntrypoint(cmd string) string {
 }
 
 // configureDomain adds a custom domain to an app.
-func configureDomain(client *api.Client, slug, domainName string) {
+func configureDomain(ctx context.Context, client *api.Client, slug, domainName string) {
 	ui.Info(fmt.Sprintf("Configuring custom domain: %s", domainName))
-	domain, err := client.AddDomain(slug, domainName)
+	domain, err := client.AddDomain(ctx, slug, domainName)
 	if err != nil {
 		ui.Warn(fmt.Sprintf("Domain configuration failed: %v", err))
 		ui.Info("You can configure it later with: hatch domain add " + domainName)
@@ -223,129 +301,12 @@ func checkSourceDirectory(dir, rt string) error {
 	return nil
 }
 
-// createTarGz creates a tar.gz archive of the given directory.
-// Uses .hatchignore patterns if present, otherwise applies built-in defaults.
-// Returns the archive bytes and a list of excluded file/folder names.
-func createTarGz(dir string) ([]byte, []string, error) {
-	var buf bytes.Buffer
-	gw := gzip.NewWriter(&buf)
-	tw := tar.NewWriter(gw)
-
-	absDir, err := filepath.Abs(dir)
-	if err != nil {
-		return nil, nil, fmt.Errorf("resolving directory: %w", err)
+// printArtifactReport prints excluded paths and symlink handling to stdout.
+func printArtifactReport(report *artifact.Report) {
+	if len(report.Excluded) > 0 {
+		fmt.Println(ui.Dim("  Excluded: " + strings.Join(report.Excluded, ", ")))
 	}
-
-	// Load .hatchignore or use defaults
-	matcher, err := ignore.LoadFile(filepath.Join(dir, ".hatchignore"))
-	if err != nil {
-		if os.IsNotExist(err) {
-			matcher = ignore.DefaultMatcher()
-		} else {
-			return nil, nil, fmt.Errorf("reading .hatchignore: %w", err)
-		}
+	for _, ev := range report.Symlinks {
+		fmt.Println(ui.Dim("  Symlink " + ev.String()))
 	}
-
-	var excluded []string
-	excludedSet := make(map[string]bool)
-
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Never skip the root directory itself (even if deploy-target starts with ".")
-		if path == dir {
-			return nil
-		}
-
-		rel, _ := filepath.Rel(dir, path)
-
-		if matcher.ShouldExclude(rel, info.IsDir()) {
-			label := rel
-			if info.IsDir() {
-				label += "/"
-				if !excludedSet[label] {
-					excludedSet[label] = true
-					excluded = append(excluded, label)
-				}
-				return filepath.SkipDir
-			}
-			if !excludedSet[label] {
-				excludedSet[label] = true
-				excluded = append(excluded, label)
-			}
-			return nil
-		}
-
-		// Handle symlinks
-		link := ""
-		if info.Mode()&os.ModeSymlink != 0 {
-			link, err = os.Readlink(path)
-			if err != nil {
-				return err
-			}
-
-			// Validate symlink doesn't escape output directory
-			target := link
-			if !filepath.IsAbs(target) {
-				target = filepath.Join(filepath.Dir(path), target)
-			}
-			target, err = filepath.Abs(target)
-			if err != nil {
-				return err
-			}
-
-			// Skip symlinks that point outside the output directory
-			if !strings.HasPrefix(target, absDir+string(filepath.Separator)) && target != absDir {
-				return nil
-			}
-		}
-
-		header, err := tar.FileInfoHeader(info, link)
-		if err != nil {
-			return err
-		}
-
-		header.Name = rel
-		if info.IsDir() {
-			header.Name += "/"
-		}
-
-		if err := tw.WriteHeader(header); err != nil {
-			return err
-		}
-
-		// Only copy content for regular files (not dirs or symlinks)
-		if info.Mode().IsRegular() {
-			f, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-			if _, err := io.Copy(tw, f); err != nil {
-				return err
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if err := tw.Close(); err != nil {
-		return nil, nil, err
-	}
-	if err := gw.Close(); err != nil {
-		return nil, nil, err
-	}
-
-	// Check artifact size
-	if buf.Len() > 500*1024*1024 {
-		return nil, nil, fmt.Errorf("artifact too large (%.0f MB, max 500 MB)", float64(buf.Len())/1024/1024)
-	}
-
-	return buf.Bytes(), excluded, nil
 }
@@ -139,67 +139,6 @@ func TestCheckSourceDirectory_PHPWithHatchignoreOK(t *testing.T) {
 	}
 }
 
-func TestCreateTarGz_SymlinkEscapesDirectory(t *testing.T) {
-	tmp := t.TempDir()
-
-	// Create a symlink that points outside the directory
-	linkPath := tmp + "/external-link"
-	os.Symlink("/etc/passwd", linkPath)
-	os.WriteFile(tmp+"/server.js", []byte("// server"), 0644)
-
-	artifact, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(artifact) == 0 {
-		t.Fatal("expected non-empty artifact")
-	}
-
-	// The symlink that escapes should be excluded (no content added)
-	// Check that symlink is not in artifact by checking excluded list
-	found := false
-	for _, e := range excluded {
-		if e == "external-link" {
-			found = true
-		}
-	}
-	if found {
-		t.Logf("escaping symlink was excluded: %v", excluded)
-	}
-}
-
-func TestCreateTarGz_InternalSymlinkIncluded(t *testing.T) {
-	tmp := t.TempDir()
-
-	// Create a directory
-	os.MkdirAll(tmp+"/data", 0755)
-	os.WriteFile(tmp+"/data/info.txt", []byte("secret"), 0644)
-
-	// Create a symlink inside the directory pointing to another file inside
-	linkPath := tmp + "/info-link"
-	os.Symlink("data/info.txt", linkPath)
-	os.WriteFile(tmp+"/server.js", []byte("// server"), 0644)
-
-	artifact, excluded, err := createTarGz(tmp)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(artifact) == 0 {
-		t.Fatal("expected non-empty artifact")
-	}
-
-	// Internal symlink should be included
-	found := false
-	for _, e := range excluded {
-		if e == "info-link" {
-			found = true
-		}
-	}
-	if found {
-		t.Error("internal symlink should not be excluded")
-	}
-}
-
 // TestUIHelpers tests UI output functions
 func TestUI_Info(t *testing.T) {
 	output := captureUIOutput(func() {
@@ -0,0 +1,61 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+)
+
+// uploadResumable uploads an artifact in chunks. If an earlier upload of the
+// same artifact to the same egg was interrupted, it resumes from the chunks
+// the server already has. Falls back to a single request when the API does
+// not support chunked uploads.
+func uploadResumable(ctx context.Context, client *api.Client, slug string, artifact []byte, format api.ArtifactFormat, rt, startCmd string) error {
+	sum := sha256.Sum256(artifact)
+	digest := hex.EncodeToString(sum[:])
+
+	opts := api.ChunkedUploadOptions{}
+	if state, err := config.LoadUploadState(slug); err == nil && state != nil && state.SHA256 == digest {
+		opts.ResumeID = state.UploadID
+	}
+
+	started := false
+	opts.OnSession = func(uploadID string) {
+		started = true
+		// Best effort: losing the state file only costs the ability to resume.
+		_ = config.SaveUploadState(&config.UploadState{
+			Slug:      slug,
+			UploadID:  uploadID,
+			SHA256:    digest,
+			Size:      int64(len(artifact)),
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	err := client.UploadArtifactChunked(ctx, slug, artifact, format, rt, startCmd, opts)
+	if errors.Is(err, api.ErrChunkedUploadUnsupported) {
+		return client.UploadArtifact(ctx, slug, bytes.NewReader(artifact), format, rt, startCmd)
+	}
+	if errors.Is(err, api.ErrUnsupportedArtifactFormat) {
+		// The caller rebuilds the artifact in another format, so there is
+		// nothing to resume.
+		_ = config.ClearUploadState(slug)
+		return err
+	}
+	if err != nil {
+		if started {
+			return fmt.Errorf("%w\n\nProgress was saved. Run the same deploy again to resume the upload", err)
+		}
+		return err
+	}
+
+	_ = config.ClearUploadState(slug)
+	return nil
+}
@@ -0,0 +1,160 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+)
+
+func TestUploadResumable_ResumesSavedUpload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	artifact := []byte("artifact-bytes")
+	sum := sha256.Sum256(artifact)
+	config.SaveUploadState(&config.UploadState{Slug: "myapp", UploadID: "up-7", SHA256: hex.EncodeToString(sum[:])})
+
+	var initiated, resumed, committed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/apps/myapp/artifact/uploads":
+			initiated = true
+			json.NewEncoder(w).Encode(api.UploadSession{ID: "up-8"})
+		case r.Method == "GET" && r.URL.Path == "/v1/apps/myapp/artifact/uploads/up-7":
+			resumed = true
+			json.NewEncoder(w).Encode(api.UploadSession{ID: "up-7"})
+		case r.Method == "PUT":
+			io.Copy(io.Discard, r.Body)
+		case strings.HasSuffix(r.URL.Path, "/commit"):
+			committed = true
+		}
+	}))
+	defer server.Close()
+
+	err := uploadResumable(context.Background(), api.NewTestClient("tok", server.URL), "myapp", artifact, api.ArtifactGzip, "node", "node index.js")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resumed || initiated {
+		t.Errorf("expected saved upload to be resumed (resumed=%v, initiated=%v)", resumed, initiated)
+	}
+	if !committed {
+		t.Error("expected upload to be committed")
+	}
+	if state, _ := config.LoadUploadState("myapp"); state != nil {
+		t.Errorf("expected state to be cleared after success, got %+v", state)
+	}
+}
+
+func TestUploadResumable_KeepsStateOnFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			json.NewEncoder(w).Encode(api.UploadSession{ID: "up-9"})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	err := uploadResumable(context.Background(), api.NewTestClient("tok", server.URL), "myapp", []byte("data"), api.ArtifactGzip, "node", "")
+	if err == nil || !strings.Contains(err.Error(), "resume the upload") {
+		t.Fatalf("expected resumable failure, got: %v", err)
+	}
+	state, _ := config.LoadUploadState("myapp")
+	if state == nil || state.UploadID != "up-9" {
+		t.Fatalf("expected saved state for up-9, got %+v", state)
+	}
+}
+
+func TestUploadResumable_FallsBackToSingleRequest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var single bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/apps/myapp/artifact" {
+			single = true
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := uploadResumable(context.Background(), api.NewTestClient("tok", server.URL), "myapp", []byte("data"), api.ArtifactGzip, "node", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !single {
+		t.Error("expected fallback to single-request upload")
+	}
+}
+
+func TestRunDeploy_ChunkedZstdFallsBackToGzip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "index.html"), []byte("<h1>hi</h1>"), 0644)
+	t.Chdir(tmp)
+
+	var types []string
+	committed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/apps":
+			json.NewEncoder(w).Encode(api.App{Slug: "myapp", Name: "myapp"})
+		case r.Method == "POST" && r.URL.Path == "/v1/apps/myapp/artifact/uploads":
+			var body struct {
+				ContentType string `json:"content_type"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			types = append(types, body.ContentType)
+			if body.ContentType == "application/zstd" {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			json.NewEncoder(w).Encode(api.UploadSession{ID: "up-1"})
+		case r.Method == "PUT":
+			io.Copy(io.Discard, r.Body)
+		case strings.HasSuffix(r.URL.Path, "/commit"):
+			committed = true
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetCwd:   func() (string, error) { return tmp, nil },
+		NewAPIClient: func(token string) APIClient {
+			return &realAPIClient{client: api.NewTestClient(token, server.URL)}
+		},
+	}
+	defer func() { deps = defaultDeps(); deployTarget = ""; runtime = ""; compression = "" }()
+	deployTarget = tmp
+	runtime = "static"
+	compression = "zstd"
+
+	captureOutput(func() {
+		if err := runDeploy(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if len(types) != 2 || types[0] != "application/zstd" || types[1] != "application/gzip" {
+		t.Errorf("expected a zstd upload then a gzip retry, got %v", types)
+	}
+	if !committed {
+		t.Error("expected the gzip upload to be committed")
+	}
+}
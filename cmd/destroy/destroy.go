@@ -2,12 +2,14 @@ package destroy
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -16,15 +18,15 @@ import (
 // Deps holds injectable dependencies for testing.
 type Deps struct {
 	GetToken  func() (string, error)
-	DeleteApp func(token, slug string) error
+	DeleteApp func(ctx context.Context, token, slug string) error
 	ReadInput func(prompt string) (string, error)
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		DeleteApp: func(token, slug string) error {
-			return api.NewClient(token).DeleteApp(slug)
+		DeleteApp: func(ctx context.Context, token, slug string) error {
+			return api.NewClient(token).DeleteApp(ctx, slug)
 		},
 		ReadInput: readInput,
 	}
@@ -48,6 +50,7 @@ func NewCmd() *cobra.Command {
 }
 
 func runDestroy(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -78,7 +81,7 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner(fmt.Sprintf("Destroying %s...", slug))
 	sp.Start()
-	err = deps.DeleteApp(token, slug)
+	err = deps.DeleteApp(ctx, token, slug)
 	sp.Stop()
 
 	if err != nil {
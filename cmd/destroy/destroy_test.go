@@ -2,6 +2,7 @@ package destroy
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -42,7 +43,7 @@ func TestRunDestroy_Confirmed(t *testing.T) {
 		ReadInput: func(prompt string) (string, error) {
 			return "myapp\n", nil
 		},
-		DeleteApp: func(token, slug string) error {
+		DeleteApp: func(ctx context.Context, token, slug string) error {
 			deleted = slug
 			return nil
 		},
@@ -91,7 +92,7 @@ func TestRunDestroy_APIError(t *testing.T) {
 		ReadInput: func(prompt string) (string, error) {
 			return "myapp\n", nil
 		},
-		DeleteApp: func(token, slug string) error {
+		DeleteApp: func(ctx context.Context, token, slug string) error {
 			return fmt.Errorf("forbidden")
 		},
 	}
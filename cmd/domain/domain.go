@@ -1,11 +1,13 @@
 package domain
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -13,26 +15,26 @@ import (
 // Deps holds injectable dependencies for testing.
 type Deps struct {
 	GetToken       func() (string, error)
-	ListDomains    func(token, slug string) ([]api.Domain, error)
-	AddDomain      func(token, slug, domain string) (*api.Domain, error)
-	RemoveDomain   func(token, slug, domain string) error
-	VerifyDomain   func(token, slug, domain string) (*api.Domain, error)
+	ListDomains    func(ctx context.Context, token, slug string) ([]api.Domain, error)
+	AddDomain      func(ctx context.Context, token, slug, domain string) (*api.Domain, error)
+	RemoveDomain   func(ctx context.Context, token, slug, domain string) error
+	VerifyDomain   func(ctx context.Context, token, slug, domain string) (*api.Domain, error)
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		ListDomains: func(token, slug string) ([]api.Domain, error) {
-			return api.NewClient(token).ListDomains(slug)
+		ListDomains: func(ctx context.Context, token, slug string) ([]api.Domain, error) {
+			return api.NewClient(token).ListDomains(ctx, slug)
 		},
-		AddDomain: func(token, slug, domain string) (*api.Domain, error) {
-			return api.NewClient(token).AddDomain(slug, domain)
+		AddDomain: func(ctx context.Context, token, slug, domain string) (*api.Domain, error) {
+			return api.NewClient(token).AddDomain(ctx, slug, domain)
 		},
-		RemoveDomain: func(token, slug, domain string) error {
-			return api.NewClient(token).RemoveDomain(slug, domain)
+		RemoveDomain: func(ctx context.Context, token, slug, domain string) error {
+			return api.NewClient(token).RemoveDomain(ctx, slug, domain)
 		},
-		VerifyDomain: func(token, slug, domain string) (*api.Domain, error) {
-			return api.NewClient(token).VerifyDomain(slug, domain)
+		VerifyDomain: func(ctx context.Context, token, slug, domain string) (*api.Domain, error) {
+			return api.NewClient(token).VerifyDomain(ctx, slug, domain)
 		},
 	}
 }
@@ -80,7 +82,7 @@ func newListCmd() *cobra.Command {
 Example:
   hatch domain list --app my-app`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runList(appSlug)
+			return runList(interrupt.FromCommand(cmd), appSlug)
 		},
 	}
 
@@ -109,7 +111,7 @@ Example:
   hatch domain add www.example.com --app my-app`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAdd(appSlug, args[0])
+			return runAdd(interrupt.FromCommand(cmd), appSlug, args[0])
 		},
 	}
 
@@ -131,7 +133,7 @@ Example:
   hatch domain remove example.com --app my-app`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRemove(appSlug, args[0])
+			return runRemove(interrupt.FromCommand(cmd), appSlug, args[0])
 		},
 	}
 
@@ -143,7 +145,7 @@ Example:
 
 // resolveSlug resolves an app name to its slug by listing apps.
 // Returns the slug unchanged if it's already a valid slug or no match found.
-func resolveSlug(appSlug string) (string, error) {
+func resolveSlug(ctx context.Context, appSlug string) (string, error) {
 	token, err := deps.GetToken()
 	if err != nil {
 		return "", fmt.Errorf("checking auth: %w", err)
@@ -153,7 +155,7 @@ func resolveSlug(appSlug string) (string, error) {
 	}
 
 	client := api.NewClient(token)
-	apps, err := client.ListApps()
+	apps, err := client.ListApps(ctx)
 	if err == nil {
 		// Check if appSlug matches any app name or slug
 		for _, app := range apps {
@@ -166,8 +168,8 @@ func resolveSlug(appSlug string) (string, error) {
 	return appSlug, nil
 }
 
-func runList(appSlug string) error {
-	slug, err := resolveSlug(appSlug)
+func runList(ctx context.Context, appSlug string) error {
+	slug, err := resolveSlug(ctx, appSlug)
 	if err != nil {
 		return err
 	}
@@ -182,7 +184,7 @@ func runList(appSlug string) error {
 
 	sp := ui.NewSpinner("Fetching domains...")
 	sp.Start()
-	domains, err := deps.ListDomains(token, slug)
+	domains, err := deps.ListDomains(ctx, token, slug)
 	sp.Stop()
 
 	if err != nil {
@@ -206,8 +208,8 @@ func runList(appSlug string) error {
 	return nil
 }
 
-func runAdd(appSlug, domain string) error {
-	slug, err := resolveSlug(appSlug)
+func runAdd(ctx context.Context, appSlug, domain string) error {
+	slug, err := resolveSlug(ctx, appSlug)
 	if err != nil {
 		return err
 	}
@@ -222,7 +224,7 @@ func runAdd(appSlug, domain string) error {
 
 	sp := ui.NewSpinner("Adding domain...")
 	sp.Start()
-	d, err := deps.AddDomain(token, slug, domain)
+	d, err := deps.AddDomain(ctx, token, slug, domain)
 	sp.Stop()
 
 	if err != nil {
@@ -255,8 +257,8 @@ func runAdd(appSlug, domain string) error {
 	return nil
 }
 
-func runRemove(appSlug, domain string) error {
-	slug, err := resolveSlug(appSlug)
+func runRemove(ctx context.Context, appSlug, domain string) error {
+	slug, err := resolveSlug(ctx, appSlug)
 	if err != nil {
 		return err
 	}
@@ -271,7 +273,7 @@ func runRemove(appSlug, domain string) error {
 
 	sp := ui.NewSpinner("Removing domain...")
 	sp.Start()
-	err = deps.RemoveDomain(token, slug, domain)
+	err = deps.RemoveDomain(ctx, token, slug, domain)
 	sp.Stop()
 
 	if err != nil {
@@ -297,7 +299,7 @@ Example:
   hatch domain verify example.com --app my-app`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runVerify(appSlug, args[0])
+			return runVerify(interrupt.FromCommand(cmd), appSlug, args[0])
 		},
 	}
 
@@ -307,8 +309,8 @@ Example:
 	return cmd
 }
 
-func runVerify(appSlug, domain string) error {
-	slug, err := resolveSlug(appSlug)
+func runVerify(ctx context.Context, appSlug, domain string) error {
+	slug, err := resolveSlug(ctx, appSlug)
 	if err != nil {
 		return err
 	}
@@ -323,7 +325,7 @@ func runVerify(appSlug, domain string) error {
 
 	sp := ui.NewSpinner("Verifying domain ownership...")
 	sp.Start()
-	d, err := deps.VerifyDomain(token, slug, domain)
+	d, err := deps.VerifyDomain(ctx, token, slug, domain)
 	sp.Stop()
 
 	if err != nil {
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"os"
 	"testing"
@@ -50,13 +51,13 @@ func TestListDomains(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			deps = &Deps{
 				GetToken: func() (string, error) { return tt.token, tt.tokenErr },
-				ListDomains: func(token, slug string) ([]api.Domain, error) {
+				ListDomains: func(ctx context.Context, token, slug string) ([]api.Domain, error) {
 					return tt.domains, tt.listErr
 				},
 			}
 			defer func() { deps = defaultDeps() }()
 
-			err := runList("test-app")
+			err := runList(context.Background(), "test-app")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -104,7 +105,7 @@ func TestAddDomain(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			deps = &Deps{
 				GetToken: func() (string, error) { return tt.token, nil },
-				AddDomain: func(token, slug, domain string) (*api.Domain, error) {
+				AddDomain: func(ctx context.Context, token, slug, domain string) (*api.Domain, error) {
 					return tt.domain, tt.addErr
 				},
 			}
@@ -115,7 +116,7 @@ func TestAddDomain(t *testing.T) {
 			os.Stdout, _ = os.Open(os.DevNull)
 			defer func() { os.Stdout = old }()
 
-			err := runAdd("test-app", "example.com")
+			err := runAdd(context.Background(), "test-app", "example.com")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -161,13 +162,13 @@ func TestRemoveDomain(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			deps = &Deps{
 				GetToken: func() (string, error) { return tt.token, nil },
-				RemoveDomain: func(token, slug, domain string) error {
+				RemoveDomain: func(ctx context.Context, token, slug, domain string) error {
 					return tt.removeErr
 				},
 			}
 			defer func() { deps = defaultDeps() }()
 
-			err := runRemove("test-app", "example.com")
+			err := runRemove(context.Background(), "test-app", "example.com")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -1,11 +1,13 @@
 package energy
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/spf13/cobra"
 )
@@ -22,23 +24,24 @@ func NewCmd() *cobra.Command {
 				return fmt.Errorf("not logged in: %w (run 'hatch login' first)", err)
 			}
 
+			ctx := interrupt.FromCommand(cmd)
 			client := api.NewClient(token)
 
 			if len(args) == 1 {
-				return showAppEnergy(client, args[0])
+				return showAppEnergy(ctx, client, args[0])
 			}
 			// Check .hatch.toml for per-app energy
 			if slug := resolve.SlugFromToml(); slug != "" {
-				return showAppEnergy(client, slug)
+				return showAppEnergy(ctx, client, slug)
 			}
-			return showAccountEnergy(client)
+			return showAccountEnergy(ctx, client)
 		},
 	}
 	return cmd
 }
 
-func showAccountEnergy(client *api.Client) error {
-	energy, err := client.GetAccountEnergy()
+func showAccountEnergy(ctx context.Context, client *api.Client) error {
+	energy, err := client.GetAccountEnergy(ctx)
 	if err != nil {
 		return fmt.Errorf("getting energy status: %w", err)
 	}
@@ -63,8 +66,8 @@ func showAccountEnergy(client *api.Client) error {
 	return nil
 }
 
-func showAppEnergy(client *api.Client, slug string) error {
-	energy, err := client.GetAppEnergy(slug)
+func showAppEnergy(ctx context.Context, client *api.Client, slug string) error {
+	energy, err := client.GetAppEnergy(ctx, slug)
 	if err != nil {
 		return fmt.Errorf("getting energy for %s: %w", slug, err)
 	}
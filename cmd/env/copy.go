@@ -0,0 +1,135 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envcopy"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyFrom         string
+	copyTo           string
+	copyInclude      []string
+	copyExclude      []string
+	copySkipExisting bool
+	copyDryRun       bool
+	copyYes          bool
+)
+
+func newCopyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "copy --from EGG --to EGG",
+		Short: "Copy environment variables from one egg to another",
+		Long: `Copy environment variables from one egg to another, for example to set up
+a preview or migrate an egg.
+
+Select keys with --include and --exclude glob patterns (repeatable; exclude
+wins). Keys on the target that are not copied are left alone. The diff is
+printed with secret values masked and applied in one atomic update after
+confirmation.
+
+  hatch env copy --from prod --to preview-42 --exclude 'DATABASE_URL' --exclude 'STRIPE_*'
+  hatch env copy --from prod --to staging --include 'SENTRY_*' --skip-existing`,
+		Args: cobra.NoArgs,
+		RunE: runCopy,
+	}
+	cmd.Flags().StringVar(&copyFrom, "from", "", "egg to copy from")
+	cmd.Flags().StringVar(&copyTo, "to", "", "egg to copy to")
+	cmd.Flags().StringArrayVar(&copyInclude, "include", nil, "only copy keys matching this glob (repeatable)")
+	cmd.Flags().StringArrayVar(&copyExclude, "exclude", nil, "never copy keys matching this glob (repeatable)")
+	cmd.Flags().BoolVar(&copySkipExisting, "skip-existing", false, "keep keys that already exist on the target")
+	cmd.Flags().BoolVar(&copyDryRun, "dry-run", false, "print the diff without applying it")
+	cmd.Flags().BoolVarP(&copyYes, "yes", "y", false, "apply without asking for confirmation")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func runCopy(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	from, to := slugForName(ctx, copyFrom), slugForName(ctx, copyTo)
+	if from == to {
+		return fmt.Errorf("--from and --to are the same egg")
+	}
+
+	sp := ui.NewSpinner("Fetching environment variables...")
+	sp.Start()
+	src, err := deps.GetEnvVars(ctx, token, from)
+	if err != nil {
+		sp.Stop()
+		return fmt.Errorf("fetching env vars of %s: %w", from, err)
+	}
+	dst, err := deps.GetEnvVars(ctx, token, to)
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("fetching env vars of %s: %w", to, err)
+	}
+
+	plan, err := envcopy.NewPlan(src, dst, envcopy.Options{
+		Include:      copyInclude,
+		Exclude:      copyExclude,
+		SkipExisting: copySkipExisting,
+	})
+	if err != nil {
+		return err
+	}
+
+	if plan.Changes() == 0 {
+		ui.Info(fmt.Sprintf("Nothing to copy: %s already has the selected variables of %s.", to, from))
+		printSkipped(plan)
+		return nil
+	}
+
+	var changes []envChange
+	for _, key := range plan.Added {
+		changes = append(changes, envChange{Op: opAdd, Key: key, NewValue: plan.Set[key]})
+	}
+	for _, key := range plan.Updated {
+		changes = append(changes, envChange{Op: opChange, Key: key, OldValue: plan.Old[key], NewValue: plan.Set[key]})
+	}
+	printChanges(changes)
+	printSkipped(plan)
+	fmt.Println()
+
+	if copyDryRun {
+		ui.Info("Dry run: no changes applied.")
+		return nil
+	}
+
+	if !copyYes {
+		answer, err := deps.ReadInput(fmt.Sprintf("Copy %d variable(s) from %s to %s? [y/N] ", plan.Changes(), from, to))
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			ui.Info("Cancelled. No changes applied.")
+			return nil
+		}
+	}
+
+	result, err := deps.SetEnvVars(ctx, token, to, plan.Set, nil)
+	if err != nil {
+		return fmt.Errorf("applying changes (nothing was changed): %w", err)
+	}
+	printEnvUpdateResult(to, result)
+	return nil
+}
+
+func printSkipped(plan *envcopy.Plan) {
+	if len(plan.Skipped) > 0 {
+		fmt.Println(ui.Dim(fmt.Sprintf("  Kept existing value of %s (--skip-existing)", strings.Join(plan.Skipped, ", "))))
+	}
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func copyTestDeps(t *testing.T, applied *map[string]string, target *string) {
+	t.Helper()
+	eggs := map[string][]api.EnvVar{
+		"prod": {
+			{Key: "API_TOKEN", Value: "supersecretvalue"},
+			{Key: "STRIPE_KEY", Value: "sk_live_1234567890"},
+			{Key: "PORT", Value: "8080"},
+			{Key: "LOG_LEVEL", Value: "info"},
+		},
+		"preview": {
+			{Key: "PORT", Value: "8080"},
+			{Key: "LOG_LEVEL", Value: "debug"},
+		},
+	}
+	deps = &Deps{
+		GetToken:   func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return eggs[slug], nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			*applied, *target = vars, slug
+			return &api.EnvUpdateResult{Added: []string{"API_TOKEN"}}, nil
+		},
+		ReadInput: func(prompt string) (string, error) { return "y\n", nil },
+	}
+	t.Cleanup(func() { deps = defaultDeps() })
+}
+
+func TestRunCopy_ExcludeAndSkipExisting(t *testing.T) {
+	copyFrom, copyTo = "prod", "preview"
+	copyExclude = []string{"STRIPE_*"}
+	copySkipExisting = true
+	defer func() { copyFrom, copyTo, copyExclude, copySkipExisting = "", "", nil, false }()
+
+	var applied map[string]string
+	var target string
+	copyTestDeps(t, &applied, &target)
+
+	output := captureOutput(func() {
+		if err := runCopy(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if target != "preview" {
+		t.Errorf("expected changes applied to preview, got %q", target)
+	}
+	if len(applied) != 1 || applied["API_TOKEN"] != "supersecretvalue" {
+		t.Errorf("expected only API_TOKEN to be copied, got %v", applied)
+	}
+	if contains(output, "supersecretvalue") {
+		t.Errorf("secret value leaked in diff: %s", output)
+	}
+	if !contains(output, "Kept existing value of LOG_LEVEL") {
+		t.Errorf("expected skipped key to be reported, got: %s", output)
+	}
+}
+
+func TestRunCopy_DryRun(t *testing.T) {
+	copyFrom, copyTo = "prod", "preview"
+	copyInclude = []string{"LOG_*"}
+	copyDryRun = true
+	defer func() { copyFrom, copyTo, copyInclude, copyDryRun = "", "", nil, false }()
+
+	var applied map[string]string
+	var target string
+	copyTestDeps(t, &applied, &target)
+
+	output := captureOutput(func() {
+		if err := runCopy(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if applied != nil {
+		t.Fatal("dry run must not apply changes")
+	}
+	if !contains(output, "~ LOG_LEVEL: debug → info") || contains(output, "API_TOKEN") {
+		t.Errorf("expected only LOG_LEVEL in diff, got: %s", output)
+	}
+}
+
+func TestRunCopy_SameEgg(t *testing.T) {
+	copyFrom, copyTo = "prod", "prod"
+	defer func() { copyFrom, copyTo = "", "" }()
+
+	var applied map[string]string
+	var target string
+	copyTestDeps(t, &applied, &target)
+
+	err := runCopy(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "same egg") {
+		t.Fatalf("expected same egg error, got: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFile     string
+	diffKeysOnly bool
+)
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff EGG OTHER_EGG | diff [EGG] --file FILE",
+		Short: "Compare environment variables between eggs or with a local file",
+		Long: `Compare the environment variables of two eggs, or of an egg and a local
+dotenv file, and list keys that exist on only one side or have different
+values.
+
+Sensitive values are never printed: they are shown as short keyed
+fingerprints. The key is random for each run, so fingerprints can only be
+compared within one run's output. With --keys-only
+only missing keys count, which suits template files such as .env.example.
+
+Exits with a non-zero status when there are differences, so it can be used
+as a CI check:
+
+  hatch env diff staging-egg prod-egg
+  hatch env diff prod-egg --file .env.example --keys-only`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: runDiff,
+	}
+	cmd.Flags().StringVar(&diffFile, "file", "", "compare with a local dotenv file instead of a second egg")
+	cmd.Flags().BoolVar(&diffKeysOnly, "keys-only", false, "only report keys missing on either side, not differing values")
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	if diffFile == "" && len(args) != 2 {
+		return fmt.Errorf("specify two eggs to compare, or one egg and --file")
+	}
+	if diffFile != "" && len(args) > 1 {
+		return fmt.Errorf("specify at most one egg with --file")
+	}
+
+	var right []api.EnvVar
+	if diffFile != "" {
+		// Parse before any network call so a bad file fails fast
+		vars, err := parseEnvFile(diffFile)
+		if err != nil {
+			return err
+		}
+		right = vars
+	}
+
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	var leftName, rightName string
+	switch {
+	case diffFile == "":
+		leftName, rightName = slugForName(ctx, args[0]), slugForName(ctx, args[1])
+	case len(args) == 1:
+		leftName, rightName = slugForName(ctx, args[0]), diffFile
+	default:
+		slug, err := resolveSlug(ctx)
+		if err != nil {
+			return err
+		}
+		leftName, rightName = slug, diffFile
+	}
+
+	sp := ui.NewSpinner("Fetching environment variables...")
+	sp.Start()
+	left, err := deps.GetEnvVars(ctx, token, leftName)
+	if err == nil && diffFile == "" {
+		right, err = deps.GetEnvVars(ctx, token, rightName)
+	}
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("fetching env vars: %w", err)
+	}
+
+	// With prune, diffEnv reports left-only keys as removals and right-only
+	// keys as additions
+	all, _ := diffEnv(left, right, true)
+	var changes []envChange
+	for _, c := range all {
+		if diffKeysOnly && c.Op == opChange {
+			continue
+		}
+		changes = append(changes, c)
+	}
+
+	if len(changes) == 0 {
+		ui.Success(fmt.Sprintf("No differences between %s and %s.", leftName, rightName))
+		return nil
+	}
+
+	fmt.Printf("Comparing %s with %s\n", ui.Bold(leftName), ui.Bold(rightName))
+	printDrift(changes, leftName, rightName)
+	fmt.Println()
+	return fmt.Errorf("%s and %s differ in %d key(s)", leftName, rightName, len(changes))
+}
+
+// printDrift prints the differences between two env var sets, showing
+// fingerprints instead of sensitive values.
+func printDrift(changes []envChange, leftName, rightName string) {
+	for _, c := range changes {
+		switch c.Op {
+		case opRemove:
+			fmt.Println(ui.Red(fmt.Sprintf("  - %s", c.Key)) + ui.Dim(fmt.Sprintf("  (only in %s)", leftName)))
+		case opAdd:
+			fmt.Println(ui.Green(fmt.Sprintf("  + %s", c.Key)) + ui.Dim(fmt.Sprintf("  (only in %s)", rightName)))
+		case opChange:
+			fmt.Println(ui.Yellow(fmt.Sprintf("  ~ %s: %s → %s", c.Key, driftValue(c.Key, c.OldValue), driftValue(c.Key, c.NewValue))))
+		}
+	}
+}
+
+func driftValue(key, value string) string {
+	if secret.IsSensitive(key) {
+		return secret.Fingerprint(value)
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package env
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
+)
+
+func diffTestDeps(t *testing.T, eggs map[string][]api.EnvVar) {
+	t.Helper()
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return eggs[slug], nil
+		},
+	}
+	t.Cleanup(func() { deps = defaultDeps() })
+}
+
+func TestRunDiff_Eggs(t *testing.T) {
+	diffTestDeps(t, map[string][]api.EnvVar{
+		"staging": {
+			{Key: "LOG_LEVEL", Value: "debug"},
+			{Key: "API_KEY", Value: "sk_test_1234567890"},
+			{Key: "DEBUG_TOOLBAR", Value: "1"},
+			{Key: "PORT", Value: "8080"},
+		},
+		"prod": {
+			{Key: "LOG_LEVEL", Value: "info"},
+			{Key: "API_KEY", Value: "sk_live_0987654321"},
+			{Key: "SENTRY_DSN", Value: "https://x@sentry.io/1"},
+			{Key: "PORT", Value: "8080"},
+		},
+	})
+
+	var err error
+	out := captureOutput(func() { err = runDiff(nil, []string{"staging", "prod"}) })
+
+	if err == nil || err.Error() != "staging and prod differ in 4 key(s)" {
+		t.Fatalf("expected drift error, got: %v", err)
+	}
+	for _, want := range []string{
+		"- DEBUG_TOOLBAR", "only in staging",
+		"+ SENTRY_DSN", "only in prod",
+		"~ LOG_LEVEL: debug → info",
+		"~ API_KEY: " + secret.Fingerprint("sk_test_1234567890") + " → " + secret.Fingerprint("sk_live_0987654321"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sk_test") || strings.Contains(out, "sk_live") {
+		t.Errorf("secret value leaked into output:\n%s", out)
+	}
+	if strings.Contains(out, "PORT") {
+		t.Errorf("equal keys should not be listed:\n%s", out)
+	}
+}
+
+func TestRunDiff_FileKeysOnly(t *testing.T) {
+	appSlug = "prod"
+	diffFile = writeEnvFile(t, "LOG_LEVEL=\nAPI_KEY=\nNEW_FLAG=\n")
+	diffKeysOnly = true
+	defer func() { appSlug = ""; diffFile = ""; diffKeysOnly = false }()
+
+	diffTestDeps(t, map[string][]api.EnvVar{
+		"prod": {{Key: "LOG_LEVEL", Value: "info"}, {Key: "API_KEY", Value: "sk_live_0987654321"}},
+	})
+
+	var err error
+	out := captureOutput(func() { err = runDiff(nil, nil) })
+
+	if err == nil || !strings.Contains(err.Error(), "differ in 1 key(s)") {
+		t.Fatalf("expected one missing key, got: %v", err)
+	}
+	if !strings.Contains(out, "+ NEW_FLAG") || strings.Contains(out, "LOG_LEVEL") {
+		t.Errorf("expected only the missing key to be reported, got:\n%s", out)
+	}
+}
+
+func TestRunDiff_NoDrift(t *testing.T) {
+	vars := []api.EnvVar{{Key: "PORT", Value: "8080"}}
+	diffTestDeps(t, map[string][]api.EnvVar{"a": vars, "b": vars})
+
+	var err error
+	out := captureOutput(func() { err = runDiff(nil, []string{"a", "b"}) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "No differences between a and b") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestRunDiff_Args(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want string
+	}{
+		{"one egg", "", []string{"a"}, "specify two eggs"},
+		{"two eggs with file", ".env", []string{"a", "b"}, "at most one egg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffFile = tt.file
+			defer func() { diffFile = "" }()
+
+			err := runDiff(nil, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q error, got: %v", tt.want, err)
+			}
+		})
+	}
+}
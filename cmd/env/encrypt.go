@@ -0,0 +1,169 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envcrypt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	encryptOutput     string
+	encryptRecipients []string
+)
+
+func newKeygenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keygen",
+		Short: "Create the key used to decrypt encrypted env files",
+		Long: `Create an X25519 key pair for encrypted env files and store it in
+~/.hatch/env.key. An existing key is never replaced.
+
+The printed public key can be shared: add it to .hatch-recipients in the
+project so that files encrypted with 'hatch env encrypt' are readable by
+you.`,
+		Args: cobra.NoArgs,
+		RunE: runKeygen,
+	}
+}
+
+func newEncryptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt FILE",
+		Short: "Encrypt the values of a .env file so it can be committed",
+		Long: `Encrypt the values of a dotenv file and write FILE.enc. Keys stay readable
+so changes can be reviewed in diffs; values can only be decrypted by the
+recipients.
+
+Recipients are the public keys in .hatch-recipients (one per line), any
+--recipient flags, and your own key from 'hatch env keygen'. Re-encrypting
+to the same recipients keeps the ciphertext of unchanged values, so a diff
+shows only the keys that changed. Apply the file with:
+
+  hatch env push --encrypted FILE.enc`,
+		Args: cobra.ExactArgs(1),
+		RunE: runEncrypt,
+	}
+	cmd.Flags().StringVarP(&encryptOutput, "output", "o", "", "file to write (default FILE.enc)")
+	cmd.Flags().StringArrayVar(&encryptRecipients, "recipient", nil, "public key (hatch-pub-...) to encrypt to (repeatable)")
+	return cmd
+}
+
+func runKeygen(cmd *cobra.Command, args []string) error {
+	path, err := envcrypt.IdentityPath()
+	if err != nil {
+		return err
+	}
+	if existing, err := envcrypt.LoadIdentity(path); err == nil {
+		return fmt.Errorf("%s already exists (public key: %s)", path, existing.Recipient())
+	}
+
+	id, err := envcrypt.GenerateIdentity()
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+	if err := envcrypt.SaveIdentity(path, id); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	ui.Success(fmt.Sprintf("Wrote %s", path))
+	fmt.Println(id.Recipient())
+	fmt.Println(ui.Dim(fmt.Sprintf("Add the public key above to %s to be able to decrypt the project's env files.", envcrypt.RecipientsFile)))
+	return nil
+}
+
+func runEncrypt(cmd *cobra.Command, args []string) error {
+	input := args[0]
+	output := encryptOutput
+	if output == "" {
+		output = input + ".enc"
+	}
+
+	if data, err := os.ReadFile(input); err == nil && envcrypt.IsEncrypted(data) {
+		return fmt.Errorf("%s is already encrypted", input)
+	}
+	vars, err := parseEnvFile(input)
+	if err != nil {
+		return err
+	}
+
+	recipients, err := envcrypt.ReadRecipients(envcrypt.RecipientsFile)
+	if err != nil {
+		return err
+	}
+	for _, s := range encryptRecipients {
+		r, err := envcrypt.ParseRecipient(s)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, r)
+	}
+	id, err := loadIdentity()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if id != nil {
+		recipients = append(recipients, id.Recipient())
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipients. Run 'hatch env keygen', add public keys to %s, or use --recipient", envcrypt.RecipientsFile)
+	}
+
+	entries := make([]dotenv.Entry, 0, len(vars))
+	for _, v := range vars {
+		entries = append(entries, dotenv.Entry{Key: v.Key, Value: v.Value})
+	}
+	previous, _ := os.ReadFile(output)
+	data, err := envcrypt.Encrypt(entries, recipients, previous, id)
+	if err != nil {
+		return fmt.Errorf("encrypting %s: %w", input, err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	ui.Success(fmt.Sprintf("Encrypted %d variables from %s to %s", len(entries), input, output))
+	return nil
+}
+
+// loadIdentity reads the local key from ~/.hatch/env.key.
+func loadIdentity() (*envcrypt.Identity, error) {
+	path, err := envcrypt.IdentityPath()
+	if err != nil {
+		return nil, err
+	}
+	return envcrypt.LoadIdentity(path)
+}
+
+// decryptEnvFile reads an encrypted env file with the local key.
+func decryptEnvFile(path string) ([]api.EnvVar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	id, err := loadIdentity()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no decryption key. Run 'hatch env keygen' and ask a teammate to re-encrypt %s to your public key", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := envcrypt.Decrypt(data, id)
+	if errors.Is(err, envcrypt.ErrNoIdentity) {
+		return nil, fmt.Errorf("%s is not encrypted to your key (%s). Ask a teammate to add it to %s and re-encrypt", path, id.Recipient(), envcrypt.RecipientsFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", path, err)
+	}
+
+	vars := make([]api.EnvVar, 0, len(entries))
+	for _, e := range entries {
+		vars = append(vars, api.EnvVar{Key: e.Key, Value: e.Value})
+	}
+	return vars, nil
+}
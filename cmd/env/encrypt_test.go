@@ -0,0 +1,109 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envcrypt"
+)
+
+func TestEncryptAndPushEncrypted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	captureOutput(func() {
+		if err := runKeygen(nil, nil); err != nil {
+			t.Fatalf("keygen: %v", err)
+		}
+	})
+	if err := runKeygen(nil, nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected existing key to be kept, got: %v", err)
+	}
+
+	os.WriteFile(".env.production", []byte("API_TOKEN=supersecretvalue\nPORT=8080\n"), 0600)
+	captureOutput(func() {
+		if err := runEncrypt(nil, []string{".env.production"}); err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+	})
+	data, err := os.ReadFile(".env.production.enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "supersecretvalue") || !strings.Contains(string(data), "API_TOKEN=ENC[") {
+		t.Fatalf("unexpected encrypted file:\n%s", data)
+	}
+
+	// Without --encrypted the ciphertext must not be pushed as values
+	appSlug = "myapp"
+	defer func() { appSlug = ""; pushEncrypted = false; pushYes = false }()
+	if err := runPush(nil, []string{".env.production.enc"}); err == nil || !strings.Contains(err.Error(), "--encrypted") {
+		t.Fatalf("expected a hint to use --encrypted, got: %v", err)
+	}
+
+	var set map[string]string
+	deps = &Deps{
+		GetToken:   func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return nil, nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			set = vars
+			return &api.EnvUpdateResult{Added: []string{"API_TOKEN", "PORT"}}, nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	pushEncrypted, pushYes = true, true
+	output := captureOutput(func() {
+		if err := runPush(nil, []string{".env.production.enc"}); err != nil {
+			t.Fatalf("push: %v", err)
+		}
+	})
+	if set["API_TOKEN"] != "supersecretvalue" || set["PORT"] != "8080" {
+		t.Errorf("expected decrypted values to be applied, got %v", set)
+	}
+	if strings.Contains(output, "supersecretvalue") {
+		t.Errorf("secret value leaked in diff: %s", output)
+	}
+}
+
+func TestRunEncrypt_NoRecipients(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := writeEnvFile(t, "A=1\n")
+
+	err := runEncrypt(nil, []string{path})
+	if err == nil || !strings.Contains(err.Error(), "no recipients") {
+		t.Fatalf("expected no recipients error, got: %v", err)
+	}
+}
+
+func TestPushEncrypted_NotARecipient(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	other, _ := envcrypt.GenerateIdentity()
+	encryptRecipients = []string{other.Recipient().String()}
+	encryptOutput = filepath.Join(dir, "prod.enc")
+	defer func() { encryptRecipients = nil; encryptOutput = "" }()
+
+	captureOutput(func() {
+		if err := runEncrypt(nil, []string{writeEnvFile(t, "A=1\n")}); err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+	})
+
+	_, err := decryptEnvFile(encryptOutput)
+	if err == nil || !strings.Contains(err.Error(), "hatch env keygen") {
+		t.Fatalf("expected missing key hint, got: %v", err)
+	}
+
+	captureOutput(func() { runKeygen(nil, nil) })
+	_, err = decryptEnvFile(encryptOutput)
+	if err == nil || !strings.Contains(err.Error(), "not encrypted to your key") {
+		t.Fatalf("expected not-a-recipient error, got: %v", err)
+	}
+}
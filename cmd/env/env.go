@@ -2,36 +2,51 @@ package env
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envcrypt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/telemetry"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // Deps holds injectable dependencies for testing.
 type Deps struct {
-	GetToken    func() (string, error)
-	GetEnvVars  func(token, slug string) ([]api.EnvVar, error)
-	SetEnvVar   func(token, slug, key, value string) error
-	UnsetEnvVar func(token, slug, key string) error
+	GetToken   func() (string, error)
+	GetEnvVars func(ctx context.Context, token, slug string) ([]api.EnvVar, error)
+	SetEnvVars func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error)
+	ReadInput  func(prompt string) (string, error)
+	ReadSecret func(prompt string) (string, error)
+	ReadStdin  func() (string, error)
+	Restart    func(ctx context.Context, token, slug string) error
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		GetEnvVars: func(token, slug string) ([]api.EnvVar, error) {
-			return api.NewClient(token).GetEnvVars(slug)
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return api.NewClient(token).GetEnvVars(ctx, slug)
 		},
-		SetEnvVar: func(token, slug, key, value string) error {
-			return api.NewClient(token).SetEnvVar(slug, key, value)
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			return api.NewClient(token).SetEnvVars(ctx, slug, vars, unset)
 		},
-		UnsetEnvVar: func(token, slug, key string) error {
-			return api.NewClient(token).UnsetEnvVar(slug, key)
+		ReadInput:  readInput,
+		ReadSecret: readSecret,
+		ReadStdin:  readStdin,
+		Restart: func(ctx context.Context, token, slug string) error {
+			return api.NewClient(token).RestartAndWait(ctx, slug, restartTimeout)
 		},
 	}
 }
@@ -41,6 +56,20 @@ var deps = defaultDeps()
 var appSlug string
 var envFile string
 
+// restartTimeout bounds the wait for the egg to run again after a restart.
+const restartTimeout = 3 * time.Minute
+
+var (
+	restartAfter = true
+	noRestart    bool
+)
+
+var (
+	setStdin     bool
+	setSecret    bool
+	setFromFiles []string
+)
+
 // NewCmd returns the env command with set/unset subcommands.
 func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -52,13 +81,25 @@ func NewCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&appSlug, "app", "a", "", "egg slug (auto-detected from git remote if omitted)")
 
 	setCmd := &cobra.Command{
-		Use:   "set [KEY=VALUE...]",
+		Use:   "set [KEY=VALUE...] [KEY --secret|--stdin]",
 		Short: "Set environment variables",
-		Long:  "Set one or more environment variables on a Hatch egg. Values should be in KEY=VALUE format, or use --from-env to import from a .env file.",
-		Args:  cobra.ArbitraryArgs,
-		RunE:  runSet,
+		Long: `Set one or more environment variables on a Hatch egg. Values should be in KEY=VALUE format, or use --from-env to import from a .env file.
+
+Values given as KEY=VALUE end up in shell history and are visible in ps
+output. For secrets, pass only the key and read the value another way:
+
+  hatch env set API_KEY --secret              prompt without echo
+  printf %s "$TOKEN" | hatch env set API_KEY --stdin
+  hatch env set --from-file TLS_CERT=cert.pem  use a file's contents`,
+		Args:        cobra.ArbitraryArgs,
+		RunE:        runSet,
+		Annotations: map[string]string{telemetry.AnnotationKeysOnly: "true"},
 	}
 	setCmd.Flags().StringVarP(&envFile, "from-env", "f", "", "path to .env file to import")
+	setCmd.Flags().BoolVar(&setStdin, "stdin", false, "read the value of the single KEY argument from stdin")
+	setCmd.Flags().BoolVar(&setSecret, "secret", false, "prompt for the value of each KEY argument without echo")
+	setCmd.Flags().StringArrayVar(&setFromFiles, "from-file", nil, "set KEY to the contents of a file, as KEY=path (repeatable)")
+	addRestartFlags(setCmd)
 
 	unsetCmd := &cobra.Command{
 		Use:   "unset KEY [KEY...]",
@@ -67,12 +108,14 @@ func NewCmd() *cobra.Command {
 		Args:  cobra.MinimumNArgs(1),
 		RunE:  runUnset,
 	}
+	addRestartFlags(unsetCmd)
 
-	cmd.AddCommand(setCmd, unsetCmd)
+	cmd.AddCommand(setCmd, unsetCmd, newPullCmd(), newPushCmd(), newExportCmd(), newDiffCmd(), newCopyCmd(), newKeygenCmd(), newEncryptCmd())
 	return cmd
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -81,14 +124,14 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
 	}
 
-	slug, err := resolveSlug()
+	slug, err := resolveSlug(ctx)
 	if err != nil {
 		return err
 	}
 
 	sp := ui.NewSpinner("Fetching environment variables...")
 	sp.Start()
-	vars, err := deps.GetEnvVars(token, slug)
+	vars, err := deps.GetEnvVars(ctx, token, slug)
 	sp.Stop()
 
 	if err != nil {
@@ -102,30 +145,21 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	table := ui.NewTable(os.Stdout, "KEY", "VALUE")
 	for _, v := range vars {
-		// Mask sensitive values
-		displayValue := v.Value
-		sensitiveKeys := []string{"PASSWORD", "SECRET", "TOKEN", "KEY", "DSN", "DATABASE_URL", "API_KEY", "PRIVATE"}
-		for _, sk := range sensitiveKeys {
-			if strings.Contains(strings.ToUpper(v.Key), sk) {
-				if len(v.Value) > 8 {
-					displayValue = v.Value[:4] + "****" + v.Value[len(v.Value)-4:]
-				} else {
-					displayValue = "****"
-				}
-				break
-			}
-		}
-		table.AddRow(v.Key, displayValue)
+		table.AddRow(v.Key, secret.Mask(v.Key, v.Value))
 	}
 	table.Render()
 	return nil
 }
 
 func runSet(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	// Validate inputs
-	if envFile == "" && len(args) == 0 {
+	if envFile == "" && len(args) == 0 && len(setFromFiles) == 0 {
 		return fmt.Errorf("no environment variables specified. Provide KEY=VALUE arguments or use --from-env")
 	}
+	if setStdin && setSecret {
+		return fmt.Errorf("--stdin and --secret cannot be used together")
+	}
 
 	token, err := deps.GetToken()
 	if err != nil {
@@ -135,84 +169,108 @@ func runSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
 	}
 
-	slug, err := resolveSlug()
+	slug, err := resolveSlug(ctx)
 	if err != nil {
 		return err
 	}
 
-	// Process .env file if specified
+	vars := map[string]string{}
+
+	// Collect .env file entries first so positional arguments override them
 	if envFile != "" {
-		if err := processEnvFile(token, slug, envFile); err != nil {
+		fileVars, err := parseEnvFile(envFile)
+		if err != nil {
 			return err
 		}
+		for _, v := range fileVars {
+			vars[v.Key] = v.Value
+		}
 	}
 
-	// Process positional KEY=VALUE arguments
-	for _, arg := range args {
-		parts := strings.SplitN(arg, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid format %q, expected KEY=VALUE. To specify an egg, use: hatch env set -a <slug> KEY=VALUE", arg)
+	// Collect --from-file contents
+	for _, spec := range setFromFiles {
+		key, path, ok := strings.Cut(spec, "=")
+		if !ok || key == "" || path == "" {
+			return fmt.Errorf("invalid --from-file %q, expected KEY=path", spec)
 		}
-		key, value := parts[0], parts[1]
-
-		if err := deps.SetEnvVar(token, slug, key, value); err != nil {
-			return fmt.Errorf("setting %s: %w", key, err)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading value for %s: %w", key, err)
 		}
-		ui.Success(fmt.Sprintf("Set %s", key))
-	}
-	return nil
-}
-
-// processEnvFile reads a .env file and sets each variable
-func processEnvFile(token, slug, filePath string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("opening .env file: %w", err)
+		vars[key] = string(data)
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+	// Collect positional arguments: KEY=VALUE, or a bare KEY whose value is
+	// read from stdin or a hidden prompt so it never appears in argv
+	var bareKeys []string
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			if !setStdin && !setSecret {
+				return fmt.Errorf("invalid format %q, expected KEY=VALUE (or KEY with --secret or --stdin). To specify an egg, use: hatch env set -a <slug> KEY=VALUE", arg)
+			}
+			bareKeys = append(bareKeys, arg)
 			continue
 		}
+		vars[key] = value
+	}
+	if (setStdin || setSecret) && len(bareKeys) == 0 {
+		return fmt.Errorf("--stdin and --secret need a KEY argument without a value")
+	}
 
-		// Split on first =
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid format at line %d: %q (expected KEY=VALUE)", lineNum, line)
+	if setStdin {
+		if len(bareKeys) != 1 {
+			return fmt.Errorf("--stdin reads a single value, but %d keys were given", len(bareKeys))
 		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		// Trim surrounding quotes from value
-		if len(value) >= 2 {
-			if (value[0] == '"' && value[len(value)-1] == '"') ||
-				(value[0] == '\'' && value[len(value)-1] == '\'') {
-				value = value[1 : len(value)-1]
+		value, err := deps.ReadStdin()
+		if err != nil {
+			return fmt.Errorf("reading value for %s from stdin: %w", bareKeys[0], err)
+		}
+		vars[bareKeys[0]] = value
+	}
+	if setSecret {
+		for _, key := range bareKeys {
+			value, err := deps.ReadSecret(fmt.Sprintf("Value for %s: ", key))
+			if err != nil {
+				return fmt.Errorf("reading value for %s: %w", key, err)
 			}
+			if value == "" {
+				return fmt.Errorf("no value entered for %s", key)
+			}
+			vars[key] = value
 		}
+	}
 
-		if err := deps.SetEnvVar(token, slug, key, value); err != nil {
-			return fmt.Errorf("setting %s: %w", key, err)
-		}
-		ui.Success(fmt.Sprintf("Set %s", key))
+	result, err := deps.SetEnvVars(ctx, token, slug, vars, nil)
+	if err != nil {
+		return fmt.Errorf("setting env vars: %w", err)
 	}
+	printEnvUpdateResult(slug, result)
+	return restartIfChanged(ctx, token, slug, result)
+}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("reading .env file: %w", err)
+// parseEnvFile reads KEY=VALUE pairs from a .env file in file order.
+// References to variables not defined in the file resolve against the local
+// environment, as they would when the file is loaded by a dotenv library.
+func parseEnvFile(filePath string) ([]api.EnvVar, error) {
+	// Reading an encrypted file as plain dotenv would yield ciphertext values
+	if data, err := os.ReadFile(filePath); err == nil && envcrypt.IsEncrypted(data) {
+		return nil, fmt.Errorf("%s is encrypted. Use 'hatch env push --encrypted %s'", filePath, filePath)
+	}
+	entries, err := dotenv.ParseFile(filePath, dotenv.Options{Lookup: os.LookupEnv})
+	if err != nil {
+		return nil, fmt.Errorf("reading .env file: %w", err)
 	}
 
-	return nil
+	vars := make([]api.EnvVar, 0, len(entries))
+	for _, e := range entries {
+		vars = append(vars, api.EnvVar{Key: e.Key, Value: e.Value})
+	}
+	return vars, nil
 }
 
 func runUnset(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -221,39 +279,126 @@ func runUnset(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
 	}
 
-	slug, err := resolveSlug()
+	slug, err := resolveSlug(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, key := range args {
-		if err := deps.UnsetEnvVar(token, slug, key); err != nil {
-			return fmt.Errorf("unsetting %s: %w", key, err)
-		}
-		ui.Success(fmt.Sprintf("Unset %s", key))
+	result, err := deps.SetEnvVars(ctx, token, slug, nil, args)
+	if err != nil {
+		return fmt.Errorf("unsetting env vars: %w", err)
 	}
+	printEnvUpdateResult(slug, result)
+	return restartIfChanged(ctx, token, slug, result)
+}
+
+// addRestartFlags adds --restart and --no-restart to a command that changes
+// env vars.
+func addRestartFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&restartAfter, "restart", true, "restart the egg once after the changes and wait until it runs (default)")
+	cmd.Flags().BoolVar(&noRestart, "no-restart", false, "apply the changes without restarting the egg")
+	cmd.MarkFlagsMutuallyExclusive("restart", "no-restart")
+}
+
+// restartIfChanged restarts the egg once after a batch of changes so that it
+// picks them up, and waits until it runs again.
+func restartIfChanged(ctx context.Context, token, slug string, result *api.EnvUpdateResult) error {
+	if result.Changed() == 0 {
+		return nil
+	}
+	if noRestart || !restartAfter {
+		ui.Info(fmt.Sprintf("Not restarted. Run 'hatch restart %s' for the egg to pick up the changes.", slug))
+		return nil
+	}
+
+	sp := ui.NewSpinner(fmt.Sprintf("Restarting %s...", slug))
+	sp.Start()
+	err := deps.Restart(ctx, token, slug)
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("env vars were applied, but restarting %s failed: %w", slug, err)
+	}
+	ui.Success(fmt.Sprintf("Restarted %s; it is running", slug))
 	return nil
 }
 
-func resolveSlug() (string, error) {
+// printEnvUpdateResult reports exactly which keys a bulk update changed.
+func printEnvUpdateResult(slug string, result *api.EnvUpdateResult) {
+	for _, key := range result.Added {
+		ui.Success(fmt.Sprintf("Added %s", key))
+	}
+	for _, key := range result.Updated {
+		ui.Success(fmt.Sprintf("Updated %s", key))
+	}
+	for _, key := range result.Removed {
+		ui.Success(fmt.Sprintf("Removed %s", key))
+	}
+	if len(result.Unchanged) > 0 {
+		fmt.Println(ui.Dim(fmt.Sprintf("  Unchanged: %s", strings.Join(result.Unchanged, ", "))))
+	}
+	if result.Changed() == 0 {
+		ui.Info(fmt.Sprintf("No changes to %s.", slug))
+	}
+}
+
+func resolveSlug(ctx context.Context) (string, error) {
 	if appSlug != "" {
-		// Try to resolve as app name by listing apps
-		token, err := deps.GetToken()
-		if err == nil && token != "" {
-			client := api.NewClient(token)
-			apps, err := client.ListApps()
-			if err == nil {
-				for _, app := range apps {
-					if app.Name == appSlug || app.Slug == appSlug {
-						return app.Slug, nil
-					}
-				}
-			}
-		}
-		return appSlug, nil
+		return slugForName(ctx, appSlug), nil
 	}
 	if slug := resolve.SlugFromToml(); slug != "" {
 		return slug, nil
 	}
 	return "", fmt.Errorf("no egg specified. Use --app <slug> (or set slug in .hatch.toml)")
 }
+
+// slugForName returns the slug of the egg with the given name or slug,
+// falling back to name itself if the egg list can't be fetched.
+func slugForName(ctx context.Context, name string) string {
+	// Try to resolve as app name by listing apps
+	token, err := deps.GetToken()
+	if err == nil && token != "" {
+		client := api.NewClient(token)
+		apps, err := client.ListApps(ctx)
+		if err == nil {
+			for _, app := range apps {
+				if app.Name == name || app.Slug == name {
+					return app.Slug
+				}
+			}
+		}
+	}
+	return name
+}
+
+// readSecret prompts on stderr and reads a line from the terminal without
+// echoing it.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("stdin is not a terminal; use --stdin to pipe the value")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// readStdin reads the whole of stdin, dropping one trailing newline so that
+// `echo value | hatch env set KEY --stdin` does what it looks like.
+func readStdin() (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+func readInput(prompt string) (string, error) {
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	return reader.ReadString('\n')
+}
@@ -2,6 +2,7 @@ package env
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -43,7 +44,7 @@ func TestRunList_ShowsVars(t *testing.T) {
 
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		GetEnvVars: func(token, slug string) ([]api.EnvVar, error) {
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
 			return []api.EnvVar{
 				{Key: "PORT", Value: "8080"},
 				{Key: "NODE_ENV", Value: "production"},
@@ -73,7 +74,7 @@ func TestRunList_Empty(t *testing.T) {
 
 	deps = &Deps{
 		GetToken:   func() (string, error) { return "tok123", nil },
-		GetEnvVars: func(token, slug string) ([]api.EnvVar, error) { return nil, nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return nil, nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -109,13 +110,16 @@ func TestRunSet_Success(t *testing.T) {
 	appSlug = "myapp"
 	defer func() { appSlug = "" }()
 
-	setKeys := []string{}
+	calls := 0
+	var got map[string]string
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		SetEnvVar: func(token, slug, key, value string) error {
-			setKeys = append(setKeys, key+"="+value)
-			return nil
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			calls++
+			got = vars
+			return &api.EnvUpdateResult{Added: []string{"PORT"}, Updated: []string{"DB"}}, nil
 		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -126,11 +130,99 @@ func TestRunSet_Success(t *testing.T) {
 		}
 	})
 
-	if len(setKeys) != 2 {
-		t.Fatalf("expected 2 set calls, got %d", len(setKeys))
+	if calls != 1 {
+		t.Fatalf("expected a single bulk call, got %d", calls)
 	}
-	if !contains(output, "Set PORT") {
-		t.Fatalf("expected success message, got: %s", output)
+	if len(got) != 2 || got["PORT"] != "8080" || got["DB"] != "postgres://localhost" {
+		t.Fatalf("unexpected vars sent: %v", got)
+	}
+	if !contains(output, "Added PORT") || !contains(output, "Updated DB") {
+		t.Fatalf("expected changed keys in output, got: %s", output)
+	}
+}
+
+func TestRunSet_FromEnvMergesWithArgs(t *testing.T) {
+	appSlug = "myapp"
+	envFile = writeEnvFile(t, "PORT=3000\nNAME=app\n")
+	defer func() { appSlug = ""; envFile = "" }()
+
+	var got map[string]string
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			got = vars
+			return &api.EnvUpdateResult{Unchanged: []string{"NAME", "PORT"}}, nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	output := captureOutput(func() {
+		if err := runSet(nil, []string{"PORT=8080"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if got["PORT"] != "8080" || got["NAME"] != "app" {
+		t.Fatalf("expected file vars overridden by args, got %v", got)
+	}
+	if !contains(output, "No changes to myapp") {
+		t.Fatalf("expected no-change message, got: %s", output)
+	}
+}
+
+func TestRunSet_FromEnvKeepsMultiLineValues(t *testing.T) {
+	appSlug = "myapp"
+	envFile = writeEnvFile(t, "export HOST=db\nURL=postgres://${HOST}/app # primary\nPEM=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"\n")
+	defer func() { appSlug = ""; envFile = "" }()
+
+	var got map[string]string
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			got = vars
+			return &api.EnvUpdateResult{}, nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	captureOutput(func() {
+		if err := runSet(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if got["URL"] != "postgres://db/app" {
+		t.Errorf("expected interpolated URL without comment, got %q", got["URL"])
+	}
+	if got["PEM"] != "-----BEGIN KEY-----\nabc\n-----END KEY-----" {
+		t.Errorf("expected multi-line PEM value, got %q", got["PEM"])
+	}
+}
+
+func TestRunSet_FromEnvReportsPosition(t *testing.T) {
+	appSlug = "myapp"
+	envFile = writeEnvFile(t, "A=1\nB=\"unterminated\n")
+	defer func() { appSlug = ""; envFile = "" }()
+
+	called := false
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			called = true
+			return &api.EnvUpdateResult{}, nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	err := runSet(nil, nil)
+	if err == nil || !contains(err.Error(), "line 2, column 3: unterminated double-quoted value") {
+		t.Fatalf("expected positioned parse error, got: %v", err)
+	}
+	if called {
+		t.Fatal("nothing should be sent when the file does not parse")
 	}
 }
 
@@ -158,9 +250,10 @@ func TestRunSet_APIError(t *testing.T) {
 
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		SetEnvVar: func(token, slug, key, value string) error {
-			return fmt.Errorf("permission denied")
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			return nil, fmt.Errorf("permission denied")
 		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -168,7 +261,7 @@ func TestRunSet_APIError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if !contains(err.Error(), "setting PORT") {
+	if !contains(err.Error(), "setting env vars: permission denied") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -177,13 +270,14 @@ func TestRunUnset_Success(t *testing.T) {
 	appSlug = "myapp"
 	defer func() { appSlug = "" }()
 
-	unsetKeys := []string{}
+	var unsetKeys []string
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		UnsetEnvVar: func(token, slug, key string) error {
-			unsetKeys = append(unsetKeys, key)
-			return nil
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			unsetKeys = unset
+			return &api.EnvUpdateResult{Removed: unset}, nil
 		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -195,9 +289,9 @@ func TestRunUnset_Success(t *testing.T) {
 	})
 
 	if len(unsetKeys) != 2 {
-		t.Fatalf("expected 2 unset calls, got %d", len(unsetKeys))
+		t.Fatalf("expected 2 keys to unset, got %v", unsetKeys)
 	}
-	if !contains(output, "Unset PORT") {
+	if !contains(output, "Removed PORT") {
 		t.Fatalf("expected success message, got: %s", output)
 	}
 }
@@ -208,9 +302,10 @@ func TestRunUnset_APIError(t *testing.T) {
 
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		UnsetEnvVar: func(token, slug, key string) error {
-			return fmt.Errorf("not found")
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			return nil, fmt.Errorf("not found")
 		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
 	}
 	defer func() { deps = defaultDeps() }()
 
@@ -218,7 +313,7 @@ func TestRunUnset_APIError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if !contains(err.Error(), "unsetting MISSING") {
+	if !contains(err.Error(), "unsetting env vars: not found") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -231,3 +326,123 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+// setSecretTestDeps records what runSet sends and serves values for bare keys.
+func setSecretTestDeps(t *testing.T, stdin string, prompted map[string]string) *map[string]string {
+	t.Helper()
+	got := map[string]string{}
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			got = vars
+			return &api.EnvUpdateResult{}, nil
+		},
+		ReadStdin: func() (string, error) { return stdin, nil },
+		ReadSecret: func(prompt string) (string, error) {
+			for key, value := range prompted {
+				if contains(prompt, key) {
+					return value, nil
+				}
+			}
+			return "", nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	appSlug = "myapp"
+	t.Cleanup(func() {
+		deps = defaultDeps()
+		appSlug = ""
+		setStdin, setSecret, setFromFiles = false, false, nil
+	})
+	return &got
+}
+
+func TestRunSet_Stdin(t *testing.T) {
+	got := setSecretTestDeps(t, "sk_live_abc", nil)
+	setStdin = true
+
+	output := captureOutput(func() {
+		if err := runSet(nil, []string{"API_KEY", "PORT=8080"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if (*got)["API_KEY"] != "sk_live_abc" || (*got)["PORT"] != "8080" {
+		t.Fatalf("unexpected vars: %v", *got)
+	}
+	if contains(output, "sk_live_abc") {
+		t.Errorf("secret echoed in output: %s", output)
+	}
+}
+
+func TestRunSet_StdinSingleKeyOnly(t *testing.T) {
+	setSecretTestDeps(t, "x", nil)
+	setStdin = true
+
+	err := runSet(nil, []string{"A", "B"})
+	if err == nil || !contains(err.Error(), "single value") {
+		t.Fatalf("expected single-value error, got: %v", err)
+	}
+}
+
+func TestRunSet_SecretPrompt(t *testing.T) {
+	got := setSecretTestDeps(t, "", map[string]string{"DB_PASSWORD": "hunter2", "API_KEY": "abc"})
+	setSecret = true
+
+	captureOutput(func() {
+		if err := runSet(nil, []string{"DB_PASSWORD", "API_KEY"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if (*got)["DB_PASSWORD"] != "hunter2" || (*got)["API_KEY"] != "abc" {
+		t.Fatalf("unexpected vars: %v", *got)
+	}
+}
+
+func TestRunSet_SecretPromptEmptyValue(t *testing.T) {
+	setSecretTestDeps(t, "", nil)
+	setSecret = true
+
+	err := runSet(nil, []string{"DB_PASSWORD"})
+	if err == nil || !contains(err.Error(), "no value entered for DB_PASSWORD") {
+		t.Fatalf("expected empty value error, got: %v", err)
+	}
+}
+
+func TestRunSet_FromFile(t *testing.T) {
+	got := setSecretTestDeps(t, "", nil)
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	path := writeEnvFile(t, pem)
+	setFromFiles = []string{"TLS_CERT=" + path}
+
+	captureOutput(func() {
+		if err := runSet(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if (*got)["TLS_CERT"] != pem {
+		t.Fatalf("expected file contents verbatim, got %q", (*got)["TLS_CERT"])
+	}
+}
+
+func TestRunSet_FromFileInvalidSpec(t *testing.T) {
+	setSecretTestDeps(t, "", nil)
+	setFromFiles = []string{"TLS_CERT"}
+
+	err := runSet(nil, nil)
+	if err == nil || !contains(err.Error(), "expected KEY=path") {
+		t.Fatalf("expected spec error, got: %v", err)
+	}
+}
+
+func TestRunSet_StdinAndSecretConflict(t *testing.T) {
+	setSecretTestDeps(t, "", nil)
+	setStdin, setSecret = true, true
+
+	err := runSet(nil, []string{"KEY"})
+	if err == nil || !contains(err.Error(), "cannot be used together") {
+		t.Fatalf("expected conflict error, got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envexport"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat = "dotenv"
+	exportReveal bool
+	exportOutput = "-"
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the egg's environment variables for other tools",
+		Long: fmt.Sprintf(`Print the egg's environment variables in a format other tools can consume.
+
+Formats: %s
+
+Sensitive values (keys containing TOKEN, SECRET, PASSWORD, KEY, ...) are
+masked unless --reveal is set. Keys are sorted. Use -o to write a file
+with 0600 permissions instead of printing to stdout.
+
+  hatch env export --format docker --reveal -o .env.docker
+  eval "$(hatch env export --format sh --reveal)"
+  hatch env export --format k8s-secret --reveal | kubectl apply -f -`, strings.Join(envexport.Names(), ", ")),
+		Args: cobra.NoArgs,
+		RunE: runExport,
+	}
+	cmd.Flags().StringVar(&exportFormat, "format", "dotenv", "output format ("+strings.Join(envexport.Names(), "|")+")")
+	cmd.Flags().BoolVar(&exportReveal, "reveal", false, "print sensitive values in full")
+	cmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "file to write (- for stdout)")
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	// Reject a bad format before any network call
+	enc, err := envexport.Get(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	slug, err := resolveSlug(ctx)
+	if err != nil {
+		return err
+	}
+
+	vars, err := deps.GetEnvVars(ctx, token, slug)
+	if err != nil {
+		return fmt.Errorf("fetching env vars: %w", err)
+	}
+
+	out, masked := exportVars(vars, exportReveal)
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, out, envexport.Meta{App: slug}); err != nil {
+		return fmt.Errorf("encoding %s: %w", exportFormat, err)
+	}
+
+	if masked > 0 {
+		fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("%d sensitive values masked. Use --reveal to export them in full.", masked)))
+	}
+
+	if exportOutput == "-" {
+		fmt.Print(buf.String())
+		return nil
+	}
+	if err := os.WriteFile(exportOutput, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", exportOutput, err)
+	}
+	ui.Success(fmt.Sprintf("Exported %d variables from %s to %s", len(out), slug, exportOutput))
+	return nil
+}
+
+// exportVars returns vars sorted by key, with sensitive values masked unless
+// reveal is set, and the number of values that were masked.
+func exportVars(vars []api.EnvVar, reveal bool) ([]api.EnvVar, int) {
+	out := make([]api.EnvVar, len(vars))
+	copy(out, vars)
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+
+	if reveal {
+		return out, 0
+	}
+	masked := 0
+	for i, v := range out {
+		if secret.IsSensitive(v.Key) {
+			out[i].Value = secret.Mask(v.Key, v.Value)
+			masked++
+		}
+	}
+	return out, masked
+}
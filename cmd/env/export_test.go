@@ -0,0 +1,101 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func TestRunExport(t *testing.T) {
+	vars := []api.EnvVar{
+		{Key: "PORT", Value: "8080"},
+		{Key: "API_KEY", Value: "sk_live_abcdefgh1234"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		reveal bool
+		want   string
+	}{
+		{"masked json", "json", false, "{\n  \"API_KEY\": \"****1234\",\n  \"PORT\": \"8080\"\n}\n"},
+		{"revealed sh", "sh", true, "export API_KEY='sk_live_abcdefgh1234'\nexport PORT='8080'\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appSlug = "myapp"
+			exportFormat, exportReveal = tt.format, tt.reveal
+			defer func() { appSlug = ""; exportFormat = "dotenv"; exportReveal = false }()
+
+			deps = &Deps{
+				GetToken:   func() (string, error) { return "tok123", nil },
+				GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return vars, nil },
+			}
+			defer func() { deps = defaultDeps() }()
+
+			var err error
+			out := captureOutput(func() { err = runExport(nil, nil) })
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunExport_WritesFile(t *testing.T) {
+	appSlug = "myapp"
+	exportFormat, exportReveal = "docker", true
+	exportOutput = filepath.Join(t.TempDir(), ".env.docker")
+	defer func() { appSlug = ""; exportFormat = "dotenv"; exportReveal = false; exportOutput = "-" }()
+
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return []api.EnvVar{{Key: "GREETING", Value: "hello world"}}, nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	captureOutput(func() {
+		if err := runExport(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(exportOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "GREETING=hello world\n" {
+		t.Errorf("unexpected file content: %q", data)
+	}
+	info, _ := os.Stat(exportOutput)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRunExport_UnknownFormat(t *testing.T) {
+	exportFormat = "xml"
+	defer func() { exportFormat = "dotenv" }()
+
+	deps = &Deps{
+		GetToken: func() (string, error) {
+			t.Fatal("auth should not be checked for an unknown format")
+			return "", nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	err := runExport(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Fatalf("expected unknown format error, got: %v", err)
+	}
+}
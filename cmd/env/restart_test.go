@@ -0,0 +1,85 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func TestRestartAfterChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		noRestart    bool
+		result       api.EnvUpdateResult
+		restartErr   error
+		wantRestarts int
+		wantErr      string
+		wantOutput   string
+	}{
+		{
+			name:         "one restart for a batch",
+			result:       api.EnvUpdateResult{Added: []string{"A"}, Updated: []string{"B", "C"}},
+			wantRestarts: 1,
+			wantOutput:   "Restarted myapp; it is running",
+		},
+		{
+			name:       "no restart without changes",
+			result:     api.EnvUpdateResult{Unchanged: []string{"A"}},
+			wantOutput: "No changes",
+		},
+		{
+			name:       "no-restart",
+			noRestart:  true,
+			result:     api.EnvUpdateResult{Added: []string{"A"}},
+			wantOutput: "Run 'hatch restart myapp'",
+		},
+		{
+			name:         "restart failure keeps the changes",
+			result:       api.EnvUpdateResult{Added: []string{"A"}},
+			restartErr:   fmt.Errorf("myapp is crashed"),
+			wantRestarts: 1,
+			wantErr:      "env vars were applied, but restarting myapp failed: myapp is crashed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appSlug = "myapp"
+			noRestart = tt.noRestart
+			defer func() { appSlug = ""; noRestart = false }()
+
+			restarts := 0
+			deps = &Deps{
+				GetToken: func() (string, error) { return "tok123", nil },
+				SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+					result := tt.result
+					return &result, nil
+				},
+				Restart: func(ctx context.Context, token, slug string) error {
+					restarts++
+					return tt.restartErr
+				},
+			}
+			defer func() { deps = defaultDeps() }()
+
+			var err error
+			output := captureOutput(func() { err = runSet(nil, []string{"A=1", "B=2", "C=3"}) })
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if restarts != tt.wantRestarts {
+				t.Errorf("expected %d restart(s), got %d", tt.wantRestarts, restarts)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("expected %q in output, got: %s", tt.wantOutput, output)
+			}
+		})
+	}
+}
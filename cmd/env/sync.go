@@ -0,0 +1,251 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pullOutput    string
+	pushYes       bool
+	pushPrune     bool
+	pushDryRun    bool
+	pushEncrypted bool
+)
+
+func newPullCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Write the egg's environment variables to a local .env file",
+		Long: `Write the egg's environment variables to a local dotenv file.
+
+The file is created with 0600 permissions and overwritten if it exists.
+Use -o - to print to stdout instead.`,
+		Args: cobra.NoArgs,
+		RunE: runPull,
+	}
+	cmd.Flags().StringVarP(&pullOutput, "output", "o", ".env", "file to write (- for stdout)")
+	return cmd
+}
+
+func newPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push FILE",
+		Short: "Apply a local .env file to the egg",
+		Long: `Compare a local dotenv file with the egg's environment variables and apply
+the difference.
+
+Keys only in the file are added and keys with different values are changed.
+Keys that exist only on the egg are kept unless --prune is set. The diff is
+printed with secret values masked and applied after confirmation.
+
+With --encrypted, FILE is a file written by 'hatch env encrypt'. It is
+decrypted locally with ~/.hatch/env.key; plaintext never touches disk.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPush,
+	}
+	cmd.Flags().BoolVarP(&pushYes, "yes", "y", false, "apply without asking for confirmation")
+	cmd.Flags().BoolVar(&pushPrune, "prune", false, "delete egg keys that are missing from the file")
+	cmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "print the diff without applying it")
+	cmd.Flags().BoolVar(&pushEncrypted, "encrypted", false, "FILE was written by 'hatch env encrypt'; decrypt it with ~/.hatch/env.key")
+	addRestartFlags(cmd)
+	return cmd
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	slug, err := resolveSlug(ctx)
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Fetching environment variables...")
+	sp.Start()
+	vars, err := deps.GetEnvVars(ctx, token, slug)
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("fetching env vars: %w", err)
+	}
+
+	content := formatEnvFile(vars)
+	if pullOutput == "-" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := os.WriteFile(pullOutput, []byte(content), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", pullOutput, err)
+	}
+	ui.Success(fmt.Sprintf("Wrote %d variables from %s to %s", len(vars), slug, pullOutput))
+	return nil
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	readFile := parseEnvFile
+	if pushEncrypted {
+		readFile = decryptEnvFile
+	}
+	local, err := readFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	slug, err := resolveSlug(ctx)
+	if err != nil {
+		return err
+	}
+
+	sp := ui.NewSpinner("Fetching environment variables...")
+	sp.Start()
+	remote, err := deps.GetEnvVars(ctx, token, slug)
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("fetching env vars: %w", err)
+	}
+
+	changes, kept := diffEnv(remote, local, pushPrune)
+	if len(changes) == 0 {
+		ui.Info(fmt.Sprintf("%s is already up to date with %s.", slug, args[0]))
+		return nil
+	}
+
+	printChanges(changes)
+	if kept > 0 {
+		fmt.Println(ui.Dim(fmt.Sprintf("  %d key(s) only on the egg were kept (use --prune to delete them)", kept)))
+	}
+	fmt.Println()
+
+	if pushDryRun {
+		ui.Info("Dry run: no changes applied.")
+		return nil
+	}
+
+	if !pushYes {
+		answer, err := deps.ReadInput(fmt.Sprintf("Apply %d change(s) to %s? [y/N] ", len(changes), slug))
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			ui.Info("Cancelled. No changes applied.")
+			return nil
+		}
+	}
+
+	set := map[string]string{}
+	var unset []string
+	for _, c := range changes {
+		if c.Op == opRemove {
+			unset = append(unset, c.Key)
+		} else {
+			set[c.Key] = c.NewValue
+		}
+	}
+
+	result, err := deps.SetEnvVars(ctx, token, slug, set, unset)
+	if err != nil {
+		return fmt.Errorf("applying changes (nothing was changed): %w", err)
+	}
+	printEnvUpdateResult(slug, result)
+	return restartIfChanged(ctx, token, slug, result)
+}
+
+const (
+	opAdd    = "add"
+	opChange = "change"
+	opRemove = "remove"
+)
+
+// envChange is one difference between the egg's env vars and a local set.
+type envChange struct {
+	Op       string
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// diffEnv computes the changes needed to make remote match local, sorted by
+// key. Remote-only keys become removals when prune is set; otherwise they
+// are left alone and counted in kept.
+func diffEnv(remote, local []api.EnvVar, prune bool) (changes []envChange, kept int) {
+	remoteMap := make(map[string]string, len(remote))
+	for _, v := range remote {
+		remoteMap[v.Key] = v.Value
+	}
+	localMap := make(map[string]string, len(local))
+	for _, v := range local {
+		localMap[v.Key] = v.Value
+	}
+
+	for key, value := range localMap {
+		old, ok := remoteMap[key]
+		switch {
+		case !ok:
+			changes = append(changes, envChange{Op: opAdd, Key: key, NewValue: value})
+		case old != value:
+			changes = append(changes, envChange{Op: opChange, Key: key, OldValue: old, NewValue: value})
+		}
+	}
+	for key, value := range remoteMap {
+		if _, ok := localMap[key]; ok {
+			continue
+		}
+		if prune {
+			changes = append(changes, envChange{Op: opRemove, Key: key, OldValue: value})
+		} else {
+			kept++
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, kept
+}
+
+// printChanges prints a diff with sensitive values masked.
+func printChanges(changes []envChange) {
+	for _, c := range changes {
+		switch c.Op {
+		case opAdd:
+			fmt.Println(ui.Green(fmt.Sprintf("  + %s=%s", c.Key, secret.Mask(c.Key, c.NewValue))))
+		case opChange:
+			fmt.Println(ui.Yellow(fmt.Sprintf("  ~ %s: %s → %s", c.Key, secret.Mask(c.Key, c.OldValue), secret.Mask(c.Key, c.NewValue))))
+		case opRemove:
+			fmt.Println(ui.Red(fmt.Sprintf("  - %s", c.Key)))
+		}
+	}
+}
+
+// formatEnvFile renders env vars as a dotenv file, one KEY=VALUE per line.
+func formatEnvFile(vars []api.EnvVar) string {
+	entries := make([]dotenv.Entry, 0, len(vars))
+	for _, v := range vars {
+		entries = append(entries, dotenv.Entry{Key: v.Key, Value: v.Value})
+	}
+	return dotenv.Format(entries)
+}
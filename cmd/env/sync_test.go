@@ -0,0 +1,193 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func TestDiffEnv(t *testing.T) {
+	remote := []api.EnvVar{
+		{Key: "KEEP", Value: "same"},
+		{Key: "CHANGE", Value: "old"},
+		{Key: "REMOTE_ONLY", Value: "x"},
+	}
+	local := []api.EnvVar{
+		{Key: "KEEP", Value: "same"},
+		{Key: "CHANGE", Value: "new"},
+		{Key: "ADD", Value: "1"},
+	}
+
+	changes, kept := diffEnv(remote, local, false)
+	if kept != 1 {
+		t.Errorf("expected 1 kept key, got %d", kept)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Op != opAdd || changes[0].Key != "ADD" {
+		t.Errorf("unexpected first change: %+v", changes[0])
+	}
+	if changes[1].Op != opChange || changes[1].OldValue != "old" || changes[1].NewValue != "new" {
+		t.Errorf("unexpected second change: %+v", changes[1])
+	}
+
+	changes, kept = diffEnv(remote, local, true)
+	if kept != 0 || len(changes) != 3 {
+		t.Fatalf("expected 3 changes with prune, got %+v (kept %d)", changes, kept)
+	}
+	if changes[2].Op != opRemove || changes[2].Key != "REMOTE_ONLY" {
+		t.Errorf("expected REMOTE_ONLY removal, got %+v", changes[2])
+	}
+}
+
+func TestRunPull_WritesFile(t *testing.T) {
+	appSlug = "myapp"
+	out := filepath.Join(t.TempDir(), ".env.production")
+	pullOutput = out
+	defer func() { appSlug = ""; pullOutput = ".env" }()
+
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return []api.EnvVar{{Key: "PORT", Value: "8080"}, {Key: "GREETING", Value: "hello world"}}, nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	captureOutput(func() {
+		if err := runPull(nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "PORT=8080\nGREETING='hello world'\n" {
+		t.Errorf("unexpected file contents: %q", data)
+	}
+	info, _ := os.Stat(out)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 permissions, got %o", info.Mode().Perm())
+	}
+}
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPush_DryRunMasksAndDoesNotApply(t *testing.T) {
+	appSlug = "myapp"
+	pushDryRun = true
+	defer func() { appSlug = ""; pushDryRun = false }()
+
+	path := writeEnvFile(t, "API_TOKEN=supersecretvalue\nPORT=8080\n")
+	applied := false
+	deps = &Deps{
+		GetToken:   func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return nil, nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			applied = true
+			return &api.EnvUpdateResult{}, nil
+		},
+		Restart: func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	output := captureOutput(func() {
+		if err := runPush(nil, []string{path}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if applied {
+		t.Fatal("dry run must not apply changes")
+	}
+	if contains(output, "supersecretvalue") {
+		t.Errorf("secret value leaked in diff: %s", output)
+	}
+	if !contains(output, "+ API_TOKEN=****alue") || !contains(output, "+ PORT=8080") {
+		t.Errorf("expected masked additions in diff, got: %s", output)
+	}
+}
+
+func TestRunPush_ConfirmedWithPrune(t *testing.T) {
+	appSlug = "myapp"
+	pushPrune = true
+	defer func() { appSlug = ""; pushPrune = false }()
+
+	path := writeEnvFile(t, "PORT=9090\n")
+	var set map[string]string
+	var unset []string
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return []api.EnvVar{{Key: "PORT", Value: "8080"}, {Key: "OLD", Value: "x"}}, nil
+		},
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, keys []string) (*api.EnvUpdateResult, error) {
+			set, unset = vars, keys
+			return &api.EnvUpdateResult{Updated: []string{"PORT"}, Removed: []string{"OLD"}}, nil
+		},
+		ReadInput: func(prompt string) (string, error) { return "y\n", nil },
+		Restart:   func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	output := captureOutput(func() {
+		if err := runPush(nil, []string{path}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if len(set) != 1 || set["PORT"] != "9090" {
+		t.Errorf("expected PORT=9090 to be set, got %v", set)
+	}
+	if len(unset) != 1 || unset[0] != "OLD" {
+		t.Errorf("expected OLD to be removed, got %v", unset)
+	}
+	if !contains(output, "Updated PORT") || !contains(output, "Removed OLD") {
+		t.Errorf("expected changed keys in output, got: %s", output)
+	}
+}
+
+func TestRunPush_Declined(t *testing.T) {
+	appSlug = "myapp"
+	defer func() { appSlug = "" }()
+
+	path := writeEnvFile(t, "PORT=9090\n")
+	applied := false
+	deps = &Deps{
+		GetToken:   func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return nil, nil },
+		SetEnvVars: func(ctx context.Context, token, slug string, vars map[string]string, unset []string) (*api.EnvUpdateResult, error) {
+			applied = true
+			return &api.EnvUpdateResult{}, nil
+		},
+		ReadInput: func(prompt string) (string, error) { return "n\n", nil },
+		Restart:   func(ctx context.Context, token, slug string) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	output := captureOutput(func() {
+		if err := runPush(nil, []string{path}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if applied {
+		t.Fatal("changes must not be applied when declined")
+	}
+	if !contains(output, "Cancelled") {
+		t.Errorf("expected cancel message, got: %s", output)
+	}
+}
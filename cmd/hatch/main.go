@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/cmd/root"
+	"github.com/EscapeVelocityOperations/hatch-cli/cmd/run"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/telemetry"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/update"
 	"golang.org/x/term"
@@ -28,6 +31,17 @@ func main() {
 
 	err := root.Execute()
 
+	// A command started by `hatch run` failed: exit with its code, quietly
+	var exitErr *run.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+
+	// Interrupted with Ctrl-C: exit the way a killed process would, quietly
+	if errors.Is(err, context.Canceled) {
+		os.Exit(130)
+	}
+
 	if err != nil {
 		// Send telemetry for CLI errors
 		telemetry.Send(
@@ -40,7 +54,10 @@ func main() {
 		time.Sleep(50 * time.Millisecond)
 
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if hint := root.ErrorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		os.Exit(root.ExitCode(err))
 	}
 
 	// Show update notification (suppress in MCP mode, non-interactive, or dev builds)
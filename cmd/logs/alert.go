@@ -0,0 +1,109 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logalert"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+)
+
+var (
+	alertOn        []string
+	alertExec      string
+	alertWebhook   string
+	alertThreshold = logalert.DefaultThreshold
+	alertWindow    = logalert.DefaultWindow
+	alertCooldown  = logalert.DefaultCooldown
+	alertsFromToml bool
+)
+
+// alerter evaluates the alert rules while following, or is nil when there
+// are none. alertRuns tracks the actions still running.
+var (
+	alerter   *logalert.Alerter
+	alertRuns sync.WaitGroup
+)
+
+// loadAlertRules returns the rules from the --alert-on flags followed by the
+// [[logs.alerts]] rules in .hatch.toml. The rules in .hatch.toml run shell
+// commands and post log lines wherever the file says, so they only apply
+// with --alerts: a cloned repository must not get to run them on a plain
+// `hatch logs`.
+func loadAlertRules(history bool) ([]*logalert.Rule, error) {
+	if len(alertOn) == 0 && (alertExec != "" || alertWebhook != "") {
+		return nil, fmt.Errorf("--exec and --webhook need --alert-on")
+	}
+	if (len(alertOn) > 0 || alertsFromToml) && (!follow || history) {
+		return nil, fmt.Errorf("--alert-on and --alerts only work when following (they cannot be combined with --follow=false, --until, --output or --deployment)")
+	}
+	if !follow || history {
+		return nil, nil
+	}
+
+	var rules []*logalert.Rule
+	for _, pattern := range alertOn {
+		r := &logalert.Rule{
+			Pattern:   pattern,
+			Threshold: alertThreshold,
+			Window:    alertWindow,
+			Cooldown:  alertCooldown,
+			Exec:      alertExec,
+			Webhook:   alertWebhook,
+		}
+		if err := r.Compile(); err != nil {
+			return nil, fmt.Errorf("--alert-on: %w (set --exec or --webhook)", err)
+		}
+		rules = append(rules, r)
+	}
+	if !alertsFromToml {
+		if fromToml, err := logalert.Load("."); err == nil && len(fromToml) > 0 {
+			fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("Ignoring %d alert rule(s) in .hatch.toml; pass --alerts to run them.", len(fromToml))))
+		}
+		return rules, nil
+	}
+	fromToml, err := logalert.Load(".")
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, fromToml...), nil
+}
+
+// alertNames lists rules by name for the startup message.
+func alertNames(rules []*logalert.Rule) string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = fmt.Sprintf("%q", r.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// watchAlerts wraps handler so that every line from slug is checked against
+// the alert rules before it is handled. Actions stop when ctx is done.
+func watchAlerts(ctx context.Context, slug string, handler func(string)) func(string) {
+	a := alerter
+	if a == nil {
+		return handler
+	}
+	return func(line string) {
+		for _, f := range a.Observe(slug, line) {
+			fireAlert(ctx, f)
+		}
+		handler(line)
+	}
+}
+
+// fireAlert reports a firing and runs its actions in the background.
+func fireAlert(ctx context.Context, f logalert.Firing) {
+	fmt.Fprintln(os.Stderr, ui.Yellow(fmt.Sprintf("! alert %q fired: %d matching line(s) within %s", f.Rule.Name, f.Count, f.Rule.Window)))
+	alertRuns.Add(1)
+	go func() {
+		defer alertRuns.Done()
+		if err := deps.RunAlert(ctx, f); err != nil {
+			fmt.Fprintln(os.Stderr, ui.Red(fmt.Sprintf("✗ alert %q: %v", f.Rule.Name, err)))
+		}
+	}()
+}
@@ -0,0 +1,51 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/buildlog"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+)
+
+// runDeploymentBuild prints the build log of the --deployment deployment and,
+// if it failed, the block of lines that explains why.
+func runDeploymentBuild(ctx context.Context, token, slug string) error {
+	d, err := deps.GetDeployment(ctx, token, slug, deploymentID)
+	if err != nil {
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+	lines, err := deps.GetBuildLogs(ctx, token, slug, d.ID)
+	if err != nil {
+		return fmt.Errorf("getting build logs: %w", err)
+	}
+
+	ui.Info(fmt.Sprintf("Build logs for %s deployment %s (%s, %s)", ui.Bold(slug), ui.Bold(d.ID), d.Status, d.CreatedAt.Local().Format("2006-01-02 15:04")))
+	fmt.Println()
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if len(lines) == 0 {
+		ui.Info("No build logs found.")
+	}
+	if !d.Failed() {
+		return nil
+	}
+
+	fmt.Println()
+	s := buildlog.Summarize(lines)
+	if s == nil {
+		ui.Error("Build failed. No error block was found in the log.")
+		return nil
+	}
+	ui.Error(fmt.Sprintf("Build failed: %s (line %d)", s.Title, s.Line))
+	fmt.Println()
+	for _, line := range s.Excerpt {
+		fmt.Println("    " + ui.Bold(line))
+	}
+	if s.Hint != "" {
+		fmt.Println()
+		ui.Info(s.Hint)
+	}
+	return nil
+}
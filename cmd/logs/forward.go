@@ -0,0 +1,198 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logforward"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logstream"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forwardTo            []string
+	healthAddr           = "127.0.0.1:8089"
+	forwardBatchSize     = 500
+	forwardFlushInterval = time.Second
+	forwardAll           bool
+	forwardBuild         bool
+	forwardSince         string
+)
+
+func newForwardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forward [slug...]",
+		Short: "Forward egg logs to OTLP, Loki or syslog",
+		Long: `Stream logs from one or more eggs and ship them to your own log backends
+until interrupted with Ctrl-C.
+
+Each line becomes a record with the egg, the stream (runtime or build) and,
+for structured lines, the level and fields as attributes. Records are sent
+in batches; a failed batch is retried with backoff and dropped after five
+attempts. Dropped connections to the eggs are reconnected. On Ctrl-C,
+queued records are sent for up to two seconds before exiting.
+
+Destinations:
+  otlp://host:4318      OpenTelemetry collector, OTLP over HTTP with JSON (otlps:// for HTTPS)
+  loki://host:3100      Grafana Loki push API (lokis:// for HTTPS)
+  syslog://host:514     RFC 5424 syslog over UDP (syslog+tcp:// for TCP)
+
+A health endpoint on --health-addr reports every stream and destination as
+JSON, with status 503 while one of them is failing.
+
+Examples:
+  hatch logs forward --to otlp://localhost:4318
+  hatch logs forward web api --to loki://localhost:3100 --to syslog://localhost:514
+  hatch logs forward --all --to loki://loki.internal:3100 --health-addr :9100`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runForward,
+	}
+	cmd.Flags().StringArrayVar(&forwardTo, "to", nil, "destination URL (repeatable)")
+	cmd.Flags().BoolVar(&forwardAll, "all", false, "forward logs of all your eggs")
+	cmd.Flags().BoolVar(&forwardBuild, "build", false, "forward build logs instead of runtime logs")
+	cmd.Flags().StringVar(&forwardSince, "since", "", "start with lines newer than a duration (15m, 2h, 1d) or timestamp instead of new lines only")
+	cmd.Flags().StringVar(&healthAddr, "health-addr", "127.0.0.1:8089", `address for the health endpoint ("" to disable)`)
+	cmd.Flags().IntVar(&forwardBatchSize, "batch-size", 500, "most records sent per request")
+	cmd.Flags().DurationVar(&forwardFlushInterval, "flush-interval", time.Second, "longest a record waits for its batch to fill")
+	return cmd
+}
+
+func runForward(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	if len(forwardTo) == 0 {
+		return fmt.Errorf("--to is required (schemes: %s)", strings.Join(logforward.Schemes(), ", "))
+	}
+	sinks := map[string]logforward.Sink{}
+	for _, dest := range forwardTo {
+		s, err := logforward.NewSink(dest)
+		if err != nil {
+			return err
+		}
+		sinks[dest] = s
+	}
+	since := time.Now()
+	if forwardSince != "" {
+		t, err := logparse.ParseTime(forwardSince, since)
+		if err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		since = t
+	}
+
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+	slugs, err := resolveSlugs(ctx, token, args, forwardAll)
+	if err != nil {
+		return err
+	}
+	return forwardLogs(ctx, token, slugs, sinks, since)
+}
+
+// forwardLogs streams every egg into the sinks until ctx is done or all
+// streams have failed, then sends what is still queued and prints a summary.
+func forwardLogs(ctx context.Context, token string, slugs []string, sinks map[string]logforward.Sink, since time.Time) error {
+	fwd := logforward.NewForwarder(sinks, logforward.Options{
+		BatchSize:     forwardBatchSize,
+		FlushInterval: forwardFlushInterval,
+	})
+	health := logforward.NewHealth(fwd)
+	if healthAddr != "" {
+		ln, err := net.Listen("tcp", healthAddr)
+		if err != nil {
+			fwd.Close(ctx)
+			return fmt.Errorf("starting health endpoint: %w", err)
+		}
+		srv := &http.Server{Handler: health}
+		go srv.Serve(ln)
+		defer srv.Close()
+		ui.Info(fmt.Sprintf("Health endpoint: http://%s/", ln.Addr()))
+	}
+
+	stream, logType := "runtime", ""
+	if forwardBuild {
+		stream, logType = "build", "build"
+	}
+	names := make([]string, len(slugs))
+	for i, slug := range slugs {
+		names[i] = ui.Bold(slug)
+	}
+	ui.Info(fmt.Sprintf("Forwarding %s logs for %s to %s...", stream, strings.Join(names, ", "), strings.Join(forwardTo, ", ")))
+
+	endCh := make(chan error, len(slugs))
+	for _, slug := range slugs {
+		go func() {
+			f := &logstream.Follower{
+				Stream: func(ctx context.Context, from time.Time, handler func(string)) error {
+					if from.Before(since) {
+						from = since
+					}
+					health.Connected(slug)
+					return deps.StreamLogs(ctx, token, slug, from, 0, true, logType, handler)
+				},
+				MaxReconnects: -1,
+				OnReconnect: func(attempt int, delay time.Duration, err error) {
+					health.Disconnected(slug, err)
+					fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("%s: reconnecting in %s… (attempt %d)", slug, delay.Round(100*time.Millisecond), attempt)))
+				},
+				Sleep: deps.Sleep,
+			}
+			err := f.Run(ctx, func(line string) {
+				health.Line(slug)
+				fwd.Add(logforward.NewRecord(slug, stream, line, time.Now()))
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			health.Disconnected(slug, err)
+			endCh <- fmt.Errorf("%s: %w", slug, err)
+		}()
+	}
+
+	failed := 0
+	for stopped := false; !stopped && failed < len(slugs); {
+		select {
+		case err := <-endCh:
+			failed++
+			ui.Error(fmt.Sprintf("%v (%d of %d still streaming)", err, len(slugs)-failed, len(slugs)))
+		case <-ctx.Done():
+			fmt.Println()
+			ui.Info("Shutting down, sending queued logs...")
+			stopped = true
+		}
+	}
+
+	// ctx is usually done by now, so give the queued logs their own
+	// deadline, short enough to finish before interrupt's grace period
+	// ends and a second Ctrl-C is not needed.
+	shutdown, cancel := context.WithTimeout(context.Background(), interrupt.Grace*2/3)
+	defer cancel()
+	fwd.Close(shutdown)
+	for _, s := range fwd.Stats() {
+		msg := fmt.Sprintf("%s: %d sent, %d dropped", s.Destination, s.Sent, s.Dropped)
+		if s.LastError != "" && !s.Healthy {
+			msg += " (last error: " + s.LastError + ")"
+		}
+		if s.Dropped > 0 {
+			ui.Warn(msg)
+		} else {
+			ui.Success(msg)
+		}
+	}
+	if failed == len(slugs) {
+		return fmt.Errorf("all %d log streams failed", len(slugs))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+)
+
+// runHistory queries stored logs between --since and --until and prints
+// them, or writes them as NDJSON with --output.
+func runHistory(ctx context.Context, token, slug, logType string, filter *logparse.Filter) error {
+	var out io.Writer = os.Stdout
+	var file *bufio.Writer
+	if outputPath != "" && outputPath != "-" {
+		f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", outputPath, err)
+		}
+		defer f.Close()
+		file = bufio.NewWriter(f)
+		out = file
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	written := 0
+	q := api.LogQuery{Since: filter.Since, Until: filter.Until, Type: logType}
+	err := deps.WalkLogs(ctx, token, slug, q, func(le api.LogEntry) error {
+		e := logparse.Parse(le.Message)
+		if e.Time.IsZero() {
+			e.Time = le.Timestamp
+		}
+		if !raw && !filter.Match(e) {
+			return nil
+		}
+		written++
+		switch {
+		case outputPath != "":
+			return enc.Encode(le)
+		case raw:
+			_, err := fmt.Fprintln(out, le.Timestamp.Format(time.RFC3339Nano), le.Message)
+			return err
+		default:
+			if e.Format == "" {
+				e.Format = logparse.FormatText
+				e.Message = e.Raw
+			}
+			_, err := fmt.Fprintln(out, renderEntry(e))
+			return err
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("querying logs: %w", err)
+	}
+
+	if file != nil {
+		if err := file.Flush(); err != nil {
+			return fmt.Errorf("writing %s: %w", outputPath, err)
+		}
+		ui.Success(fmt.Sprintf("Wrote %d log lines from %s to %s", written, slug, outputPath))
+	} else if written == 0 && outputPath == "" {
+		ui.Info("No log lines found in that time range.")
+	}
+	return nil
+}
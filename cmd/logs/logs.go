@@ -1,32 +1,71 @@
 package logs
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logalert"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logstream"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	lines  int
-	follow bool
-	build  bool
+	lines         int
+	follow        bool
+	build         bool
+	maxReconnects = -1
+	levelFilter   string
+	grepPattern   string
+	fieldFilters  []string
+	sinceFlag     string
+	raw           bool
+	allEggs       bool
+	untilFlag     string
+	outputPath    string
+	deploymentID  string
 )
 
 // Deps holds injectable dependencies for testing.
 type Deps struct {
 	GetToken   func() (string, error)
-	StreamLogs func(token, slug string, lines int, follow bool, logType string, handler func(string)) error
+	StreamLogs func(ctx context.Context, token, slug string, since time.Time, lines int, follow bool, logType string, handler func(string)) error
+	ListApps   func(ctx context.Context, token string) ([]api.App, error)
+	WalkLogs   func(ctx context.Context, token, slug string, q api.LogQuery, fn func(api.LogEntry) error) error
+	Sleep      func(context.Context, time.Duration) error
+	RunAlert   func(context.Context, logalert.Firing) error
+
+	GetDeployment func(ctx context.Context, token, slug, id string) (*api.Deployment, error)
+	GetBuildLogs  func(ctx context.Context, token, slug, deploymentID string) ([]string, error)
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		StreamLogs: func(token, slug string, lines int, follow bool, logType string, handler func(string)) error {
-			return api.NewClient(token).StreamLogs(slug, lines, follow, logType, handler)
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, lines int, follow bool, logType string, handler func(string)) error {
+			return api.NewClient(token).StreamLogsSince(ctx, slug, since, lines, follow, logType, handler)
+		},
+		ListApps: func(ctx context.Context, token string) ([]api.App, error) {
+			return api.NewClient(token).ListApps(ctx)
+		},
+		WalkLogs: func(ctx context.Context, token, slug string, q api.LogQuery, fn func(api.LogEntry) error) error {
+			return api.NewClient(token).WalkLogs(ctx, slug, q, fn)
+		},
+		Sleep:    logstream.Sleep,
+		RunAlert: logalert.Run,
+		GetDeployment: func(ctx context.Context, token, slug, id string) (*api.Deployment, error) {
+			return api.NewClient(token).GetDeployment(ctx, slug, id)
+		},
+		GetBuildLogs: func(ctx context.Context, token, slug, deploymentID string) ([]string, error) {
+			return api.NewClient(token).GetBuildLogs(ctx, slug, deploymentID)
 		},
 	}
 }
@@ -36,19 +75,89 @@ var deps = defaultDeps()
 // NewCmd returns the logs command.
 func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "logs [slug]",
+		Use:   "logs [slug...]",
 		Short: "View egg logs",
-		Long:  "Stream logs from a Hatch egg. If no slug is provided, the egg is detected from the current git remote.",
-		Args:  cobra.MaximumNArgs(1),
-		RunE:  runLogs,
+		Long: `Stream logs from a Hatch egg. If no slug is provided, the egg is detected from the current git remote.
+
+With --follow, a dropped connection (for example while a sleeping egg wakes
+up) is reconnected with backoff, resuming after the last line shown so no
+line is printed twice. It runs until interrupted with Ctrl-C.
+
+JSON and logfmt lines, and text lines that start with a level such as
+"[INFO]" or "ERROR:", are shown as time, level and message columns with the
+remaining fields after them. Use --raw to print lines exactly as received.
+
+Several eggs can be tailed at once: each line is prefixed with its egg's
+name and lines are merged in timestamp order. Each egg reconnects on its own,
+and a note is printed when one of the streams ends.
+
+With --until or --output, stored logs between --since and --until are
+queried instead of streaming, page by page. --output writes them as NDJSON
+objects with timestamp, stream and message.
+
+While following, --alert-on runs --exec or POSTs to --webhook when its
+regular expression matches --threshold lines within --window, then waits
+--cooldown before firing again. The command gets the matching lines on
+stdin. Every received line is checked, whatever the display filters. More
+rules can be set in [[logs.alerts]] tables in .hatch.toml.
+
+--deployment prints the complete build log of one deployment ("latest" for
+the most recent). If that deployment failed, the block of lines that explains
+why, such as a compiler error, a missing module or running out of memory, is
+shown again after the log.
+
+Examples:
+  hatch logs web api worker
+  hatch logs --all --level error
+  hatch logs --level warn
+  hatch logs --grep 'timeout|refused' --since 15m
+  hatch logs --field route=/api/checkout --field status=500
+  hatch logs --since 2025-06-15T10:00 --until 2025-06-15T11:00 --output incident.ndjson
+  hatch logs --deployment latest
+  hatch logs --alert-on 'panic:' --exec 'notify-send "panic in web"'
+  hatch logs --alert-on ECONNREFUSED --threshold 5 --window 1m --webhook https://hooks.example.com/x`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runLogs,
 	}
 	cmd.Flags().IntVarP(&lines, "lines", "n", 100, "number of recent log lines to show")
 	cmd.Flags().BoolVarP(&follow, "follow", "f", true, "follow log output (live tail)")
 	cmd.Flags().BoolVar(&build, "build", false, "show build logs instead of runtime logs")
+	cmd.Flags().IntVar(&maxReconnects, "max-reconnects", -1, "give up after this many reconnect attempts in a row without new lines (-1 for no limit)")
+	cmd.Flags().StringVar(&levelFilter, "level", "", "only show lines at or above this level ("+logparse.LevelNames+")")
+	cmd.Flags().StringVar(&grepPattern, "grep", "", "only show lines matching this regular expression")
+	cmd.Flags().StringArrayVar(&fieldFilters, "field", nil, "only show structured lines with this key=value field (repeatable)")
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "only show lines newer than a duration (15m, 2h, 1d) or timestamp")
+	cmd.Flags().BoolVar(&raw, "raw", false, "print lines exactly as received, without parsing or filtering")
+	cmd.Flags().BoolVar(&allEggs, "all", false, "show logs of all your eggs")
+	cmd.Flags().StringVar(&untilFlag, "until", "", "query stored logs older than a duration or timestamp instead of streaming")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "query stored logs and write them as NDJSON to this file (- for stdout)")
+	cmd.Flags().StringVar(&deploymentID, "deployment", "", `show the build logs of this deployment ID ("latest" for the most recent)`)
+	cmd.Flags().StringArrayVar(&alertOn, "alert-on", nil, "run the alert action when this regular expression matches (repeatable)")
+	cmd.Flags().StringVar(&alertExec, "exec", "", "shell command to run when an alert fires, with the matching lines on stdin")
+	cmd.Flags().StringVar(&alertWebhook, "webhook", "", "URL to POST a JSON description of the alert to when it fires")
+	cmd.Flags().IntVar(&alertThreshold, "threshold", logalert.DefaultThreshold, "matching lines within --window needed to fire an alert")
+	cmd.Flags().DurationVar(&alertWindow, "window", logalert.DefaultWindow, "time window for --threshold")
+	cmd.Flags().DurationVar(&alertCooldown, "cooldown", logalert.DefaultCooldown, "least time between two firings of an alert")
+	cmd.Flags().BoolVar(&alertsFromToml, "alerts", false, "also run the [[logs.alerts]] rules in .hatch.toml, which can run commands and post to webhooks")
+	cmd.AddCommand(newForwardCmd())
 	return cmd
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	filter, err := buildFilter()
+	if err != nil {
+		return err
+	}
+	history := untilFlag != "" || outputPath != ""
+	if deploymentID != "" && history {
+		return fmt.Errorf("--deployment cannot be combined with --until or --output")
+	}
+	rules, err := loadAlertRules(history || deploymentID != "")
+	if err != nil {
+		return err
+	}
+
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -57,7 +166,7 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
 	}
 
-	slug, err := resolveSlug(args)
+	slugs, err := resolveSlugs(ctx, token, args, allEggs)
 	if err != nil {
 		return err
 	}
@@ -65,23 +174,146 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	logType := ""
 	if build {
 		logType = "build"
-		ui.Info(fmt.Sprintf("Streaming build logs for %s...", ui.Bold(slug)))
+	}
+	if deploymentID != "" {
+		if len(slugs) > 1 {
+			return fmt.Errorf("--deployment works with a single egg")
+		}
+		return runDeploymentBuild(ctx, token, slugs[0])
+	}
+	if history {
+		if len(slugs) > 1 {
+			return fmt.Errorf("--until and --output work with a single egg")
+		}
+		return runHistory(ctx, token, slugs[0], logType, filter)
+	}
+
+	names := make([]string, len(slugs))
+	for i, slug := range slugs {
+		names[i] = ui.Bold(slug)
+	}
+	if build {
+		ui.Info(fmt.Sprintf("Streaming build logs for %s...", strings.Join(names, ", ")))
 	} else {
-		ui.Info(fmt.Sprintf("Streaming logs for %s...", ui.Bold(slug)))
+		ui.Info(fmt.Sprintf("Streaming logs for %s...", strings.Join(names, ", ")))
+	}
+	if len(rules) > 0 {
+		ui.Info(fmt.Sprintf("Watching %d alert rule(s): %s", len(rules), alertNames(rules)))
+		alerter = logalert.New(rules)
+		defer func() {
+			alerter = nil
+			alertRuns.Wait()
+		}()
 	}
 	fmt.Println()
 
-	return deps.StreamLogs(token, slug, lines, follow, logType, func(line string) {
-		fmt.Println(line)
+	if len(slugs) > 1 {
+		return tailMany(ctx, token, slugs, logType, filter)
+	}
+	return streamEgg(ctx, token, slugs[0], logType, filter.Since, "", func(line string) {
+		if text, _, ok := formatLine(line, filter); ok {
+			fmt.Println(text)
+		}
 	})
 }
 
-func resolveSlug(args []string) (string, error) {
+// streamEgg streams one egg's logs to handler, reconnecting when following.
+// reconnectPrefix is put in front of the reconnect notice.
+func streamEgg(ctx context.Context, token, slug, logType string, minSince time.Time, reconnectPrefix string, handler func(string)) error {
+	if !follow {
+		return deps.StreamLogs(ctx, token, slug, minSince, lines, false, logType, handler)
+	}
+
+	f := &logstream.Follower{
+		Stream: func(ctx context.Context, since time.Time, handler func(string)) error {
+			if since.Before(minSince) {
+				since = minSince
+			}
+			return deps.StreamLogs(ctx, token, slug, since, lines, true, logType, handler)
+		},
+		MaxReconnects: maxReconnects,
+		OnReconnect: func(attempt int, delay time.Duration, err error) {
+			fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("%sreconnecting in %s… (attempt %d)", reconnectPrefix, delay.Round(100*time.Millisecond), attempt)))
+		},
+		Sleep: deps.Sleep,
+	}
+	return f.Run(ctx, watchAlerts(ctx, slug, handler))
+}
+
+// formatLine renders line for display and reports whether it passes the
+// filter, along with the line's timestamp if it has one. With --raw every
+// line is passed through unchanged.
+func formatLine(line string, filter *logparse.Filter) (string, time.Time, bool) {
+	if raw {
+		at, _ := logstream.Timestamp(line)
+		return line, at, true
+	}
+	e := logparse.Parse(line)
+	if !filter.Match(e) {
+		return "", time.Time{}, false
+	}
+	return renderEntry(e), e.Time, true
+}
+
+// buildFilter turns the filter flags into a logparse.Filter.
+func buildFilter() (*logparse.Filter, error) {
+	if raw && (levelFilter != "" || grepPattern != "" || len(fieldFilters) > 0) {
+		return nil, fmt.Errorf("--raw cannot be combined with --level, --grep or --field")
+	}
+	filter, err := logparse.NewFilter(levelFilter, grepPattern, fieldFilters)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if sinceFlag != "" {
+		if filter.Since, err = logparse.ParseTime(sinceFlag, now); err != nil {
+			return nil, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if untilFlag != "" {
+		if filter.Until, err = logparse.ParseTime(untilFlag, now); err != nil {
+			return nil, fmt.Errorf("--until: %w", err)
+		}
+		if !filter.Since.IsZero() && !filter.Until.After(filter.Since) {
+			return nil, fmt.Errorf("--until must be later than --since")
+		}
+	}
+	return filter, nil
+}
+
+// resolveSlugs returns the eggs to show logs for: every egg when all is
+// set (--all), the given slugs, or the egg from .hatch.toml.
+func resolveSlugs(ctx context.Context, token string, args []string, all bool) ([]string, error) {
+	if all {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--all cannot be combined with egg names")
+		}
+		apps, err := deps.ListApps(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("listing eggs: %w", err)
+		}
+		if len(apps) == 0 {
+			return nil, fmt.Errorf("no eggs found. Deploy one with 'hatch deploy'")
+		}
+		slugs := make([]string, len(apps))
+		for i, app := range apps {
+			slugs[i] = app.Slug
+		}
+		return slugs, nil
+	}
 	if len(args) > 0 {
-		return args[0], nil
+		var slugs []string
+		seen := map[string]bool{}
+		for _, slug := range args {
+			if !seen[slug] {
+				seen[slug] = true
+				slugs = append(slugs, slug)
+			}
+		}
+		return slugs, nil
 	}
 	if slug := resolve.SlugFromToml(); slug != "" {
-		return slug, nil
+		return []string{slug}, nil
 	}
-	return "", fmt.Errorf("no egg specified. Usage: hatch logs <slug> (or set slug in .hatch.toml)")
+	return nil, fmt.Errorf("no egg specified. Usage: hatch logs <slug> (or set slug in .hatch.toml)")
 }
@@ -2,10 +2,24 @@ package logs
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logalert"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logforward"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
 )
 
 func captureOutput(fn func()) string {
@@ -39,7 +53,7 @@ func TestRunLogs_WithSlugArg(t *testing.T) {
 	streamedSlug := ""
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		StreamLogs: func(token, slug string, linesN int, followN bool, logType string, handler func(string)) error {
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
 			streamedSlug = slug
 			handler("log line 1")
 			return nil
@@ -80,7 +94,7 @@ func TestRunLogs_NoArg(t *testing.T) {
 func TestRunLogs_StreamError(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		StreamLogs: func(token, slug string, linesN int, followN bool, logType string, handler func(string)) error {
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
 			return fmt.Errorf("connection reset")
 		},
 	}
@@ -101,7 +115,7 @@ func TestRunLogs_BuildFlag(t *testing.T) {
 	var capturedLogType string
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		StreamLogs: func(token, slug string, linesN int, followN bool, logType string, handler func(string)) error {
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
 			capturedLogType = logType
 			handler("build output")
 			return nil
@@ -131,7 +145,7 @@ func TestRunLogs_LinesFlag(t *testing.T) {
 	var capturedLines int
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
-		StreamLogs: func(token, slug string, linesN int, followN bool, logType string, handler func(string)) error {
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
 			capturedLines = linesN
 			return nil
 		},
@@ -153,6 +167,396 @@ func TestRunLogs_LinesFlag(t *testing.T) {
 	}
 }
 
+func TestRunLogs_FollowReconnects(t *testing.T) {
+	var sinces []time.Time
+	conns := [][]string{
+		{"2025-06-15T10:00:01Z one", "2025-06-15T10:00:02Z two"},
+		{"2025-06-15T10:00:02Z two", "2025-06-15T10:00:03Z three"},
+	}
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			sinces = append(sinces, since)
+			if len(sinces) > len(conns) {
+				return fmt.Errorf("API error 404: app not found")
+			}
+			for _, line := range conns[len(sinces)-1] {
+				handler(line)
+			}
+			return fmt.Errorf("%w: connection reset", api.ErrLogStreamInterrupted)
+		},
+		Sleep: func(context.Context, time.Duration) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	follow = true
+	defer func() { follow = false }()
+
+	var err error
+	output := captureOutput(func() {
+		err = runLogs(nil, []string{"myapp"})
+	})
+
+	if err == nil || !contains(err.Error(), "app not found") {
+		t.Fatalf("expected the non-retryable error to end the stream, got: %v", err)
+	}
+	if n := strings.Count(output, "two"); n != 1 {
+		t.Errorf("expected the replayed line once, got %d times in: %s", n, output)
+	}
+	if !contains(output, "three") {
+		t.Errorf("expected lines after reconnecting, got: %s", output)
+	}
+	if len(sinces) != 3 || !sinces[0].IsZero() || sinces[1].IsZero() {
+		t.Errorf("expected reconnects to resume from the last timestamp, got: %v", sinces)
+	}
+}
+
+func TestRunLogs_RendersAndFilters(t *testing.T) {
+	received := []string{
+		`{"time":"2025-06-15T10:00:00Z","level":"info","msg":"started","port":8080}`,
+		`{"time":"2025-06-15T10:00:01Z","level":"error","msg":"checkout failed","route":"/api/checkout"}`,
+		`level=warn msg="slow request" route=/api/cart ms=900`,
+		`plain stdout line`,
+	}
+	tests := []struct {
+		name    string
+		setup   func()
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "renders columns",
+			setup:   func() {},
+			want:    []string{"INFO  started port=8080", "ERROR checkout failed route=/api/checkout", "WARN  slow request route=/api/cart ms=900", "plain stdout line"},
+			notWant: []string{`"msg"`},
+		},
+		{
+			name:    "level",
+			setup:   func() { levelFilter = "warn" },
+			want:    []string{"checkout failed", "slow request"},
+			notWant: []string{"started", "plain stdout line"},
+		},
+		{
+			name:    "grep and field",
+			setup:   func() { grepPattern = "fail|slow"; fieldFilters = []string{"route=/api/checkout"} },
+			want:    []string{"checkout failed"},
+			notWant: []string{"slow request", "started"},
+		},
+		{
+			name:  "raw",
+			setup: func() { raw = true },
+			want:  received,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps = &Deps{
+				GetToken: func() (string, error) { return "tok123", nil },
+				StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+					for _, line := range received {
+						handler(line)
+					}
+					return nil
+				},
+			}
+			defer func() {
+				deps = defaultDeps()
+				levelFilter, grepPattern, fieldFilters, raw = "", "", nil, false
+			}()
+			tt.setup()
+
+			output := captureOutput(func() {
+				if err := runLogs(nil, []string{"myapp"}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			})
+
+			for _, w := range tt.want {
+				if !contains(output, w) {
+					t.Errorf("expected %q in output:\n%s", w, output)
+				}
+			}
+			for _, w := range tt.notWant {
+				if contains(output, w) {
+					t.Errorf("did not expect %q in output:\n%s", w, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRunLogs_FilterFlagErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func()
+		want  string
+	}{
+		{"unknown level", func() { levelFilter = "loud" }, "unknown level"},
+		{"bad since", func() { sinceFlag = "yesterday" }, "--since"},
+		{"raw with filter", func() { raw = true; grepPattern = "x" }, "--raw cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { levelFilter, grepPattern, sinceFlag, raw = "", "", "", false }()
+			tt.setup()
+
+			err := runLogs(nil, []string{"myapp"})
+			if err == nil || !contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunLogs_SincePassedToStream(t *testing.T) {
+	var gotSince time.Time
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			gotSince = since
+			return nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	sinceFlag = "15m"
+	defer func() { sinceFlag = "" }()
+
+	captureOutput(func() {
+		if err := runLogs(nil, []string{"myapp"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if d := time.Since(gotSince); d < 15*time.Minute || d > 16*time.Minute {
+		t.Errorf("expected since about 15m ago, got %v", gotSince)
+	}
+}
+
+func TestRunLogs_MultipleEggs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		all        bool
+		failing    string
+		wantEggs   []string
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:       "named eggs",
+			args:       []string{"web", "api", "web"},
+			wantEggs:   []string{"api", "web"},
+			wantOutput: []string{"web | hello from web", "api | hello from api", "web: log stream ended after 1 line(s)"},
+		},
+		{
+			name:       "all eggs",
+			all:        true,
+			wantEggs:   []string{"api", "web", "worker"},
+			wantOutput: []string{"worker | hello from worker", "api    | hello from api"},
+		},
+		{
+			name:       "one stream fails",
+			args:       []string{"web", "api"},
+			failing:    "api",
+			wantEggs:   []string{"api", "web"},
+			wantErr:    "1 of 2 log streams failed",
+			wantOutput: []string{"api: log stream ended after 1 line(s): API error 404: app not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var streamed []string
+			deps = &Deps{
+				GetToken: func() (string, error) { return "tok123", nil },
+				StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+					mu.Lock()
+					streamed = append(streamed, slug)
+					mu.Unlock()
+					handler("hello from " + slug)
+					if slug == tt.failing {
+						return fmt.Errorf("API error 404: app not found")
+					}
+					return nil
+				},
+				ListApps: func(ctx context.Context, token string) ([]api.App, error) {
+					return []api.App{{Slug: "web"}, {Slug: "api"}, {Slug: "worker"}}, nil
+				},
+			}
+			defer func() { deps = defaultDeps() }()
+			allEggs = tt.all
+			defer func() { allEggs = false }()
+
+			var err error
+			output := captureOutput(func() {
+				err = runLogs(nil, tt.args)
+			})
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+			}
+			sort.Strings(streamed)
+			if !reflect.DeepEqual(streamed, tt.wantEggs) {
+				t.Errorf("streamed eggs = %v, want %v", streamed, tt.wantEggs)
+			}
+			for _, w := range tt.wantOutput {
+				if !contains(output, w) {
+					t.Errorf("expected %q in output:\n%s", w, output)
+				}
+			}
+		})
+	}
+}
+
+func TestTailMany_WaitsForStreamsOnCancel(t *testing.T) {
+	var mu sync.Mutex
+	stopped := 0
+	started := make(chan struct{}, 2)
+	deps = &Deps{
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			// Stand in for a stream that takes a moment to shut down.
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			stopped++
+			mu.Unlock()
+			return ctx.Err()
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		<-started
+		cancel()
+	}()
+	var err error
+	captureOutput(func() {
+		err = tailMany(ctx, "tok123", []string{"web", "api"}, "", &logparse.Filter{})
+	})
+
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if stopped != 2 {
+		t.Errorf("tailMany returned before its streams stopped (%d of 2 stopped)", stopped)
+	}
+}
+
+func TestRunLogs_AllWithNames(t *testing.T) {
+	deps = &Deps{GetToken: func() (string, error) { return "tok123", nil }}
+	defer func() { deps = defaultDeps() }()
+	allEggs = true
+	defer func() { allEggs = false }()
+
+	err := runLogs(nil, []string{"web"})
+	if err == nil || !contains(err.Error(), "--all cannot be combined") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMergerFlush(t *testing.T) {
+	base := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	m := &merger{}
+	m.add(eggLine{text: "api 2", at: base.Add(2 * time.Second), received: base})
+	m.add(eggLine{text: "web 1", at: base.Add(time.Second), received: base})
+	m.add(eggLine{text: "web 3", at: base.Add(3 * time.Second), received: base.Add(time.Second)})
+
+	var got []string
+	for _, l := range m.flush(base) {
+		got = append(got, l.text)
+	}
+	if want := []string{"web 1", "api 2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first flush = %v, want %v", got, want)
+	}
+	if len(m.pending) != 1 || m.pending[0].text != "web 3" {
+		t.Errorf("expected the later line to be held back, pending = %v", m.pending)
+	}
+}
+
+func TestRunLogs_HistoryToNDJSON(t *testing.T) {
+	ts := time.Date(2025, 6, 15, 10, 5, 0, 0, time.UTC)
+	var gotQuery api.LogQuery
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			t.Error("history queries must not open a stream")
+			return nil
+		},
+		WalkLogs: func(ctx context.Context, token, slug string, q api.LogQuery, fn func(api.LogEntry) error) error {
+			gotQuery = q
+			for _, e := range []api.LogEntry{
+				{Timestamp: ts, Stream: "stdout", Message: `{"level":"info","msg":"ok"}`},
+				{Timestamp: ts, Stream: "stderr", Message: `{"level":"error","msg":"db <timeout>"}`},
+			} {
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	out := filepath.Join(t.TempDir(), "logs.ndjson")
+	sinceFlag, untilFlag, outputPath, levelFilter = "2025-06-15T10:00:00Z", "2025-06-15T11:00:00Z", out, "error"
+	defer func() { sinceFlag, untilFlag, outputPath, levelFilter = "", "", "", "" }()
+
+	captureOutput(func() {
+		if err := runLogs(nil, []string{"myapp"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if !gotQuery.Since.Equal(time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)) || !gotQuery.Until.Equal(time.Date(2025, 6, 15, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected query window: %+v", gotQuery)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"timestamp":"2025-06-15T10:05:00Z","stream":"stderr","message":"{\"level\":\"error\",\"msg\":\"db <timeout>\"}"}` + "\n"
+	if string(data) != want {
+		t.Errorf("NDJSON output:\n%s\nwant:\n%s", data, want)
+	}
+	if info, err := os.Stat(out); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRunLogs_HistoryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		setup func()
+		want  string
+	}{
+		{"until before since", []string{"myapp"}, func() { sinceFlag, untilFlag = "1h", "2h" }, "--until must be later than --since"},
+		{"several eggs", []string{"web", "api"}, func() { untilFlag = "1h" }, "single egg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps = &Deps{GetToken: func() (string, error) { return "tok123", nil }}
+			defer func() { deps = defaultDeps() }()
+			defer func() { sinceFlag, untilFlag = "", "" }()
+			tt.setup()
+
+			err := runLogs(nil, tt.args)
+			if err == nil || !contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -161,3 +565,272 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestForwardLogs(t *testing.T) {
+	var mu sync.Mutex
+	var pushed []string
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushed = append(pushed, string(body))
+		mu.Unlock()
+	}))
+	defer loki.Close()
+
+	var delivered sync.WaitGroup
+	delivered.Add(1)
+	deps = &Deps{
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			handler(`{"level":"error","msg":"boom"}`)
+			handler("plain line")
+			delivered.Done()
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+	forwardTo = []string{"loki://" + strings.TrimPrefix(loki.URL, "http://")}
+	healthAddr = "127.0.0.1:0"
+	defer func() { forwardTo, healthAddr = nil, "127.0.0.1:8089" }()
+
+	sink, err := logforward.NewSink(forwardTo[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		delivered.Wait()
+		cancel()
+	}()
+	output := captureOutput(func() {
+		err = forwardLogs(ctx, "tok123", []string{"web"}, map[string]logforward.Sink{forwardTo[0]: sink}, time.Now())
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !contains(output, ": 2 sent, 0 dropped") {
+		t.Errorf("expected a delivery summary in output:\n%s", output)
+	}
+	all := strings.Join(pushed, "\n")
+	for _, w := range []string{`"egg":"web"`, `"level":"error"`, `"stream":"runtime"`, `"plain line"`} {
+		if !contains(all, w) {
+			t.Errorf("expected %s in pushed logs:\n%s", w, all)
+		}
+	}
+}
+
+func TestForwardLogs_AllStreamsFail(t *testing.T) {
+	deps = &Deps{
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			return fmt.Errorf("API error 404: app not found")
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+	forwardTo = []string{"syslog://127.0.0.1:1"}
+	healthAddr = ""
+	defer func() { forwardTo, healthAddr = nil, "127.0.0.1:8089" }()
+
+	sink, err := logforward.NewSink(forwardTo[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := captureOutput(func() {
+		err = forwardLogs(context.Background(), "tok123", []string{"web", "api"}, map[string]logforward.Sink{forwardTo[0]: sink}, time.Now())
+	})
+	if err == nil || err.Error() != "all 2 log streams failed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !contains(output, "API error 404: app not found (0 of 2 still streaming)") {
+		t.Errorf("expected stream failures in output:\n%s", output)
+	}
+}
+
+func TestRunForward_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      []string
+		wantErr string
+	}{
+		{"no destination", nil, "--to is required"},
+		{"unknown scheme", []string{"kafka://localhost:9092"}, "unsupported destination scheme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwardTo = tt.to
+			defer func() { forwardTo = nil }()
+			err := runForward(nil, []string{"web"})
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunLogs_AlertOn(t *testing.T) {
+	var mu sync.Mutex
+	var firings []logalert.Firing
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		StreamLogs: func(ctx context.Context, token, slug string, since time.Time, linesN int, followN bool, logType string, handler func(string)) error {
+			for _, line := range []string{"starting", "panic: nil map", "ECONNREFUSED", "panic: again"} {
+				handler(line)
+			}
+			return fmt.Errorf("API error 404: app not found")
+		},
+		RunAlert: func(ctx context.Context, f logalert.Firing) error {
+			mu.Lock()
+			firings = append(firings, f)
+			mu.Unlock()
+			return nil
+		},
+		Sleep: func(context.Context, time.Duration) error { return nil },
+	}
+	defer func() { deps = defaultDeps() }()
+	follow = true
+	alertOn = []string{"panic:", "ECONNREFUSED"}
+	alertExec = "cat"
+	alertThreshold = 2
+	defer func() {
+		follow = false
+		alertOn, alertExec, alertThreshold = nil, "", 1
+	}()
+
+	output := captureOutput(func() {
+		runLogs(nil, []string{"myapp"})
+	})
+
+	if !contains(output, `Watching 2 alert rule(s): "panic:", "ECONNREFUSED"`) {
+		t.Errorf("expected the rules to be announced, got: %s", output)
+	}
+	if len(firings) != 1 || firings[0].Rule.Name != "panic:" || firings[0].Count != 2 || firings[0].Matches[1].Line != "panic: again" {
+		t.Fatalf("expected one panic firing with both lines, got: %+v", firings)
+	}
+}
+
+func TestLoadAlertRules_TomlNeedsOptIn(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".hatch.toml"), []byte("[[logs.alerts]]\npattern = \".\"\nexec = \"rm -rf ~\"\n"), 0644)
+	t.Chdir(dir)
+	follow = true
+	defer func() { follow, alertsFromToml = false, false }()
+
+	rules, err := loadAlertRules(false)
+	if err != nil || len(rules) != 0 {
+		t.Fatalf("rules in .hatch.toml must not load without --alerts, got %v, %v", rules, err)
+	}
+
+	alertsFromToml = true
+	rules, err = loadAlertRules(false)
+	if err != nil || len(rules) != 1 || rules[0].Pattern != "." {
+		t.Fatalf("expected the .hatch.toml rule with --alerts, got %v, %v", rules, err)
+	}
+}
+
+func TestRunLogs_AlertFlagErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr string
+	}{
+		{"exec without rule", func() { alertExec = "true" }, "--exec and --webhook need --alert-on"},
+		{"not following", func() { alertOn, alertExec = []string{"x"}, "true" }, "--alert-on and --alerts only work when following"},
+		{"toml rules not following", func() { alertsFromToml = true }, "--alert-on and --alerts only work when following"},
+		{"no action", func() { follow, alertOn = true, []string{"x"} }, `rule "x" needs exec or webhook (set --exec or --webhook)`},
+		{"bad pattern", func() { follow, alertOn, alertExec = true, []string{"("}, "true" }, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer func() {
+				follow, alertsFromToml = false, false
+				alertOn, alertExec = nil, ""
+			}()
+			err := runLogs(nil, []string{"myapp"})
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunLogs_Deployment(t *testing.T) {
+	buildLog := []string{
+		"#9 [build 4/5] RUN go build ./...",
+		"#9 1.2 # example.com/shop",
+		"#9 1.2 main.go:12:2: undefined: cart",
+		"#9 ERROR: exit code: 1",
+	}
+	tests := []struct {
+		name      string
+		status    string
+		args      []string
+		want      []string
+		notWanted []string
+		wantErr   string
+	}{
+		{
+			name:   "failed deployment is summarized",
+			status: "build_failed",
+			args:   []string{"myapp"},
+			want:   []string{"deployment d42 (build_failed", "#9 ERROR: exit code: 1", "Build failed: compile error in main.go (line 3)", "    main.go:12:2: undefined: cart"},
+		},
+		{
+			name:      "successful deployment only prints the log",
+			status:    "running",
+			args:      []string{"myapp"},
+			want:      []string{"main.go:12:2"},
+			notWanted: []string{"Build failed"},
+		},
+		{
+			name:    "single egg only",
+			args:    []string{"web", "api"},
+			wantErr: "--deployment works with a single egg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID string
+			deps = &Deps{
+				GetToken: func() (string, error) { return "tok123", nil },
+				GetDeployment: func(ctx context.Context, token, slug, id string) (*api.Deployment, error) {
+					return &api.Deployment{ID: "d42", Status: tt.status}, nil
+				},
+				GetBuildLogs: func(ctx context.Context, token, slug, id string) ([]string, error) {
+					gotID = id
+					return buildLog, nil
+				},
+			}
+			defer func() { deps = defaultDeps() }()
+			deploymentID = "latest"
+			defer func() { deploymentID = "" }()
+
+			var err error
+			output := captureOutput(func() {
+				err = runLogs(nil, tt.args)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotID != "d42" {
+				t.Errorf("expected build logs of the resolved deployment, got %q", gotID)
+			}
+			for _, w := range tt.want {
+				if !contains(output, w) {
+					t.Errorf("expected %q in output:\n%s", w, output)
+				}
+			}
+			for _, w := range tt.notWanted {
+				if contains(output, w) {
+					t.Errorf("did not expect %q in output:\n%s", w, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+)
+
+// mergeWindow is how long lines are held back so that lines arriving from
+// different eggs at about the same time are printed in timestamp order.
+const mergeWindow = 250 * time.Millisecond
+
+// prefixColors are cycled through to tell eggs apart.
+var prefixColors = []func(string) string{ui.Cyan, ui.Purple, ui.Blue, ui.Green, ui.Yellow}
+
+// eggLine is a line received from one egg's stream, already formatted.
+type eggLine struct {
+	egg  int
+	text string
+	// at orders the line: its own timestamp, or when it was received.
+	at       time.Time
+	received time.Time
+}
+
+type eggEnd struct {
+	egg int
+	err error
+}
+
+// tailMany streams several eggs at once and prints their lines, prefixed
+// with the egg name, in one merged output. It returns when every stream has
+// ended, with an error if any of them failed. When ctx is done it still
+// waits for the streams to stop, so that none of them is left handling
+// lines (and firing alerts) after it returns.
+func tailMany(ctx context.Context, token string, slugs []string, logType string, filter *logparse.Filter) error {
+	lineCh := make(chan eggLine)
+	endCh := make(chan eggEnd, len(slugs))
+	var streams sync.WaitGroup
+	defer streams.Wait()
+	for i, slug := range slugs {
+		streams.Add(1)
+		go func() {
+			defer streams.Done()
+			err := streamEgg(ctx, token, slug, logType, filter.Since, slug+": ", func(line string) {
+				text, at, ok := formatLine(line, filter)
+				if !ok {
+					return
+				}
+				now := time.Now()
+				if at.IsZero() {
+					at = now
+				}
+				select {
+				case lineCh <- eggLine{egg: i, text: text, at: at, received: now}:
+				case <-ctx.Done():
+				}
+			})
+			endCh <- eggEnd{egg: i, err: err}
+		}()
+	}
+
+	prefixes := eggPrefixes(slugs)
+	shown := make([]int, len(slugs))
+	emit := func(ls []eggLine) {
+		for _, l := range ls {
+			shown[l.egg]++
+			fmt.Println(prefixes[l.egg] + l.text)
+		}
+	}
+
+	m := &merger{}
+	ticker := time.NewTicker(mergeWindow / 2)
+	defer ticker.Stop()
+	failed := 0
+	for running := len(slugs); running > 0; {
+		select {
+		case l := <-lineCh:
+			m.add(l)
+		case <-ticker.C:
+			emit(m.flush(time.Now().Add(-mergeWindow)))
+		case <-ctx.Done():
+			emit(m.flush(time.Now()))
+			return ctx.Err()
+		case end := <-endCh:
+			running--
+			emit(m.flush(time.Now()))
+			slug := slugs[end.egg]
+			left := ""
+			if running > 0 {
+				left = fmt.Sprintf(" (%d of %d still streaming)", running, len(slugs))
+			}
+			if end.err != nil {
+				failed++
+				ui.Warn(fmt.Sprintf("%s: log stream ended after %d line(s): %v%s", slug, shown[end.egg], end.err, left))
+			} else {
+				ui.Info(fmt.Sprintf("%s: log stream ended after %d line(s)%s", slug, shown[end.egg], left))
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d log streams failed", failed, len(slugs))
+	}
+	return nil
+}
+
+// eggPrefixes returns a colored, aligned "name | " prefix per egg.
+func eggPrefixes(slugs []string) []string {
+	width := 0
+	for _, slug := range slugs {
+		width = max(width, len(slug))
+	}
+	prefixes := make([]string, len(slugs))
+	for i, slug := range slugs {
+		color := prefixColors[i%len(prefixColors)]
+		prefixes[i] = color(slug+strings.Repeat(" ", width-len(slug))) + " | "
+	}
+	return prefixes
+}
+
+// merger holds recent lines back and releases them ordered by timestamp.
+type merger struct {
+	pending []eggLine
+}
+
+func (m *merger) add(l eggLine) {
+	m.pending = append(m.pending, l)
+}
+
+// flush returns, in timestamp order, the lines received at or before cutoff
+// and keeps the rest.
+func (m *merger) flush(cutoff time.Time) []eggLine {
+	var ready, keep []eggLine
+	for _, l := range m.pending {
+		if l.received.After(cutoff) {
+			keep = append(keep, l)
+		} else {
+			ready = append(ready, l)
+		}
+	}
+	m.pending = keep
+	sort.SliceStable(ready, func(i, j int) bool { return ready[i].at.Before(ready[j].at) })
+	return ready
+}
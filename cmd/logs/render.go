@@ -0,0 +1,61 @@
+package logs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+)
+
+// renderEntry formats a parsed line as time, level and message columns with
+// the remaining fields appended as key=value. Unrecognized lines are
+// returned unchanged.
+func renderEntry(e logparse.Entry) string {
+	if e.Format == "" {
+		return e.Raw
+	}
+
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString(ui.Dim(e.Time.Local().Format("15:04:05.000")))
+		b.WriteString(" ")
+	}
+	b.WriteString(levelColumn(e.Level))
+	b.WriteString(" ")
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		b.WriteString(" ")
+		b.WriteString(ui.Dim(f.Key + "=" + quoteField(f.Value)))
+	}
+	return b.String()
+}
+
+// levelColumn returns the level upper-cased, padded to a fixed width and
+// colored by severity.
+func levelColumn(l logparse.Level) string {
+	name := strings.ToUpper(l.String())
+	if name == "" {
+		name = "-"
+	}
+	name += strings.Repeat(" ", 5-len(name))
+	switch {
+	case l >= logparse.LevelError:
+		return ui.Red(name)
+	case l == logparse.LevelWarn:
+		return ui.Yellow(name)
+	case l == logparse.LevelInfo:
+		return ui.Blue(name)
+	default:
+		return ui.Dim(name)
+	}
+}
+
+// quoteField quotes values that would otherwise be ambiguous in key=value
+// form.
+func quoteField(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -3,22 +3,37 @@ package mcp
 import (
 	"fmt"
 
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/mcpserver"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 )
 
+var disableReveal bool
+
 func NewCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "Start MCP tool server for AI assistants",
-		Long:  "Start a Model Context Protocol (MCP) server over stdio. This exposes Hatch CLI tools for use by AI assistants like Claude.",
-		RunE:  runMCP,
+		Long: `Start a Model Context Protocol (MCP) server over stdio. This exposes Hatch CLI tools for use by AI assistants like Claude.
+
+Environment variable tools return sensitive values masked. The reveal_env_var
+tool returns a single plaintext value and records each call in
+~/.hatch/audit.log. Leave it out with --disable-reveal, or permanently with
+"mcp": {"disable_reveal": true} in ~/.hatch/config.json.`,
+		RunE: runMCP,
 	}
+	cmd.Flags().BoolVar(&disableReveal, "disable-reveal", false, "do not offer the reveal_env_var tool")
+	return cmd
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
-	s := mcpserver.NewServer()
+	opts := mcpserver.Options{DisableReveal: disableReveal}
+	if cfg, err := config.Load(); err == nil && cfg.MCP != nil && cfg.MCP.DisableReveal {
+		opts.DisableReveal = true
+	}
+
+	s := mcpserver.NewServer(opts)
 	if err := server.ServeStdio(s); err != nil {
 		return fmt.Errorf("MCP server error: %w", err)
 	}
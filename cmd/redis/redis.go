@@ -5,6 +5,7 @@ import (
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -48,6 +49,7 @@ Limits: 25 MB memory, 50 max connections, no persistence (data lost on restart).
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := auth.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -64,7 +66,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	ui.Info(fmt.Sprintf("Adding Redis cache to %s...", ui.Bold(slug)))
 
 	client := api.NewClient(token)
-	addon, err := client.AddAddon(slug, "redis")
+	addon, err := client.AddAddon(ctx, slug, "redis")
 	if err != nil {
 		return fmt.Errorf("adding redis: %w", err)
 	}
@@ -76,7 +78,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		ui.Info("Limits: 25 MB memory, 50 connections, no persistence")
 		ui.Info("Note: Redis data is ephemeral — lost on egg restart.")
-		ui.Info("Redeploy to activate: hatch deploy (from your project directory)")
+		ui.Info(fmt.Sprintf("Restart to activate: hatch restart %s", slug))
 	} else {
 		ui.Warn(fmt.Sprintf("Redis status: %s", addon.Status))
 	}
@@ -94,6 +96,7 @@ func newInfoCmd() *cobra.Command {
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := auth.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -108,7 +111,7 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	client := api.NewClient(token)
-	redisURL, status, err := client.GetRedisURL(slug)
+	redisURL, status, err := client.GetRedisURL(ctx, slug)
 	if err != nil {
 		return fmt.Errorf("no redis addon for %s. Run: hatch redis add %s", slug, slug)
 	}
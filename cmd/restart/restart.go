@@ -2,12 +2,14 @@ package restart
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -16,15 +18,15 @@ import (
 // Deps holds injectable dependencies for testing.
 type Deps struct {
 	GetToken   func() (string, error)
-	RestartApp func(token, slug string) error
+	RestartApp func(ctx context.Context, token, slug string) error
 	Confirm    func(prompt string) bool
 }
 
 func defaultDeps() *Deps {
 	return &Deps{
 		GetToken: auth.GetToken,
-		RestartApp: func(token, slug string) error {
-			return api.NewClient(token).RestartApp(slug)
+		RestartApp: func(ctx context.Context, token, slug string) error {
+			return api.NewClient(token).RestartApp(ctx, slug)
 		},
 		Confirm: confirmPrompt,
 	}
@@ -52,6 +54,7 @@ func NewCmd() *cobra.Command {
 }
 
 func runRestart(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
 	token, err := deps.GetToken()
 	if err != nil {
 		return fmt.Errorf("checking auth: %w", err)
@@ -74,7 +77,7 @@ func runRestart(cmd *cobra.Command, args []string) error {
 
 	sp := ui.NewSpinner(fmt.Sprintf("Restarting %s...", slug))
 	sp.Start()
-	err = deps.RestartApp(token, slug)
+	err = deps.RestartApp(ctx, token, slug)
 	sp.Stop()
 
 	if err != nil {
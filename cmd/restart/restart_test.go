@@ -2,6 +2,7 @@ package restart
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -40,7 +41,7 @@ func TestRunRestart_Confirmed(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
 		Confirm:  func(prompt string) bool { return true },
-		RestartApp: func(token, slug string) error {
+		RestartApp: func(ctx context.Context, token, slug string) error {
 			restarted = slug
 			return nil
 		},
@@ -85,7 +86,7 @@ func TestRunRestart_APIError(t *testing.T) {
 	deps = &Deps{
 		GetToken: func() (string, error) { return "tok123", nil },
 		Confirm:  func(prompt string) bool { return true },
-		RestartApp: func(token, slug string) error {
+		RestartApp: func(ctx context.Context, token, slug string) error {
 			return fmt.Errorf("server error")
 		},
 	}
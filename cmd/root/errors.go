@@ -0,0 +1,66 @@
+package root
+
+import (
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+// Exit codes for errors returned by Execute, so scripts can tell a missing
+// egg from an expired token without parsing messages. Interrupted commands
+// exit with 130 and `hatch run` passes its command's code through.
+const (
+	ExitError       = 1
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitQuota       = 5
+	ExitRateLimited = 6
+	ExitUnavailable = 7
+)
+
+// ExitCode returns the exit status for an error returned by Execute.
+func ExitCode(err error) int {
+	switch {
+	case api.IsUnauthorized(err), api.IsForbidden(err):
+		return ExitAuth
+	case api.IsNotFound(err):
+		return ExitNotFound
+	case api.IsQuotaExceeded(err):
+		return ExitQuota
+	case api.IsRateLimited(err):
+		return ExitRateLimited
+	case api.IsRetryable(err):
+		return ExitUnavailable
+	}
+	return ExitError
+}
+
+// ErrorHint returns advice to print below an error returned by Execute,
+// or "" when there is none.
+func ErrorHint(err error) string {
+	apiErr, ok := api.AsError(err)
+	if !ok {
+		return ""
+	}
+
+	var hint string
+	switch {
+	case api.IsUnauthorized(err):
+		hint = "Your token was rejected. Run 'hatch login', or check HATCH_TOKEN and --token."
+	case api.IsForbidden(err):
+		hint = "Your token is not allowed to do this. Check that the egg belongs to your account."
+	case api.IsNotFound(err):
+		hint = "Not found. Run 'hatch apps' to check the egg's slug."
+	case api.IsQuotaExceeded(err):
+		hint = "Your account is over its quota. Run 'hatch energy' to check, or 'hatch boost' to add energy."
+	case api.IsRateLimited(err):
+		hint = "Too many requests. Wait a minute and try again."
+	case apiErr.Retryable:
+		hint = "The Hatch API is unavailable. Try again in a few minutes."
+	}
+	if apiErr.RequestID != "" {
+		if hint != "" {
+			hint += "\n"
+		}
+		hint += "Request ID: " + apiErr.RequestID + " (include it when reporting this)"
+	}
+	return hint
+}
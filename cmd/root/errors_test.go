@@ -0,0 +1,43 @@
+package root
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func TestExitCodeAndHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantHint string
+	}{
+		{"plain error", errors.New("boom"), ExitError, ""},
+		{"unauthorized", &api.Error{Status: 401, Code: api.CodeUnauthorized}, ExitAuth, "hatch login"},
+		{"forbidden", &api.Error{Status: 403, Code: api.CodeForbidden}, ExitAuth, "not allowed"},
+		{"wrapped not found", fmt.Errorf("fetching app: %w", &api.Error{Status: 404, Code: api.CodeNotFound}), ExitNotFound, "hatch apps"},
+		{"quota", &api.Error{Status: 402, Code: api.CodeQuotaExceeded}, ExitQuota, "hatch energy"},
+		{"rate limited", &api.Error{Status: 429, Code: api.CodeRateLimited, Retryable: true}, ExitRateLimited, "Too many requests"},
+		{"unavailable", &api.Error{Status: 503, Code: api.CodeUnavailable, Retryable: true}, ExitUnavailable, "unavailable"},
+		{"other API error", &api.Error{Status: 400, Code: api.CodeBadRequest}, ExitError, ""},
+		{"request id", &api.Error{Status: 500, Code: api.CodeInternal, RequestID: "req-9"}, ExitError, "Request ID: req-9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.wantCode {
+				t.Errorf("ExitCode = %d, want %d", got, tt.wantCode)
+			}
+			hint := ErrorHint(tt.err)
+			if tt.wantHint == "" && hint != "" {
+				t.Errorf("expected no hint, got %q", hint)
+			}
+			if !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("hint %q does not mention %q", hint, tt.wantHint)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package root
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,9 +30,12 @@ import (
 	mcpcmd "github.com/EscapeVelocityOperations/hatch-cli/cmd/mcp"
 	"github.com/EscapeVelocityOperations/hatch-cli/cmd/open"
 	"github.com/EscapeVelocityOperations/hatch-cli/cmd/restart"
+	"github.com/EscapeVelocityOperations/hatch-cli/cmd/run"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/telemetry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -53,7 +59,16 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := interrupt.Context(context.Background())
+	defer stop()
+	err := rootCmd.ExecuteContext(ctx)
+	// Once interrupted, whatever failed failed because it was cancelled,
+	// except a command run by `hatch run`, whose exit code is kept.
+	var exitErr *run.ExitError
+	if err != nil && ctx.Err() != nil && !errors.As(err, &exitErr) {
+		return ctx.Err()
+	}
+	return err
 }
 
 // IsVerbose returns whether the --verbose flag is set.
@@ -72,6 +87,9 @@ func init() {
 		// Track command for telemetry
 		lastCommandName = cmd.CommandPath()
 		lastCommandArgs = args
+		if cmd.Annotations[telemetry.AnnotationKeysOnly] == "true" {
+			lastCommandArgs = telemetry.KeysOnly(args)
+		}
 		lastMode = "cli"
 
 		if verbose {
@@ -80,6 +98,7 @@ func init() {
 		if tokenFlag != "" {
 			auth.SetTokenFlag(tokenFlag)
 		}
+		configureRetries()
 
 		// Skip TOS check for commands that don't need it
 		name := cmd.Name()
@@ -156,6 +175,66 @@ func init() {
 	rootCmd.AddCommand(open.NewCmd())
 	rootCmd.AddCommand(rediscmd.NewCmd())
 	rootCmd.AddCommand(restart.NewCmd())
+	rootCmd.AddCommand(run.NewCmd())
+}
+
+// configureRetries applies the retry policy from the config file and
+// environment to the API clients this command creates.
+func configureRetries() {
+	var rc config.RetryConfig
+	if cfg, err := config.Load(); err == nil && cfg.Retry != nil {
+		rc = *cfg.Retry
+	}
+	p, err := retryPolicy(rc, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
+	}
+	api.SetRetryPolicy(p)
+}
+
+// retryPolicy starts from api.DefaultRetryPolicy and applies the config file
+// settings, then HATCH_MAX_RETRIES and HATCH_RETRY_MAX_DELAY. Invalid values
+// are reported and leave the setting unchanged.
+func retryPolicy(rc config.RetryConfig, getenv func(string) string) (api.RetryPolicy, error) {
+	p := api.DefaultRetryPolicy
+	var errs []error
+
+	maxDelay := func(source, v string) {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("ignoring %s %q: want a positive duration like 30s", source, v))
+			return
+		}
+		p.MaxDelay = d
+		if p.BaseDelay > d {
+			p.BaseDelay = d
+		}
+	}
+	maxRetries := func(source string, n int) {
+		if n < 0 {
+			errs = append(errs, fmt.Errorf("ignoring %s %d: must not be negative", source, n))
+			return
+		}
+		p.MaxRetries = n
+	}
+
+	if rc.MaxRetries != nil {
+		maxRetries("retry.max_retries", *rc.MaxRetries)
+	}
+	if rc.MaxDelay != "" {
+		maxDelay("retry.max_delay", rc.MaxDelay)
+	}
+	if v := getenv("HATCH_MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil {
+			errs = append(errs, fmt.Errorf("ignoring HATCH_MAX_RETRIES %q: not a number", v))
+		} else {
+			maxRetries("HATCH_MAX_RETRIES", n)
+		}
+	}
+	if v := getenv("HATCH_RETRY_MAX_DELAY"); v != "" {
+		maxDelay("HATCH_RETRY_MAX_DELAY", v)
+	}
+	return p, errors.Join(errs...)
 }
 
 // LastCommand returns the last executed command path.
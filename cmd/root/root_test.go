@@ -7,7 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/telemetry"
+	"github.com/spf13/cobra"
 )
 
 func setupTestConfig(t *testing.T) string {
@@ -136,6 +139,22 @@ func TestPersistentPreRun_VerboseFlag(t *testing.T) {
 	}
 }
 
+func TestPersistentPreRun_KeysOnlyArgs(t *testing.T) {
+	setupTestConfig(t)
+	config.Save(&config.Config{TosAcceptedAt: time.Now().UTC().Format(time.RFC3339)})
+
+	cmd := &cobra.Command{Use: "set", Annotations: map[string]string{telemetry.AnnotationKeysOnly: "true"}}
+	rootCmd.PersistentPreRun(cmd, []string{"API_KEY=sk_live_secret", "PORT"})
+
+	args := strings.Join(LastArgs(), " ")
+	if strings.Contains(args, "sk_live_secret") {
+		t.Errorf("secret value reached telemetry args: %s", args)
+	}
+	if args != "API_KEY=<redacted> PORT" {
+		t.Errorf("unexpected telemetry args: %s", args)
+	}
+}
+
 func TestPersistentPreRun_ConfigLoadErrorDoesNotBlock(t *testing.T) {
 	// Set HOME to a directory that exists but has unreadable config
 	home := setupTestConfig(t)
@@ -159,6 +178,45 @@ func TestPersistentPreRun_ConfigLoadErrorDoesNotBlock(t *testing.T) {
 	}
 }
 
+func TestRetryPolicy(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	def := api.DefaultRetryPolicy
+	tests := []struct {
+		name           string
+		rc             config.RetryConfig
+		env            map[string]string
+		wantMaxRetries int
+		wantMaxDelay   time.Duration
+		wantErr        string
+	}{
+		{"defaults", config.RetryConfig{}, nil, def.MaxRetries, def.MaxDelay, ""},
+		{"config file", config.RetryConfig{MaxRetries: intPtr(5), MaxDelay: "10s"}, nil, 5, 10 * time.Second, ""},
+		{"config disables retries", config.RetryConfig{MaxRetries: intPtr(0)}, nil, 0, def.MaxDelay, ""},
+		{"env overrides config", config.RetryConfig{MaxRetries: intPtr(5)}, map[string]string{"HATCH_MAX_RETRIES": "1", "HATCH_RETRY_MAX_DELAY": "2m"}, 1, 2 * time.Minute, ""},
+		{"invalid env number", config.RetryConfig{}, map[string]string{"HATCH_MAX_RETRIES": "many"}, def.MaxRetries, def.MaxDelay, "HATCH_MAX_RETRIES"},
+		{"negative retries", config.RetryConfig{MaxRetries: intPtr(-1)}, nil, def.MaxRetries, def.MaxDelay, "retry.max_retries"},
+		{"invalid delay", config.RetryConfig{MaxDelay: "soon"}, nil, def.MaxRetries, def.MaxDelay, "retry.max_delay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := retryPolicy(tt.rc, func(k string) string { return tt.env[k] })
+			if p.MaxRetries != tt.wantMaxRetries || p.MaxDelay != tt.wantMaxDelay {
+				t.Errorf("got %d retries up to %s, want %d up to %s", p.MaxRetries, p.MaxDelay, tt.wantMaxRetries, tt.wantMaxDelay)
+			}
+			if p.BaseDelay > p.MaxDelay {
+				t.Errorf("base delay %s exceeds max delay %s", p.BaseDelay, p.MaxDelay)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error mentioning %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestRootCmdStructure(t *testing.T) {
 	// Test that rootCmd has expected properties
 	if rootCmd.Use != "hatch" {
@@ -0,0 +1,244 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/cmd/db"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/interrupt"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/resolve"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// tunnel is the part of db.Tunnel that run needs.
+type tunnel interface {
+	Addr() net.Addr
+	Close() error
+}
+
+// Deps holds injectable dependencies for testing.
+type Deps struct {
+	GetToken       func() (string, error)
+	GetEnvVars     func(ctx context.Context, token, slug string) ([]api.EnvVar, error)
+	GetDatabaseURL func(ctx context.Context, token, slug string) (string, error)
+	StartTunnel    func(ctx context.Context, token, slug string) (tunnel, error)
+	Exec           func(name string, args, env []string) (int, error)
+}
+
+func defaultDeps() *Deps {
+	return &Deps{
+		GetToken: auth.GetToken,
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return api.NewClient(token).GetEnvVars(ctx, slug)
+		},
+		GetDatabaseURL: func(ctx context.Context, token, slug string) (string, error) {
+			return api.NewClient(token).GetDatabaseURL(ctx, slug)
+		},
+		StartTunnel: func(ctx context.Context, token, slug string) (tunnel, error) {
+			return db.StartTunnel(token, slug, "127.0.0.1:0", true)
+		},
+		Exec: execCommand,
+	}
+}
+
+var deps = defaultDeps()
+
+var (
+	appSlug  string
+	dbTunnel bool
+)
+
+// ExitError carries the exit code of the command run by `hatch run` so that
+// main can exit with it without printing anything.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.Code)
+}
+
+// NewCmd returns the run command.
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run [flags] -- COMMAND [ARGS...]",
+		Short: "Run a local command with the egg's environment variables",
+		Long: `Run a local command with the egg's environment variables merged over the
+local environment. Useful for one-off scripts such as migrations or seeds:
+
+  hatch run -- npm run migrate
+  hatch run --db-tunnel -- python manage.py migrate
+
+With --db-tunnel, a database tunnel (as in 'hatch db connect') is started on
+a free local port for the duration of the command, and DATABASE_URL points
+at it. The command's exit code becomes hatch's exit code.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runRun,
+	}
+	cmd.Flags().StringVarP(&appSlug, "app", "a", "", "egg slug (auto-detected from .hatch.toml if omitted)")
+	cmd.Flags().BoolVar(&dbTunnel, "db-tunnel", false, "tunnel the egg's database and point DATABASE_URL at it")
+	// Everything after the command name belongs to the command
+	cmd.Flags().SetInterspersed(false)
+	return cmd
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	ctx := interrupt.FromCommand(cmd)
+	token, err := deps.GetToken()
+	if err != nil {
+		return fmt.Errorf("checking auth: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token")
+	}
+
+	slug := appSlug
+	if slug == "" {
+		slug = resolve.SlugFromToml()
+	}
+	if slug == "" {
+		return fmt.Errorf("no egg specified. Use --app <slug> (or set slug in .hatch.toml)")
+	}
+
+	vars, err := deps.GetEnvVars(ctx, token, slug)
+	if err != nil {
+		return fmt.Errorf("fetching env vars: %w", err)
+	}
+
+	if dbTunnel {
+		dbURL, err := deps.GetDatabaseURL(ctx, token, slug)
+		if err != nil {
+			return fmt.Errorf("fetching database URL: %w", err)
+		}
+		if dbURL == "" {
+			return fmt.Errorf("%s has no database. Add one with 'hatch db add' or drop --db-tunnel", slug)
+		}
+
+		t, err := deps.StartTunnel(ctx, token, slug)
+		if err != nil {
+			return fmt.Errorf("starting database tunnel: %w", err)
+		}
+		defer t.Close()
+
+		localURL, err := tunnelURL(dbURL, t.Addr().String())
+		if err != nil {
+			return err
+		}
+		// mergeEnv applies later entries last, so this overrides the egg's value
+		vars = append(vars, api.EnvVar{Key: "DATABASE_URL", Value: localURL})
+		fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("→ Database tunnel for %s on %s", slug, t.Addr())))
+	}
+
+	env := mergeEnv(os.Environ(), vars)
+	fmt.Fprintln(os.Stderr, ui.Dim(fmt.Sprintf("→ Running %s with the environment of %s", args[0], slug)))
+
+	code, err := deps.Exec(args[0], args[1:], env)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+	return nil
+}
+
+// mergeEnv returns base with vars applied on top; later vars win.
+func mergeEnv(base []string, vars []api.EnvVar) []string {
+	index := make(map[string]int, len(base))
+	env := make([]string, 0, len(base)+len(vars))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if i, ok := index[key]; ok {
+			env[i] = kv
+			continue
+		}
+		index[key] = len(env)
+		env = append(env, kv)
+	}
+	for _, v := range vars {
+		kv := v.Key + "=" + v.Value
+		if i, ok := index[v.Key]; ok {
+			env[i] = kv
+			continue
+		}
+		index[v.Key] = len(env)
+		env = append(env, kv)
+	}
+	return env
+}
+
+// tunnelURL rewrites the host of a database URL to the local tunnel address,
+// keeping credentials, database name, and query parameters.
+func tunnelURL(dbURL, addr string) (string, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing database URL: %w", err)
+	}
+	u.Host = addr
+	return u.String(), nil
+}
+
+// execCommand runs name with the given environment, connected to the
+// terminal. Signals do not stop hatch while the command runs, so cleanup
+// such as closing the tunnel still happens; those the command would not
+// get on its own are forwarded to it (see forwardedSignals).
+func execCommand(name string, args, env []string) (int, error) {
+	defer interrupt.Hold()()
+	c := exec.Command(name, args...)
+	c.Env = env
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("starting %s: %w", name, err)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, forwardedSignals(term.IsTerminal(int(os.Stdin.Fd())))...)
+	defer func() {
+		signal.Stop(sigCh)
+		close(sigCh)
+	}()
+	go func() {
+		for sig := range sigCh {
+			c.Process.Signal(sig)
+		}
+	}()
+
+	err := c.Wait()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return 0, fmt.Errorf("running %s: %w", name, err)
+	}
+
+	state := c.ProcessState
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return state.ExitCode(), nil
+}
+
+// forwardedSignals returns the signals execCommand passes on to the
+// command. A Ctrl-C at the terminal already reaches the command, which is
+// in hatch's process group, so forwarding it would deliver it twice. SIGINT
+// is only forwarded when stdin is not a terminal, where it was sent to
+// hatch alone, as by a process supervisor.
+func forwardedSignals(tty bool) []os.Signal {
+	if tty {
+		return []os.Signal{syscall.SIGTERM}
+	}
+	return []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+}
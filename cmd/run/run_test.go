@@ -0,0 +1,183 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+type fakeTunnel struct {
+	closed bool
+}
+
+func (f *fakeTunnel) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 54321}
+}
+
+func (f *fakeTunnel) Close() error {
+	f.closed = true
+	return nil
+}
+
+func envValue(env []string, key string) (string, bool) {
+	var value string
+	found := false
+	for _, kv := range env {
+		if k, v, _ := strings.Cut(kv, "="); k == key {
+			value, found = v, true
+		}
+	}
+	return value, found
+}
+
+func TestRunRun_NotLoggedIn(t *testing.T) {
+	deps = &Deps{
+		GetToken: func() (string, error) { return "", nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	err := runRun(nil, []string{"true"})
+	if err == nil || err.Error() != "not logged in. Run 'hatch login', set HATCH_TOKEN, or use --token" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunRun_InjectsEnvAndPropagatesExitCode(t *testing.T) {
+	appSlug = "myapp"
+	defer func() { appSlug = "" }()
+	t.Setenv("PORT", "3000")
+	t.Setenv("LOCAL_ONLY", "kept")
+
+	var gotName string
+	var gotArgs, gotEnv []string
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return []api.EnvVar{{Key: "PORT", Value: "8080"}, {Key: "API_KEY", Value: "abc"}}, nil
+		},
+		Exec: func(name string, args, env []string) (int, error) {
+			gotName, gotArgs, gotEnv = name, args, env
+			return 3, nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	err := runRun(nil, []string{"npm", "run", "migrate"})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected exit code 3 to propagate, got %v", err)
+	}
+	if gotName != "npm" || strings.Join(gotArgs, " ") != "run migrate" {
+		t.Errorf("unexpected command: %s %v", gotName, gotArgs)
+	}
+	if v, _ := envValue(gotEnv, "PORT"); v != "8080" {
+		t.Errorf("expected egg PORT to override local, got %q", v)
+	}
+	if v, _ := envValue(gotEnv, "API_KEY"); v != "abc" {
+		t.Errorf("expected API_KEY from egg, got %q", v)
+	}
+	if v, _ := envValue(gotEnv, "LOCAL_ONLY"); v != "kept" {
+		t.Errorf("expected local env to be kept, got %q", v)
+	}
+}
+
+func TestRunRun_DBTunnel(t *testing.T) {
+	appSlug = "myapp"
+	dbTunnel = true
+	defer func() { appSlug = ""; dbTunnel = false }()
+
+	tun := &fakeTunnel{}
+	var gotEnv []string
+	deps = &Deps{
+		GetToken: func() (string, error) { return "tok123", nil },
+		GetEnvVars: func(ctx context.Context, token, slug string) ([]api.EnvVar, error) {
+			return []api.EnvVar{{Key: "DATABASE_URL", Value: "postgresql://u:p@db.internal:5432/app?sslmode=disable"}}, nil
+		},
+		GetDatabaseURL: func(ctx context.Context, token, slug string) (string, error) {
+			return "postgresql://u:p@db.internal:5432/app?sslmode=disable", nil
+		},
+		StartTunnel: func(ctx context.Context, token, slug string) (tunnel, error) { return tun, nil },
+		Exec: func(name string, args, env []string) (int, error) {
+			if tun.closed {
+				t.Error("tunnel closed before the command ran")
+			}
+			gotEnv = env
+			return 0, nil
+		},
+	}
+	defer func() { deps = defaultDeps() }()
+
+	if err := runRun(nil, []string{"./migrate"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, _ := envValue(gotEnv, "DATABASE_URL"); v != "postgresql://u:p@127.0.0.1:54321/app?sslmode=disable" {
+		t.Errorf("expected DATABASE_URL to point at the tunnel, got %q", v)
+	}
+	if !tun.closed {
+		t.Error("expected tunnel to be closed after the command")
+	}
+}
+
+func TestRunRun_DBTunnelWithoutDatabase(t *testing.T) {
+	appSlug = "myapp"
+	dbTunnel = true
+	defer func() { appSlug = ""; dbTunnel = false }()
+
+	deps = &Deps{
+		GetToken:       func() (string, error) { return "tok123", nil },
+		GetEnvVars:     func(ctx context.Context, token, slug string) ([]api.EnvVar, error) { return nil, nil },
+		GetDatabaseURL: func(ctx context.Context, token, slug string) (string, error) { return "", nil },
+	}
+	defer func() { deps = defaultDeps() }()
+
+	err := runRun(nil, []string{"true"})
+	if err == nil || !strings.Contains(err.Error(), "has no database") {
+		t.Fatalf("expected no-database error, got: %v", err)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	env := mergeEnv(
+		[]string{"A=1", "B=2", "A=dup"},
+		[]api.EnvVar{{Key: "B", Value: "egg"}, {Key: "C", Value: "x=y"}},
+	)
+	want := []string{"A=dup", "B=egg", "C=x=y"}
+	if strings.Join(env, ",") != strings.Join(want, ",") {
+		t.Errorf("mergeEnv() = %v, want %v", env, want)
+	}
+}
+
+func TestExecCommand_ExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	code, err := execCommand("sh", []string{"-c", `test "$HATCH_TEST" = ok && exit 7`}, []string{"HATCH_TEST=ok"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code != 7 {
+		t.Errorf("expected exit code 7, got %d", code)
+	}
+
+	if _, err := execCommand("hatch-no-such-command", nil, nil); err == nil {
+		t.Error("expected an error for a missing command")
+	}
+}
+
+func TestForwardedSignals(t *testing.T) {
+	if got := forwardedSignals(true); len(got) != 1 || got[0] != syscall.SIGTERM {
+		t.Errorf("with a terminal, only SIGTERM should be forwarded, got %v", got)
+	}
+	if got := forwardedSignals(false); len(got) != 2 {
+		t.Errorf("without a terminal, SIGINT and SIGTERM should be forwarded, got %v", got)
+	}
+}
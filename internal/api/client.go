@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,6 +35,16 @@ const (
 	artifactUploadTimeout = 10 * time.Minute
 )
 
+// ErrUnsupportedArtifactFormat is returned by UploadArtifact and
+// UploadArtifactChunked when the API rejects the artifact's compression format (HTTP 415). Callers can fall back
+// to gzip, which every API version accepts.
+var ErrUnsupportedArtifactFormat = errors.New("artifact format not supported by the API")
+
+// ErrLogStreamInterrupted is returned by StreamLogs when the log stream could
+// not be reached or dropped without a normal close, e.g. while a sleeping egg
+// wakes up. Reconnecting may succeed.
+var ErrLogStreamInterrupted = errors.New("log stream interrupted")
+
 var slugRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
 
 // envKeyRegex validates environment variable key names.
@@ -45,6 +57,9 @@ var envKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,255}$`)
 // strings, and other URL-manipulation characters.
 var domainRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]{0,253}[a-zA-Z0-9])?$`)
 
+// deploymentIDRegex validates deployment IDs used in URL paths.
+var deploymentIDRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,127}$`)
+
 // validateSlug ensures slug values are safe for URL paths.
 func validateSlug(slug string) error {
 	if !slugRegex.MatchString(slug) {
@@ -97,6 +112,7 @@ type Client struct {
 	host       string
 	token      string
 	httpClient *http.Client
+	retry      RetryPolicy
 }
 
 // NewClient creates an API client with Bearer token auth.
@@ -122,6 +138,7 @@ func NewClient(token string) *Client {
 			Timeout:   defaultTimeout,
 			Transport: transport,
 		},
+		retry: retryPolicy,
 	}
 }
 
@@ -133,16 +150,99 @@ func NewTestClient(token, host string) *Client {
 }
 
 // do executes an HTTP request with Bearer auth and returns the response.
-func (c *Client) do(method, path string, body io.Reader) (*http.Response, error) {
+// Transient failures are retried according to the client's retry policy.
+// POST and PATCH requests carry an Idempotency-Key, the same on every
+// attempt, so that the API applies them only once.
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		payload = data
+	}
+	var key string
+	if method == "POST" || method == "PATCH" {
+		key = newIdempotencyKey()
+	}
+	return c.withRetries(ctx, method, path, nil, func() (*http.Response, error) {
+		return c.send(ctx, method, path, payload, key)
+	})
+}
+
+// withRetries calls attempt until it returns a response below 400, fails
+// in a way that is not worth retrying, or the client's retry policy runs
+// out. retryStatus, if not nil, marks further statuses as worth retrying
+// for this request.
+func (c *Client) withRetries(ctx context.Context, method, path string, retryStatus func(int) bool, attempt func() (*http.Response, error)) (*http.Response, error) {
+	for retry := 1; ; retry++ {
+		resp, err := attempt()
+		var reason string
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !retryableError(err) {
+				return nil, err
+			}
+			reason = err.Error()
+		case resp.StatusCode >= 400:
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if verboseEnabled {
+				fmt.Fprintf(os.Stderr, "< Body: %s\n", RedactToken(strings.TrimSpace(string(data))))
+			}
+			apiErr := newError(resp, data)
+			if retryStatus != nil && retryStatus(resp.StatusCode) {
+				apiErr.Retryable = true
+			}
+			if !apiErr.Retryable {
+				return nil, apiErr
+			}
+			err = apiErr
+			reason = resp.Status
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		default:
+			return resp, nil
+		}
+
+		if retry > c.retry.MaxRetries {
+			return nil, err
+		}
+		delay, ok := c.retry.delay(retry, retryAfter)
+		if !ok {
+			return nil, err
+		}
+		if verboseEnabled {
+			fmt.Fprintf(os.Stderr, "* %s %s failed (%s), retry %d of %d in %s\n", method, path, reason, retry, c.retry.MaxRetries, delay.Round(time.Millisecond))
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt at a request.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, idempotencyKey string) (*http.Response, error) {
 	url := c.host + apiPath + path
-	req, err := http.NewRequest(method, url, body)
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
-	if body != nil {
+	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	if verboseEnabled {
 		fmt.Fprintf(os.Stderr, "> %s %s\n", method, url)
@@ -166,22 +266,12 @@ func (c *Client) do(method, path string, body io.Reader) (*http.Response, error)
 			fmt.Fprintf(os.Stderr, "< %s: %s\n", k, strings.Join(v, ", "))
 		}
 	}
-
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		data, _ := io.ReadAll(resp.Body)
-		body := RedactToken(strings.TrimSpace(string(data)))
-		if verboseEnabled {
-			fmt.Fprintf(os.Stderr, "< Body: %s\n", body)
-		}
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, body)
-	}
 	return resp, nil
 }
 
 // ListApps returns all apps for the authenticated user.
-func (c *Client) ListApps() ([]App, error) {
-	resp, err := c.do("GET", "/apps", nil)
+func (c *Client) ListApps(ctx context.Context) ([]App, error) {
+	resp, err := c.do(ctx, "GET", "/apps", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -195,11 +285,11 @@ func (c *Client) ListApps() ([]App, error) {
 }
 
 // GetApp returns details for a single app.
-func (c *Client) GetApp(slug string) (*App, error) {
+func (c *Client) GetApp(ctx context.Context, slug string) (*App, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug, nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -214,9 +304,9 @@ func (c *Client) GetApp(slug string) (*App, error) {
 
 // CreateApp creates a new app with the given name.
 // The server generates a unique slug (name + random suffix).
-func (c *Client) CreateApp(name string) (*App, error) {
+func (c *Client) CreateApp(ctx context.Context, name string) (*App, error) {
 	body := fmt.Sprintf(`{"name":%q}`, name)
-	resp, err := c.do("POST", "/apps", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/apps", strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -230,9 +320,18 @@ func (c *Client) CreateApp(name string) (*App, error) {
 }
 
 // StreamLogs opens a WebSocket connection to stream app logs.
-// It calls the handler for each log line. The caller should cancel via context or close.
+// It calls the handler for each log line until the stream ends or ctx is
+// done, in which case it returns ctx's error.
 // logType can be "" for runtime logs or "build" for build logs.
-func (c *Client) StreamLogs(slug string, tail int, follow bool, logType string, handler func(line string)) error {
+func (c *Client) StreamLogs(ctx context.Context, slug string, tail int, follow bool, logType string, handler func(line string)) error {
+	return c.StreamLogsSince(ctx, slug, time.Time{}, tail, follow, logType, handler)
+}
+
+// StreamLogsSince is StreamLogs starting at the given time instead of the
+// last tail lines, for resuming a dropped stream. A zero since means no
+// lower bound. It returns nil when the server closes the stream normally and
+// an error wrapping ErrLogStreamInterrupted when the connection fails or drops.
+func (c *Client) StreamLogsSince(ctx context.Context, slug string, since time.Time, tail int, follow bool, logType string, handler func(line string)) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
@@ -254,41 +353,57 @@ func (c *Client) StreamLogs(slug string, tail int, follow bool, logType string,
 	if logType != "" {
 		query.Set("type", logType)
 	}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339Nano))
+	}
 	wsURL.RawQuery = query.Encode()
 
 	// Connect with auth header
 	header := http.Header{}
 	header.Set("Authorization", "Bearer "+c.token)
 
-	conn, resp, err := websocket.DefaultDialer.Dial(wsURL.String(), header)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL.String(), header)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if resp != nil && resp.StatusCode >= 400 {
 			data, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("API error %d: %s", resp.StatusCode, RedactToken(strings.TrimSpace(string(data))))
+			apiErr := newError(resp, data)
+			if resp.StatusCode >= 500 {
+				return fmt.Errorf("%w: %w", ErrLogStreamInterrupted, apiErr)
+			}
+			return apiErr
 		}
-		return fmt.Errorf("connecting to log stream: %w", err)
+		return fmt.Errorf("%w: connecting to log stream: %w", ErrLogStreamInterrupted, err)
 	}
 	defer conn.Close()
+	// Unblock ReadMessage when ctx is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
 
 	// Read messages until connection closes
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
 				return nil
 			}
-			return err
+			return fmt.Errorf("%w: %w", ErrLogStreamInterrupted, err)
 		}
 		handler(string(message))
 	}
 }
 
 // GetEnvVars returns environment variables for an app.
-func (c *Client) GetEnvVars(slug string) ([]EnvVar, error) {
+func (c *Client) GetEnvVars(ctx context.Context, slug string) ([]EnvVar, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/env", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/env", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -302,12 +417,12 @@ func (c *Client) GetEnvVars(slug string) ([]EnvVar, error) {
 }
 
 // SetEnvVar sets an environment variable on an app.
-func (c *Client) SetEnvVar(slug, key, value string) error {
+func (c *Client) SetEnvVar(ctx context.Context, slug, key, value string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
 	body := fmt.Sprintf(`{"key":%q,"value":%q}`, key, value)
-	resp, err := c.do("POST", "/apps/"+slug+"/env", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/env", strings.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -315,15 +430,59 @@ func (c *Client) SetEnvVar(slug, key, value string) error {
 	return nil
 }
 
+// SetEnvVars applies a batch of env var changes in a single request: every
+// key in vars is set and every key in unset is removed. The server applies
+// the batch atomically, so on error nothing has changed.
+func (c *Client) SetEnvVars(ctx context.Context, slug string, vars map[string]string, unset []string) (*EnvUpdateResult, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	for key := range vars {
+		if err := ValidateEnvKey(key); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range unset {
+		if err := ValidateEnvKey(key); err != nil {
+			return nil, err
+		}
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	if unset == nil {
+		unset = []string{}
+	}
+
+	body, err := json.Marshal(struct {
+		Set   map[string]string `json:"set"`
+		Unset []string          `json:"unset"`
+	}{vars, unset})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+	resp, err := c.do(ctx, "PATCH", "/apps/"+slug+"/env", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result EnvUpdateResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &result, nil
+}
+
 // UnsetEnvVar removes an environment variable from an app.
-func (c *Client) UnsetEnvVar(slug, key string) error {
+func (c *Client) UnsetEnvVar(ctx context.Context, slug, key string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
 	if err := ValidateEnvKey(key); err != nil {
 		return err
 	}
-	resp, err := c.do("DELETE", "/apps/"+slug+"/env/"+key, nil)
+	resp, err := c.do(ctx, "DELETE", "/apps/"+slug+"/env/"+key, nil)
 	if err != nil {
 		return err
 	}
@@ -332,11 +491,11 @@ func (c *Client) UnsetEnvVar(slug, key string) error {
 }
 
 // RestartApp restarts the specified app.
-func (c *Client) RestartApp(slug string) error {
+func (c *Client) RestartApp(ctx context.Context, slug string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
-	resp, err := c.do("POST", "/apps/"+slug+"/restart", nil)
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/restart", nil)
 	if err != nil {
 		return err
 	}
@@ -344,12 +503,90 @@ func (c *Client) RestartApp(slug string) error {
 	return nil
 }
 
+// waitPollInterval is how often RestartAndWait polls the app status.
+var waitPollInterval = 2 * time.Second
+
+// restartSettlePolls is how many polls in a row an unchanged app must report
+// "running" before RestartAndWait takes the restart as already done.
+const restartSettlePolls = 5
+
+// RestartAndWait restarts the app and waits until the restarted instance
+// reports "running", or until timeout.
+func (c *Client) RestartAndWait(ctx context.Context, slug string, timeout time.Duration) error {
+	before, err := c.GetApp(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if err := c.RestartApp(ctx, slug); err != nil {
+		return err
+	}
+	return c.waitRestarted(ctx, slug, before, timeout)
+}
+
+// waitRestarted polls the app until it has moved on from before, by
+// changing status or updated_at, and then reports "running". Right after a
+// restart the old instance still reports "running", and "stopped" is
+// expected while it is replaced; only "crashed" or "failed" after the
+// restart took effect end the wait early.
+//
+// The API exposes no instance ID or restart time, so this assumes that a
+// restart shows up as a change of status or updated_at. An egg that
+// restarts between two polls without bumping updated_at looks unchanged;
+// once it has reported "running" for restartSettlePolls polls in a row,
+// the restart is taken as done rather than waiting for the timeout.
+func (c *Client) waitRestarted(ctx context.Context, slug string, before *App, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	moved := false
+	settled := 0
+	for {
+		app, err := c.GetApp(ctx, slug)
+		if err != nil {
+			return err
+		}
+		if app.Status != before.Status || !app.UpdatedAt.Equal(before.UpdatedAt) {
+			moved = true
+		}
+		if !moved && app.Status == "running" {
+			settled++
+			if settled >= restartSettlePolls {
+				return nil
+			}
+		}
+		if moved {
+			switch app.Status {
+			case "running":
+				return nil
+			case "crashed", "failed":
+				return fmt.Errorf("%s is %s", slug, app.Status)
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s to be running again (status: %s)", timeout, slug, app.Status)
+		}
+		if err := sleep(ctx, waitPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d, or returns ctx's error as soon as ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 // DeleteApp permanently deletes the specified app.
-func (c *Client) DeleteApp(slug string) error {
+func (c *Client) DeleteApp(ctx context.Context, slug string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
-	resp, err := c.do("DELETE", "/apps/"+slug, nil)
+	resp, err := c.do(ctx, "DELETE", "/apps/"+slug, nil)
 	if err != nil {
 		return err
 	}
@@ -358,12 +595,12 @@ func (c *Client) DeleteApp(slug string) error {
 }
 
 // AddAddon provisions an addon (e.g. "postgresql", "s3") for an app.
-func (c *Client) AddAddon(slug, addonType string) (*Addon, error) {
+func (c *Client) AddAddon(ctx context.Context, slug, addonType string) (*Addon, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
 	body := fmt.Sprintf(`{"type":%q}`, addonType)
-	resp, err := c.do("POST", "/apps/"+slug+"/addons", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/addons", strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -377,11 +614,11 @@ func (c *Client) AddAddon(slug, addonType string) (*Addon, error) {
 }
 
 // ListAddons returns addons for an app (includes usage stats for postgresql).
-func (c *Client) ListAddons(slug string) ([]Addon, error) {
+func (c *Client) ListAddons(ctx context.Context, slug string) ([]Addon, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/addons", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/addons", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -395,11 +632,11 @@ func (c *Client) ListAddons(slug string) ([]Addon, error) {
 }
 
 // GetDatabaseURL returns the DATABASE_URL for an app's provisioned database.
-func (c *Client) GetDatabaseURL(slug string) (string, error) {
+func (c *Client) GetDatabaseURL(ctx context.Context, slug string) (string, error) {
 	if err := validateSlug(slug); err != nil {
 		return "", err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/addons/database", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/addons/database", nil)
 	if err != nil {
 		return "", err
 	}
@@ -415,11 +652,11 @@ func (c *Client) GetDatabaseURL(slug string) (string, error) {
 }
 
 // GetRedisURL returns the REDIS_URL for an app's Redis sidecar addon.
-func (c *Client) GetRedisURL(slug string) (string, string, error) {
+func (c *Client) GetRedisURL(ctx context.Context, slug string) (string, string, error) {
 	if err := validateSlug(slug); err != nil {
 		return "", "", err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/addons/redis", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/addons/redis", nil)
 	if err != nil {
 		return "", "", err
 	}
@@ -436,11 +673,11 @@ func (c *Client) GetRedisURL(slug string) (string, string, error) {
 }
 
 // ListDomains returns custom domains for an app.
-func (c *Client) ListDomains(slug string) ([]Domain, error) {
+func (c *Client) ListDomains(ctx context.Context, slug string) ([]Domain, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/domains", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/domains", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -454,12 +691,12 @@ func (c *Client) ListDomains(slug string) ([]Domain, error) {
 }
 
 // AddDomain configures a custom domain for an app.
-func (c *Client) AddDomain(slug, domain string) (*Domain, error) {
+func (c *Client) AddDomain(ctx context.Context, slug, domain string) (*Domain, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
 	body := fmt.Sprintf(`{"domain":%q}`, domain)
-	resp, err := c.do("POST", "/apps/"+slug+"/domains", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/domains", strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -473,14 +710,14 @@ func (c *Client) AddDomain(slug, domain string) (*Domain, error) {
 }
 
 // RemoveDomain removes a custom domain from an app.
-func (c *Client) RemoveDomain(slug, domain string) error {
+func (c *Client) RemoveDomain(ctx context.Context, slug, domain string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
 	if err := ValidateDomain(domain); err != nil {
 		return err
 	}
-	resp, err := c.do("DELETE", "/apps/"+slug+"/domains/"+domain, nil)
+	resp, err := c.do(ctx, "DELETE", "/apps/"+slug+"/domains/"+domain, nil)
 	if err != nil {
 		return err
 	}
@@ -489,14 +726,14 @@ func (c *Client) RemoveDomain(slug, domain string) error {
 }
 
 // VerifyDomain triggers verification of a custom domain.
-func (c *Client) VerifyDomain(slug, domain string) (*Domain, error) {
+func (c *Client) VerifyDomain(ctx context.Context, slug, domain string) (*Domain, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
 	if err := ValidateDomain(domain); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("POST", "/apps/"+slug+"/domains/"+domain+"/verify", nil)
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/domains/"+domain+"/verify", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -510,7 +747,7 @@ func (c *Client) VerifyDomain(slug, domain string) (*Domain, error) {
 }
 
 // GetLogs returns recent log lines (non-streaming).
-func (c *Client) GetLogs(slug string, tail int, logType string) ([]string, error) {
+func (c *Client) GetLogs(ctx context.Context, slug string, tail int, logType string) ([]string, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
@@ -518,7 +755,7 @@ func (c *Client) GetLogs(slug string, tail int, logType string) ([]string, error
 	if logType != "" {
 		path += "&type=" + logType
 	}
-	resp, err := c.do("GET", path, nil)
+	resp, err := c.do(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -533,18 +770,164 @@ func (c *Client) GetLogs(slug string, tail int, logType string) ([]string, error
 	return result.Lines, nil
 }
 
-// UploadArtifact uploads a pre-built tar.gz artifact for deployment.
-func (c *Client) UploadArtifact(slug string, artifact io.Reader, runtime, startCommand string) error {
+// QueryLogs returns one page of historical log lines, oldest first.
+func (c *Client) QueryLogs(ctx context.Context, slug string, q LogQuery) (*LogPage, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if !q.Since.IsZero() {
+		query.Set("since", q.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !q.Until.IsZero() {
+		query.Set("until", q.Until.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Query != "" {
+		query.Set("q", q.Query)
+	}
+	if q.Type != "" {
+		query.Set("type", q.Type)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", fmt.Sprintf("%d", q.Limit))
+	}
+	if q.Cursor != "" {
+		query.Set("cursor", q.Cursor)
+	}
+	path := fmt.Sprintf("/apps/%s/logs/query", slug)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	resp, err := c.do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page LogPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("parsing log response: %w", err)
+	}
+	return &page, nil
+}
+
+// SkipRemainingLogs can be returned by a WalkLogs callback to stop early
+// without an error.
+var SkipRemainingLogs = errors.New("skip remaining log pages")
+
+// WalkLogs pages through a log query, calling fn for each line in order. If
+// fn returns SkipRemainingLogs, WalkLogs stops and returns nil; any other
+// error stops it and is returned.
+func (c *Client) WalkLogs(ctx context.Context, slug string, q LogQuery, fn func(LogEntry) error) error {
+	for {
+		page, err := c.QueryLogs(ctx, slug, q)
+		if err != nil {
+			return err
+		}
+		for _, e := range page.Entries {
+			if err := fn(e); err != nil {
+				if errors.Is(err, SkipRemainingLogs) {
+					return nil
+				}
+				return err
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == q.Cursor {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// ListDeployments returns an app's deployments, newest first.
+func (c *Client) ListDeployments(ctx context.Context, slug string) ([]Deployment, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/deployments", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var deployments []Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&deployments); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].CreatedAt.After(deployments[j].CreatedAt)
+	})
+	return deployments, nil
+}
+
+// GetDeployment returns one deployment of an app. The ID "latest" selects
+// the most recent one.
+func (c *Client) GetDeployment(ctx context.Context, slug, id string) (*Deployment, error) {
+	if id == "latest" {
+		deployments, err := c.ListDeployments(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		if len(deployments) == 0 {
+			return nil, fmt.Errorf("%s has no deployments", slug)
+		}
+		return &deployments[0], nil
+	}
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	if !deploymentIDRegex.MatchString(id) {
+		return nil, fmt.Errorf("invalid deployment ID %q", id)
+	}
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/deployments/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var d Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &d, nil
+}
+
+// GetBuildLogs returns the complete build log of one deployment.
+func (c *Client) GetBuildLogs(ctx context.Context, slug, deploymentID string) ([]string, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	if !deploymentIDRegex.MatchString(deploymentID) {
+		return nil, fmt.Errorf("invalid deployment ID %q", deploymentID)
+	}
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/deployments/"+deploymentID+"/build-logs", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Lines []string `json:"lines"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parsing log response: %w", err)
+	}
+	return result.Lines, nil
+}
+
+// UploadArtifact uploads a pre-built tar archive for deployment, compressed
+// as described by format.
+func (c *Client) UploadArtifact(ctx context.Context, slug string, artifact io.Reader, format ArtifactFormat, runtime, startCommand string) error {
 	if err := validateSlug(slug); err != nil {
 		return err
 	}
 	url := c.host + apiPath + "/apps/" + slug + "/artifact"
-	req, err := http.NewRequest("POST", url, artifact)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, artifact)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("Content-Type", format.ContentType())
 
 	// hatch-api expects metadata as a single JSON header
 	metadata := struct {
@@ -572,9 +955,12 @@ func (c *Client) UploadArtifact(slug string, artifact io.Reader, runtime, startC
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnsupportedMediaType {
+		return fmt.Errorf("%w: %s", ErrUnsupportedArtifactFormat, format)
+	}
 	if resp.StatusCode >= 400 {
 		data, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed (%d): %s", resp.StatusCode, RedactToken(strings.TrimSpace(string(data))))
+		return fmt.Errorf("upload failed: %w", newError(resp, data))
 	}
 	return nil
 }
@@ -585,8 +971,8 @@ func isTimeoutError(err error) bool {
 }
 
 // ListKeys returns API keys for the authenticated user.
-func (c *Client) ListKeys() ([]APIKey, error) {
-	resp, err := c.do("GET", "/keys", nil)
+func (c *Client) ListKeys(ctx context.Context) ([]APIKey, error) {
+	resp, err := c.do(ctx, "GET", "/keys", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -601,11 +987,11 @@ func (c *Client) ListKeys() ([]APIKey, error) {
 
 // GetAppStatus returns the raw JSON status response for an app.
 // This includes app info, last deployment status, and custom domains.
-func (c *Client) GetAppStatus(slug string) (json.RawMessage, error) {
+func (c *Client) GetAppStatus(ctx context.Context, slug string) (json.RawMessage, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/status", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -660,8 +1046,8 @@ type BoostCheckoutResponse struct {
 }
 
 // GetAccountEnergy returns the user's account energy status.
-func (c *Client) GetAccountEnergy() (*EnergyStatus, error) {
-	resp, err := c.do("GET", "/account/energy", nil)
+func (c *Client) GetAccountEnergy(ctx context.Context) (*EnergyStatus, error) {
+	resp, err := c.do(ctx, "GET", "/account/energy", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -675,11 +1061,11 @@ func (c *Client) GetAccountEnergy() (*EnergyStatus, error) {
 }
 
 // GetAppEnergy returns energy status for a specific app.
-func (c *Client) GetAppEnergy(slug string) (*AppEnergy, error) {
+func (c *Client) GetAppEnergy(ctx context.Context, slug string) (*AppEnergy, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
-	resp, err := c.do("GET", "/apps/"+slug+"/energy", nil)
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/energy", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -694,12 +1080,12 @@ func (c *Client) GetAppEnergy(slug string) (*AppEnergy, error) {
 
 // BoostCheckout creates a Stripe checkout session for boost purchase.
 // Returns a checkout URL to open in browser.
-func (c *Client) BoostCheckout(slug, duration string) (*BoostCheckoutResponse, error) {
+func (c *Client) BoostCheckout(ctx context.Context, slug, duration string) (*BoostCheckoutResponse, error) {
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
 	body := fmt.Sprintf(`{"egg_slug":%q,"duration":%q}`, slug, duration)
-	resp, err := c.do("POST", "/billing/boost-checkout", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/billing/boost-checkout", strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -713,8 +1099,8 @@ func (c *Client) BoostCheckout(slug, duration string) (*BoostCheckoutResponse, e
 }
 
 // ListBoostCredits returns the user's available boost credits.
-func (c *Client) ListBoostCredits() (*BoostCreditsResponse, error) {
-	resp, err := c.do("GET", "/boost-credits", nil)
+func (c *Client) ListBoostCredits(ctx context.Context) (*BoostCreditsResponse, error) {
+	resp, err := c.do(ctx, "GET", "/boost-credits", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -728,12 +1114,12 @@ func (c *Client) ListBoostCredits() (*BoostCreditsResponse, error) {
 }
 
 // RedeemBoostCredit applies a boost credit to an egg.
-func (c *Client) RedeemBoostCredit(creditID, eggSlug string) (*RedeemCreditResponse, error) {
+func (c *Client) RedeemBoostCredit(ctx context.Context, creditID, eggSlug string) (*RedeemCreditResponse, error) {
 	if err := validateSlug(eggSlug); err != nil {
 		return nil, err
 	}
 	body := fmt.Sprintf(`{"egg_slug":%q}`, eggSlug)
-	resp, err := c.do("POST", "/boost-credits/"+creditID+"/redeem", strings.NewReader(body))
+	resp, err := c.do(ctx, "POST", "/boost-credits/"+creditID+"/redeem", strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
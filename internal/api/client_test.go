@@ -2,9 +2,13 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -48,18 +52,48 @@ func TestUploadArtifact_UsesExtendedTimeout(t *testing.T) {
 	c.host = server.URL
 	c.httpClient.Timeout = 1 * time.Millisecond
 
-	if err := c.UploadArtifact("myapp", bytes.NewReader([]byte("artifact")), "node", "node server.js"); err != nil {
+	if err := c.UploadArtifact(context.Background(), "myapp", bytes.NewReader([]byte("artifact")), ArtifactGzip, "node", "node server.js"); err != nil {
 		t.Fatalf("expected upload to succeed with extended timeout, got error: %v", err)
 	}
 }
 
+func TestUploadArtifact_ContentTypeFollowsFormat(t *testing.T) {
+	var gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	if err := c.UploadArtifact(context.Background(), "myapp", bytes.NewReader([]byte("artifact")), ArtifactZstd, "node", "node server.js"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotType != "application/zstd" {
+		t.Fatalf("expected Content-Type application/zstd, got %q", gotType)
+	}
+}
+
+func TestUploadArtifact_UnsupportedFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	err := c.UploadArtifact(context.Background(), "myapp", bytes.NewReader([]byte("artifact")), ArtifactZstd, "node", "node server.js")
+	if !errors.Is(err, ErrUnsupportedArtifactFormat) {
+		t.Fatalf("expected ErrUnsupportedArtifactFormat, got: %v", err)
+	}
+}
+
 func TestUploadArtifact_TimeoutErrorMessage(t *testing.T) {
 	c := NewClient("tok123")
 	c.httpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
 		return nil, timeoutError{}
 	})
 
-	err := c.UploadArtifact("myapp", bytes.NewReader([]byte("artifact")), "node", "node server.js")
+	err := c.UploadArtifact(context.Background(), "myapp", bytes.NewReader([]byte("artifact")), ArtifactGzip, "node", "node server.js")
 	if err == nil {
 		t.Fatal("expected timeout error")
 	}
@@ -86,7 +120,7 @@ func TestListApps(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	result, err := c.ListApps()
+	result, err := c.ListApps(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -118,7 +152,7 @@ func TestGetApp(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	result, err := c.GetApp("myapp")
+	result, err := c.GetApp(context.Background(), "myapp")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -140,7 +174,7 @@ func TestAPIError(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	_, err := c.GetApp("nonexistent")
+	_, err := c.GetApp(context.Background(), "nonexistent")
 	if err == nil {
 		t.Fatal("expected error for 404")
 	}
@@ -149,6 +183,69 @@ func TestAPIError(t *testing.T) {
 	}
 }
 
+func TestRestartAndWait(t *testing.T) {
+	old := waitPollInterval
+	waitPollInterval = time.Millisecond
+	defer func() { waitPollInterval = old }()
+
+	t0 := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	tests := []struct {
+		name    string
+		apps    []App // the first is the app before the restart
+		timeout time.Duration
+		wantErr string
+	}{
+		{"old instance still running at first", []App{{Status: "running", UpdatedAt: t0}, {Status: "running", UpdatedAt: t0}, {Status: "running", UpdatedAt: t0}, {Status: "deploying", UpdatedAt: t1}, {Status: "running", UpdatedAt: t1}}, time.Second, ""},
+		{"updated_at changes", []App{{Status: "running", UpdatedAt: t0}, {Status: "running", UpdatedAt: t1}}, time.Second, ""},
+		{"stopped while replaced", []App{{Status: "running", UpdatedAt: t0}, {Status: "stopped", UpdatedAt: t0}, {Status: "running", UpdatedAt: t0}}, time.Second, ""},
+		{"was crashed before", []App{{Status: "crashed", UpdatedAt: t0}, {Status: "crashed", UpdatedAt: t0}, {Status: "deploying", UpdatedAt: t1}, {Status: "running", UpdatedAt: t1}}, time.Second, ""},
+		{"crashes after the restart", []App{{Status: "running", UpdatedAt: t0}, {Status: "deploying", UpdatedAt: t1}, {Status: "crashed", UpdatedAt: t1}}, time.Second, "myapp is crashed"},
+		{"restarts between polls without bumping updated_at", slices.Repeat([]App{{Status: "running", UpdatedAt: t0}}, 1+restartSettlePolls), time.Second, ""},
+		{"never restarts", []App{{Status: "running", UpdatedAt: t0}}, 0, "timed out"},
+		{"never comes back up", []App{{Status: "stopped", UpdatedAt: t0}}, 50 * time.Millisecond, "timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls, restarted := 0, false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					if polls != 1 {
+						t.Errorf("restart requested after %d polls, want 1", polls)
+					}
+					restarted = true
+					return
+				}
+				app := tt.apps[min(polls, len(tt.apps)-1)]
+				app.Slug = "myapp"
+				polls++
+				json.NewEncoder(w).Encode(app)
+			}))
+			defer server.Close()
+
+			c := NewClient("tok123")
+			c.host = server.URL
+
+			err := c.RestartAndWait(context.Background(), "myapp", tt.timeout)
+			if !restarted {
+				t.Error("expected a restart request")
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if polls != len(tt.apps) {
+					t.Errorf("expected %d polls, got %d", len(tt.apps), polls)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestRestartApp(t *testing.T) {
 	called := false
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -166,7 +263,7 @@ func TestRestartApp(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	err := c.RestartApp("myapp")
+	err := c.RestartApp(context.Background(), "myapp")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -190,7 +287,7 @@ func TestDeleteApp(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	err := c.DeleteApp("myapp")
+	err := c.DeleteApp(context.Background(), "myapp")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -209,7 +306,7 @@ func TestGetEnvVars(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	result, err := c.GetEnvVars("myapp")
+	result, err := c.GetEnvVars(context.Background(), "myapp")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -236,10 +333,53 @@ func TestSetEnvVar(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	err := c.SetEnvVar("myapp", "PORT", "8080")
+	err := c.SetEnvVar(context.Background(), "myapp", "PORT", "8080")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetEnvVars(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Fatalf("expected PATCH, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/apps/myapp/env" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		var body struct {
+			Set   map[string]string `json:"set"`
+			Unset []string          `json:"unset"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Set["PORT"] != "8080" || len(body.Unset) != 1 || body.Unset[0] != "OLD" {
+			t.Fatalf("unexpected body: %+v", body)
+		}
+		json.NewEncoder(w).Encode(EnvUpdateResult{Updated: []string{"PORT"}, Removed: []string{"OLD"}})
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	result, err := c.SetEnvVars(context.Background(), "myapp", map[string]string{"PORT": "8080"}, []string{"OLD"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if result.Changed() != 2 || result.Updated[0] != "PORT" || result.Removed[0] != "OLD" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestSetEnvVars_InvalidKeySendsNothing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request expected for an invalid key")
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	_, err := c.SetEnvVars(context.Background(), "myapp", map[string]string{"GOOD": "1", "BAD-KEY": "2"}, nil)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
 }
 
 func TestUnsetEnvVar(t *testing.T) {
@@ -257,7 +397,7 @@ func TestUnsetEnvVar(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	err := c.UnsetEnvVar("myapp", "PORT")
+	err := c.UnsetEnvVar(context.Background(), "myapp", "PORT")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -289,7 +429,7 @@ func TestStreamLogs(t *testing.T) {
 	c.host = server.URL
 
 	var lines []string
-	err := c.StreamLogs("myapp", 50, false, "", func(line string) {
+	err := c.StreamLogs(context.Background(), "myapp", 50, false, "", func(line string) {
 		lines = append(lines, line)
 	})
 	if err != nil {
@@ -325,7 +465,7 @@ func TestStreamLogs_BuildLogs(t *testing.T) {
 	c.host = server.URL
 
 	var lines []string
-	err := c.StreamLogs("myapp", 100, true, "build", func(line string) {
+	err := c.StreamLogs(context.Background(), "myapp", 100, true, "build", func(line string) {
 		lines = append(lines, line)
 	})
 	if err != nil {
@@ -336,6 +476,227 @@ func TestStreamLogs_BuildLogs(t *testing.T) {
 	}
 }
 
+func TestStreamLogsSince(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("since"); got != "2025-06-15T10:00:02.5Z" {
+			t.Errorf("expected since=2025-06-15T10:00:02.5Z, got %q", got)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Fatalf("upgrade failed: %v", err)
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte("line one"))
+		// Drop the connection without a close frame, like a sleeping egg.
+		conn.Close()
+	}))
+	defer server.Close()
+
+	c := NewClient("tok123")
+	c.host = server.URL
+
+	since := time.Date(2025, 6, 15, 12, 0, 2, 500_000_000, time.FixedZone("CEST", 2*60*60))
+	var lines []string
+	err := c.StreamLogsSince(context.Background(), "myapp", since, 100, true, "", func(line string) {
+		lines = append(lines, line)
+	})
+	if !errors.Is(err, ErrLogStreamInterrupted) {
+		t.Fatalf("expected ErrLogStreamInterrupted, got %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+}
+
+func TestStreamLogsSince_Cancelled(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("line one"))
+		<-release
+	}))
+	defer server.Close()
+
+	c := NewClient("tok123")
+	c.host = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.StreamLogsSince(ctx, "myapp", time.Time{}, 100, true, "", func(string) { cancel() })
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after the context was cancelled")
+	}
+}
+
+func TestRestartAndWait_Deadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			json.NewEncoder(w).Encode(App{Slug: "myapp", Status: "running"})
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("tok123")
+	c.host = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := c.RestartAndWait(ctx, "myapp", time.Minute)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("RestartAndWait took %s to notice the deadline", time.Since(start))
+	}
+}
+
+func TestStreamLogs_DialErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		wantInterrupted bool
+	}{
+		{"server error is retryable", http.StatusBadGateway, true},
+		{"client error is not", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", tt.status)
+			}))
+			defer server.Close()
+
+			c := NewClient("tok123")
+			c.host = server.URL
+
+			err := c.StreamLogs(context.Background(), "myapp", 100, true, "", func(string) {})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := errors.Is(err, ErrLogStreamInterrupted); got != tt.wantInterrupted {
+				t.Errorf("errors.Is(ErrLogStreamInterrupted) = %v, want %v (err: %v)", got, tt.wantInterrupted, err)
+			}
+		})
+	}
+}
+
+func TestWalkLogs(t *testing.T) {
+	since := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/apps/myapp/logs/query" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("since") != "2025-06-15T10:00:00Z" || q.Get("until") != "2025-06-15T11:00:00Z" || q.Get("q") != "timeout" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		cursor := q.Get("cursor")
+		cursors = append(cursors, cursor)
+		page := LogPage{}
+		switch cursor {
+		case "":
+			page.Entries = []LogEntry{{Timestamp: since, Stream: "stdout", Message: "one"}, {Timestamp: since, Stream: "stderr", Message: "two"}}
+			page.NextCursor = "c2"
+		case "c2":
+			page.Entries = []LogEntry{{Timestamp: since, Stream: "stdout", Message: "three"}}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	c := NewClient("tok123")
+	c.host = server.URL
+	q := LogQuery{Since: since, Until: until, Query: "timeout"}
+
+	var got []string
+	err := c.WalkLogs(context.Background(), "myapp", q, func(e LogEntry) error {
+		got = append(got, e.Stream+":"+e.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"stdout:one", "stderr:two", "stdout:three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if want := []string{"", "c2"}; !reflect.DeepEqual(cursors, want) {
+		t.Errorf("cursors = %v, want %v", cursors, want)
+	}
+
+	cursors = nil
+	got = nil
+	err = c.WalkLogs(context.Background(), "myapp", q, func(e LogEntry) error {
+		got = append(got, e.Message)
+		return SkipRemainingLogs
+	})
+	if err != nil || len(got) != 1 || len(cursors) != 1 {
+		t.Errorf("expected SkipRemainingLogs to stop after one entry, got %v (err %v, %d pages)", got, err, len(cursors))
+	}
+}
+
+func TestDeploymentBuildLogs(t *testing.T) {
+	older := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/apps/myapp/deployments":
+			json.NewEncoder(w).Encode([]Deployment{
+				{ID: "d1", Status: "running", CreatedAt: older},
+				{ID: "d2", Status: "build_failed", CreatedAt: older.Add(time.Hour)},
+			})
+		case "/v1/apps/myapp/deployments/d1":
+			json.NewEncoder(w).Encode(Deployment{ID: "d1", Status: "running", CreatedAt: older})
+		case "/v1/apps/myapp/deployments/d2/build-logs":
+			w.Write([]byte(`{"lines":["Step 1/2","error: boom"]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("tok123")
+	c.host = server.URL
+
+	latest, err := c.GetDeployment(context.Background(), "myapp", "latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest.ID != "d2" || !latest.Failed() {
+		t.Errorf("expected the newest, failed deployment, got %+v", latest)
+	}
+	d1, err := c.GetDeployment(context.Background(), "myapp", "d1")
+	if err != nil || d1.ID != "d1" || d1.Failed() {
+		t.Errorf("unexpected deployment %+v (err %v)", d1, err)
+	}
+	lines, err := c.GetBuildLogs(context.Background(), "myapp", "d2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Step 1/2", "error: boom"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+	if _, err := c.GetBuildLogs(context.Background(), "myapp", "../env"); err == nil || !strings.Contains(err.Error(), "invalid deployment ID") {
+		t.Errorf("expected invalid deployment ID error, got: %v", err)
+	}
+}
+
 func TestCreateApp(t *testing.T) {
 	app := App{
 		Slug:   "myapp-j9ou",
@@ -356,7 +717,7 @@ func TestCreateApp(t *testing.T) {
 	c := NewClient("tok123")
 	c.host = server.URL
 
-	result, err := c.CreateApp("myapp")
+	result, err := c.CreateApp(context.Background(), "myapp")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
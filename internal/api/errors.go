@@ -0,0 +1,174 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Codes set on Error. The API sends its own codes for some failures; the
+// rest are derived from the HTTP status.
+const (
+	CodeBadRequest          = "bad_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeQuotaExceeded       = "quota_exceeded"
+	CodeRateLimited         = "rate_limited"
+	CodeUnavailable         = "unavailable"
+	CodeInternal            = "internal"
+	CodeNoRunningAllocation = "no_running_allocation"
+)
+
+// Error is returned for any API response with status 400 or above.
+type Error struct {
+	// Status is the HTTP status code.
+	Status int
+	// Code is a machine-readable reason, such as CodeNotFound.
+	Code string
+	// Message is the human-readable reason from the API, token-redacted.
+	Message string
+	// RequestID identifies the request in the API's logs, if it sent one.
+	RequestID string
+	// Retryable reports whether the same request may succeed later.
+	Retryable bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.Status, e.Message)
+}
+
+// errorBody is the JSON error shape the API uses, either flat or nested
+// under "error".
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// newError builds an Error from a failed response and its body.
+func newError(resp *http.Response, data []byte) *Error {
+	e := &Error{
+		Status:    resp.StatusCode,
+		Message:   RedactToken(strings.TrimSpace(string(data))),
+		RequestID: resp.Header.Get("X-Request-Id"),
+		Retryable: retryableStatus(resp.StatusCode),
+	}
+
+	var body struct {
+		errorBody
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		var nested errorBody
+		var msg string
+		switch {
+		case json.Unmarshal(body.Error, &msg) == nil:
+			body.Message = cmp.Or(body.Message, msg)
+		case json.Unmarshal(body.Error, &nested) == nil:
+			body.errorBody = nested
+		}
+		if body.Message != "" {
+			e.Message = RedactToken(body.Message)
+		}
+		e.Code = body.Code
+		e.RequestID = cmp.Or(body.RequestID, e.RequestID)
+	}
+
+	if e.Message == "" {
+		e.Message = strings.ToLower(http.StatusText(e.Status))
+	}
+	if e.Code == "" {
+		e.Code = codeFor(e.Status, e.Message)
+	}
+	// A 429 for an exhausted quota will not clear by waiting.
+	if e.Code == CodeQuotaExceeded {
+		e.Retryable = false
+	}
+	return e
+}
+
+// codeFor derives a code for a response that did not carry one.
+func codeFor(status int, msg string) string {
+	// The logs endpoints report a crashed egg as a plain-text 500.
+	if strings.Contains(strings.ToLower(msg), "no running allocation") {
+		return CodeNoRunningAllocation
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPaymentRequired:
+		return CodeQuotaExceeded
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// AsError returns the *Error in err's chain, if any.
+func AsError(err error) (*Error, bool) {
+	var apiErr *Error
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+func hasCode(err error, code string, status int) bool {
+	apiErr, ok := AsError(err)
+	return ok && (apiErr.Code == code || apiErr.Status == status)
+}
+
+// IsNotFound reports whether err is an API 404.
+func IsNotFound(err error) bool {
+	return hasCode(err, CodeNotFound, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether the API rejected the token.
+func IsUnauthorized(err error) bool {
+	return hasCode(err, CodeUnauthorized, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether the token may not perform the request.
+func IsForbidden(err error) bool {
+	return hasCode(err, CodeForbidden, http.StatusForbidden)
+}
+
+// IsQuotaExceeded reports whether the request needs more energy, eggs or
+// storage than the account has left.
+func IsQuotaExceeded(err error) bool {
+	return hasCode(err, CodeQuotaExceeded, http.StatusPaymentRequired)
+}
+
+// IsRateLimited reports whether the API asked the client to slow down.
+func IsRateLimited(err error) bool {
+	apiErr, ok := AsError(err)
+	return ok && apiErr.Code == CodeRateLimited
+}
+
+// IsNoRunningAllocation reports whether the egg has no running instance,
+// usually because it crashed during startup.
+func IsNoRunningAllocation(err error) bool {
+	apiErr, ok := AsError(err)
+	return ok && apiErr.Code == CodeNoRunningAllocation
+}
+
+// IsRetryable reports whether err is an API error that may go away if the
+// request is repeated later.
+func IsRetryable(err error) bool {
+	apiErr, ok := AsError(err)
+	return ok && apiErr.Retryable
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		requestID     string
+		body          string
+		wantCode      string
+		wantMessage   string
+		wantRequestID string
+		wantRetryable bool
+	}{
+		{"plain text", 404, "", "app not found", CodeNotFound, "app not found", "", false},
+		{"empty body", 503, "", "", CodeUnavailable, "service unavailable", "", true},
+		{"flat error string", 409, "", `{"error":"slug taken"}`, CodeConflict, "slug taken", "", false},
+		{"flat code and message", 429, "req-1", `{"code":"quota_exceeded","message":"out of energy"}`, CodeQuotaExceeded, "out of energy", "req-1", false},
+		{"nested error", 400, "", `{"error":{"code":"invalid_slug","message":"bad slug","request_id":"req-2"}}`, "invalid_slug", "bad slug", "req-2", false},
+		{"json without message", 500, "", `{"detail":"x"}`, CodeInternal, `{"detail":"x"}`, "", false},
+		{"no running allocation", 500, "", "no running allocation for myapp", CodeNoRunningAllocation, "no running allocation for myapp", "", false},
+		{"token redacted", 401, "", `{"error":"bad token hatch_secret123"}`, CodeUnauthorized, "bad token hatch_****", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.requestID != "" {
+				resp.Header.Set("X-Request-Id", tt.requestID)
+			}
+			e := newError(resp, []byte(tt.body))
+			if e.Status != tt.status || e.Code != tt.wantCode || e.Message != tt.wantMessage ||
+				e.RequestID != tt.wantRequestID || e.Retryable != tt.wantRetryable {
+				t.Errorf("got %+v", *e)
+			}
+		})
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(error) bool
+		want  bool
+	}{
+		{"not found", &Error{Status: 404, Code: CodeNotFound}, IsNotFound, true},
+		{"not found with API code", &Error{Status: 404, Code: "app_not_found"}, IsNotFound, true},
+		{"wrapped not found", fmt.Errorf("fetching app: %w", &Error{Status: 404, Code: CodeNotFound}), IsNotFound, true},
+		{"plain error", fmt.Errorf("API error 404: not found"), IsNotFound, false},
+		{"nil", nil, IsNotFound, false},
+		{"quota by code", &Error{Status: 429, Code: CodeQuotaExceeded}, IsQuotaExceeded, true},
+		{"quota by status", &Error{Status: 402, Code: "energy_exhausted"}, IsQuotaExceeded, true},
+		{"quota is not rate limiting", &Error{Status: 429, Code: CodeQuotaExceeded}, IsRateLimited, false},
+		{"rate limited", &Error{Status: 429, Code: CodeRateLimited}, IsRateLimited, true},
+		{"unauthorized", &Error{Status: 401, Code: CodeUnauthorized}, IsUnauthorized, true},
+		{"forbidden", &Error{Status: 403, Code: CodeForbidden}, IsForbidden, true},
+		{"no running allocation", &Error{Status: 500, Code: CodeNoRunningAllocation}, IsNoRunningAllocation, true},
+		{"retryable", &Error{Status: 503, Retryable: true}, IsRetryable, true},
+	}
+	for _, tt := range tests {
+		if got := tt.check(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDo_ReturnsTypedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"app not found"}`))
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	_, err := c.GetApp(context.Background(), "missing")
+	apiErr, ok := AsError(err)
+	if !ok {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if apiErr.Error() != "API error 404: app not found" || apiErr.RequestID != "req-42" || !IsNotFound(err) {
+		t.Errorf("unexpected error: %+v", *apiErr)
+	}
+}
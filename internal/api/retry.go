@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	mrand "math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried: timeouts, refused or reset connections, responses cut off
+// midway, and 408, 429, 502, 503 and 504.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried after the first
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry. It doubles on each
+	// retry, up to MaxDelay, and is jittered by ±50%.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited for; the error is returned instead.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// retryPolicy is the policy new clients start with.
+var retryPolicy = DefaultRetryPolicy
+
+// SetRetryPolicy sets the retry policy for clients created afterwards.
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicy = p
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryableError reports whether a failed attempt is worth retrying: a
+// timeout, a refused or reset connection, or a response cut off midway.
+// Anything else, such as a host that does not resolve or a certificate
+// that does not verify, will not fix itself within a few seconds.
+func retryableError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// delay returns how long to wait before the given retry (1-based). A
+// Retry-After from the server is used as is; ok is false when it asks for
+// longer than MaxDelay.
+func (p RetryPolicy) delay(retry int, retryAfter time.Duration) (d time.Duration, ok bool) {
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= p.MaxDelay
+	}
+	d = p.BaseDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	return d/2 + time.Duration(mrand.Int63n(int64(d))), true
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(h string, now time.Time) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(min(secs, math.MaxInt32)) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// newIdempotencyKey returns a random key that lets the API recognise a
+// retried mutating request and apply it only once.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		maxRetries int
+		wantCalls  int
+		wantErr    string
+	}{
+		{"bad gateway then ok", []int{502, 200}, "", 3, 2, ""},
+		{"rate limited then ok", []int{429, 429, 200}, "", 3, 3, ""},
+		{"gives up after max retries", []int{503}, "", 2, 3, "API error 503"},
+		{"not found is not retried", []int{404}, "", 3, 1, "API error 404"},
+		{"server error is not retried", []int{500}, "", 3, 1, "API error 500"},
+		{"retries disabled", []int{502, 200}, "", 0, 1, "API error 502"},
+		{"retry-after beyond max delay", []int{429, 200}, "120", 3, 1, "API error 429"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[min(calls, len(tt.statuses)-1)]
+				calls++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				if status == 200 {
+					io.WriteString(w, `[]`)
+				}
+			}))
+			defer server.Close()
+
+			c := NewTestClient("tok123", server.URL)
+			c.retry = RetryPolicy{MaxRetries: tt.maxRetries, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+			_, err := c.ListApps(context.Background())
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDoRetries_ConnectionError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// Close with SO_LINGER 0 so the client sees a connection reset.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+			return
+		}
+		io.WriteString(w, `[]`)
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	c.retry.BaseDelay = time.Millisecond
+	if _, err := c.ListApps(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"connection refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"response cut off", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), true},
+		{"dns timeout", &net.DNSError{Name: "api.example.com", IsTimeout: true}, true},
+		{"closed before responding", io.EOF, false},
+		{"unknown host", fmt.Errorf("dial tcp: %w", &net.DNSError{Name: "api.example.com", IsNotFound: true}), false},
+		{"bad certificate", &tls.CertificateVerificationError{Err: errors.New("unknown authority")}, false},
+	}
+	for _, tt := range tests {
+		if got := retryableError(tt.err); got != tt.want {
+			t.Errorf("%s: retryableError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDoRetries_IdempotencyKey(t *testing.T) {
+	var keys []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"slug":"myapp","name":"myapp"}`)
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	c.retry.BaseDelay = time.Millisecond
+	if _, err := c.CreateApp(context.Background(), "myapp"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("expected the same idempotency key on both attempts, got %q", keys)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("expected the body to be resent, got %q", bodies)
+	}
+
+	keys = nil
+	c.ListApps(context.Background())
+	for _, k := range keys {
+		if k != "" {
+			t.Errorf("GET should carry no idempotency key, got %q", keys)
+		}
+	}
+}
+
+func TestDoRetries_Verbose(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		io.WriteString(w, `[]`)
+	}))
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	c.retry.BaseDelay = time.Millisecond
+
+	r, w, _ := os.Pipe()
+	oldStderr := os.Stderr
+	os.Stderr = w
+	SetVerbose(true)
+	_, err := c.ListApps(context.Background())
+	SetVerbose(false)
+	os.Stderr = oldStderr
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), "* GET /apps failed (502 Bad Gateway), retry 1 of 3 in ") {
+		t.Errorf("expected a retry note in verbose output:\n%s", out)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"0", 0},
+		{"-1", 0},
+		{"soon", 0},
+		{"Sun, 15 Jun 2025 10:00:30 GMT", 30 * time.Second},
+		{"Sun, 15 Jun 2025 09:59:00 GMT", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for retry, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 8: time.Second} {
+		d, ok := p.delay(retry, 0)
+		if !ok || d < base/2 || d >= base*3/2 {
+			t.Errorf("delay(%d) = %s, want within [%s, %s)", retry, d, base/2, base*3/2)
+		}
+	}
+	if d, ok := p.delay(1, 2*time.Second); ok {
+		t.Errorf("Retry-After beyond MaxDelay should not be waited for, got %s", d)
+	}
+	if d, ok := p.delay(1, 500*time.Millisecond); !ok || d != 500*time.Millisecond {
+		t.Errorf("Retry-After should be used as is, got %s", d)
+	}
+}
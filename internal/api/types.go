@@ -1,6 +1,9 @@
 package api
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // App represents a deployed application on Hatch.
 type App struct {
@@ -21,16 +24,71 @@ type Deployment struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Failed reports whether the deployment's build or rollout failed.
+func (d *Deployment) Failed() bool {
+	s := strings.ToLower(d.Status)
+	return strings.Contains(s, "fail") || s == "error" || s == "errored"
+}
+
 // EnvVar represents an environment variable.
 type EnvVar struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
-// LogEntry represents a single log line from SSE streaming.
+// EnvUpdateResult reports the effect of a bulk env var update.
+type EnvUpdateResult struct {
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Removed   []string `json:"removed"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// Changed returns the number of keys that were added, updated, or removed.
+func (r *EnvUpdateResult) Changed() int {
+	return len(r.Added) + len(r.Updated) + len(r.Removed)
+}
+
+// ArtifactFormat identifies the compression used for an uploaded artifact.
+type ArtifactFormat string
+
+const (
+	ArtifactGzip ArtifactFormat = "gzip"
+	ArtifactZstd ArtifactFormat = "zstd"
+)
+
+// ContentType returns the MIME type sent with an artifact upload.
+func (f ArtifactFormat) ContentType() string {
+	if f == ArtifactZstd {
+		return "application/zstd"
+	}
+	return "application/gzip"
+}
+
+// LogEntry represents a single log line, as returned by QueryLogs.
 type LogEntry struct {
-	Timestamp string `json:"timestamp"`
-	Message   string `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"`
+	Message   string    `json:"message"`
+}
+
+// LogQuery selects historical log lines. Zero times leave that end of the
+// window open. Query, if set, is a case-insensitive substring the message
+// must contain; it is matched by the API.
+type LogQuery struct {
+	Since time.Time
+	Until time.Time
+	Query string
+	Type  string
+	// Limit is the page size; the API caps it. Zero uses the API default.
+	Limit  int
+	Cursor string
+}
+
+// LogPage is one page of a log query. NextCursor is empty on the last page.
+type LogPage struct {
+	Entries    []LogEntry `json:"entries"`
+	NextCursor string     `json:"next_cursor"`
 }
 
 // Addon represents a provisioned addon (database, storage, etc).
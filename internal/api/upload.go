@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultChunkSize is the chunk size requested when initiating an upload.
+	// The server may choose a different size in its response.
+	DefaultChunkSize = 8 << 20
+
+	chunkUploadTimeout = 5 * time.Minute
+)
+
+// ErrChunkedUploadUnsupported is returned by UploadArtifactChunked when the
+// API does not implement the chunked upload endpoints. Callers should fall
+// back to UploadArtifact.
+var ErrChunkedUploadUnsupported = errors.New("chunked uploads not supported by the API")
+
+// UploadSession describes a chunked artifact upload in progress.
+type UploadSession struct {
+	ID        string `json:"upload_id"`
+	ChunkSize int64  `json:"chunk_size"`
+	Received  []int  `json:"received_chunks"`
+}
+
+// ChunkedUploadOptions configures UploadArtifactChunked.
+type ChunkedUploadOptions struct {
+	// ResumeID continues an earlier upload session. If the server no longer
+	// knows the session, a new one is started.
+	ResumeID string
+	// OnSession is called once the upload ID is known, before any chunk is
+	// sent, so the caller can persist it for a later resume.
+	OnSession func(uploadID string)
+	// OnProgress is called after each chunk with the bytes confirmed so far.
+	OnProgress func(sent, total int64)
+}
+
+// UploadArtifactChunked uploads an artifact in numbered, checksummed chunks:
+// it initiates (or resumes) an upload session, PUTs every chunk the server
+// has not yet received, retrying each according to the client's retry
+// policy, then commits.
+func (c *Client) UploadArtifactChunked(ctx context.Context, slug string, artifact []byte, format ArtifactFormat, runtime, startCommand string, opts ChunkedUploadOptions) error {
+	if err := validateSlug(slug); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(artifact)
+	digest := hex.EncodeToString(sum[:])
+
+	var session *UploadSession
+	if opts.ResumeID != "" {
+		if s, err := c.GetUpload(ctx, slug, opts.ResumeID); err == nil {
+			session = s
+		}
+	}
+	if session == nil {
+		s, err := c.initiateUpload(ctx, slug, int64(len(artifact)), digest, format, runtime, startCommand)
+		if err != nil {
+			return err
+		}
+		session = s
+	}
+	if session.ChunkSize <= 0 {
+		session.ChunkSize = DefaultChunkSize
+	}
+	if opts.OnSession != nil {
+		opts.OnSession(session.ID)
+	}
+
+	received := make(map[int]bool, len(session.Received))
+	for _, n := range session.Received {
+		received[n] = true
+	}
+
+	total := int64(len(artifact))
+	var sent int64
+	for index, off := 0, int64(0); off < total; index, off = index+1, off+session.ChunkSize {
+		end := off + session.ChunkSize
+		if end > total {
+			end = total
+		}
+		if !received[index] {
+			if err := c.putChunk(ctx, slug, session.ID, index, artifact[off:end]); err != nil {
+				return err
+			}
+		}
+		sent = end
+		if opts.OnProgress != nil {
+			opts.OnProgress(sent, total)
+		}
+	}
+
+	return c.commitUpload(ctx, slug, session.ID, format)
+}
+
+// GetUpload returns the state of an upload session, including which chunks
+// the server has already received.
+func (c *Client) GetUpload(ctx context.Context, slug, uploadID string) (*UploadSession, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+	if err := validateUploadID(uploadID); err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, "GET", "/apps/"+slug+"/artifact/uploads/"+uploadID, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var session UploadSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if session.ID == "" {
+		session.ID = uploadID
+	}
+	return &session, nil
+}
+
+// initiateUpload starts a new chunked upload session. It is sent without
+// c.do so that a missing endpoint can be told apart from other errors.
+func (c *Client) initiateUpload(ctx context.Context, slug string, size int64, digest string, format ArtifactFormat, runtime, startCommand string) (*UploadSession, error) {
+	body, err := json.Marshal(struct {
+		Size         int64  `json:"size"`
+		SHA256       string `json:"sha256"`
+		ChunkSize    int64  `json:"chunk_size"`
+		ContentType  string `json:"content_type"`
+		Runtime      string `json:"runtime"`
+		StartCommand string `json:"startCommand"`
+	}{size, digest, DefaultChunkSize, format.ContentType(), runtime, startCommand})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling upload request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.host+apiPath+"/apps/"+slug+"/artifact/uploads", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, ErrChunkedUploadUnsupported
+	case http.StatusUnsupportedMediaType:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArtifactFormat, format)
+	}
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, newError(resp, data)
+	}
+
+	var session UploadSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if err := validateUploadID(session.ID); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// putChunk sends one chunk, retrying it according to the client's retry
+// policy. A checksum mismatch means the chunk was corrupted on the way, so
+// it is retried as well.
+func (c *Client) putChunk(ctx context.Context, slug, uploadID string, index int, chunk []byte) error {
+	sum := sha256.Sum256(chunk)
+	path := fmt.Sprintf("/apps/%s/artifact/uploads/%s/chunks/%d", slug, uploadID, index)
+	chunkClient := *c.httpClient
+	chunkClient.Timeout = chunkUploadTimeout
+
+	resp, err := c.withRetries(ctx, "PUT", path, func(status int) bool {
+		return status == http.StatusUnprocessableEntity
+	}, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "PUT", c.host+apiPath+path, bytes.NewReader(chunk))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Chunk-SHA256", hex.EncodeToString(sum[:]))
+		return chunkClient.Do(req)
+	})
+	if err != nil {
+		return fmt.Errorf("uploading chunk %d: %w", index, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// commitUpload finalizes an upload; the server verifies the full checksum
+// and starts the deployment. An API that only checks the artifact's format
+// once it has all of it rejects it here.
+func (c *Client) commitUpload(ctx context.Context, slug, uploadID string, format ArtifactFormat) error {
+	resp, err := c.do(ctx, "POST", "/apps/"+slug+"/artifact/uploads/"+uploadID+"/commit", nil)
+	if apiErr, ok := AsError(err); ok && apiErr.Status == http.StatusUnsupportedMediaType {
+		return fmt.Errorf("%w: %s", ErrUnsupportedArtifactFormat, format)
+	}
+	if err != nil {
+		return fmt.Errorf("committing upload: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// validateUploadID ensures server-issued upload IDs are safe for URL paths.
+func validateUploadID(id string) error {
+	if !slugRegex.MatchString(strings.ToLower(id)) {
+		return fmt.Errorf("invalid upload id %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUploadServer implements the chunked upload endpoints in memory.
+type fakeUploadServer struct {
+	mu        sync.Mutex
+	chunkSize int64
+	chunks    map[int][]byte
+	puts      []int
+	failNext  map[int]int // chunk index -> remaining 503 responses
+	committed bool
+	initiated int
+	meta      map[string]interface{}
+}
+
+func newFakeUploadServer(chunkSize int64) *fakeUploadServer {
+	return &fakeUploadServer{chunkSize: chunkSize, chunks: map[int][]byte{}, failNext: map[int]int{}}
+}
+
+func (f *fakeUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/apps/myapp/artifact/uploads")
+	switch {
+	case r.Method == "POST" && path == "":
+		f.initiated++
+		json.NewDecoder(r.Body).Decode(&f.meta)
+		json.NewEncoder(w).Encode(UploadSession{ID: "up-1", ChunkSize: f.chunkSize})
+	case r.Method == "GET" && path == "/up-1":
+		var received []int
+		for n := range f.chunks {
+			received = append(received, n)
+		}
+		json.NewEncoder(w).Encode(UploadSession{ID: "up-1", ChunkSize: f.chunkSize, Received: received})
+	case r.Method == "PUT" && strings.HasPrefix(path, "/up-1/chunks/"):
+		n, _ := strconv.Atoi(strings.TrimPrefix(path, "/up-1/chunks/"))
+		f.puts = append(f.puts, n)
+		if f.failNext[n] > 0 {
+			f.failNext[n]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(data)
+		if r.Header.Get("X-Chunk-SHA256") != hex.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		f.chunks[n] = data
+	case r.Method == "POST" && path == "/up-1/commit":
+		f.committed = true
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeUploadServer) assembled() []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(f.chunks); i++ {
+		buf.Write(f.chunks[i])
+	}
+	return buf.Bytes()
+}
+
+func TestUploadArtifactChunked_UploadsAllChunksAndCommits(t *testing.T) {
+	fake := newFakeUploadServer(4)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	artifact := []byte("0123456789")
+	var sessionID string
+	var progress []int64
+	c := NewTestClient("tok123", server.URL)
+	err := c.UploadArtifactChunked(context.Background(), "myapp", artifact, ArtifactZstd, "node", "node index.js", ChunkedUploadOptions{
+		OnSession:  func(id string) { sessionID = id },
+		OnProgress: func(sent, total int64) { progress = append(progress, sent) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sessionID != "up-1" {
+		t.Errorf("expected OnSession with up-1, got %q", sessionID)
+	}
+	if !fake.committed {
+		t.Error("expected upload to be committed")
+	}
+	if got := fake.assembled(); !bytes.Equal(got, artifact) {
+		t.Errorf("assembled artifact = %q, want %q", got, artifact)
+	}
+	if len(progress) != 3 || progress[2] != 10 {
+		t.Errorf("unexpected progress reports: %v", progress)
+	}
+	sum := sha256.Sum256(artifact)
+	if fake.meta["sha256"] != hex.EncodeToString(sum[:]) || fake.meta["content_type"] != "application/zstd" {
+		t.Errorf("unexpected initiate metadata: %v", fake.meta)
+	}
+}
+
+func TestUploadArtifactChunked_RetriesFailedChunk(t *testing.T) {
+	fake := newFakeUploadServer(4)
+	fake.failNext[1] = 2
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	c.retry.BaseDelay = time.Millisecond
+	if err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("0123456789"), ArtifactGzip, "node", "", ChunkedUploadOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	retries := 0
+	for _, n := range fake.puts {
+		if n == 1 {
+			retries++
+		}
+	}
+	if retries != 3 {
+		t.Errorf("expected chunk 1 to be sent 3 times, got %d (puts: %v)", retries, fake.puts)
+	}
+}
+
+func TestUploadArtifactChunked_GivesUpAfterMaxRetries(t *testing.T) {
+	fake := newFakeUploadServer(4)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	c.retry.BaseDelay = time.Millisecond
+	fake.failNext[0] = c.retry.MaxRetries + 1
+	err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("0123456789"), ArtifactGzip, "node", "", ChunkedUploadOptions{})
+	if err == nil || !strings.Contains(err.Error(), "uploading chunk 0") {
+		t.Fatalf("expected chunk 0 failure, got: %v", err)
+	}
+	if fake.committed {
+		t.Error("upload must not be committed after a failed chunk")
+	}
+}
+
+func TestUploadArtifactChunked_ResumesReceivedChunks(t *testing.T) {
+	fake := newFakeUploadServer(4)
+	fake.chunks[0] = []byte("0123")
+	fake.chunks[1] = []byte("4567")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("0123456789"), ArtifactGzip, "node", "", ChunkedUploadOptions{ResumeID: "up-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fake.initiated != 0 {
+		t.Errorf("resume should not initiate a new upload, initiated %d times", fake.initiated)
+	}
+	if len(fake.puts) != 1 || fake.puts[0] != 2 {
+		t.Errorf("expected only chunk 2 to be sent, got %v", fake.puts)
+	}
+	if !fake.committed {
+		t.Error("expected upload to be committed")
+	}
+}
+
+func TestUploadArtifactChunked_UnknownResumeIDStartsOver(t *testing.T) {
+	fake := newFakeUploadServer(4)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("0123456789"), ArtifactGzip, "node", "", ChunkedUploadOptions{ResumeID: "expired"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.initiated != 1 {
+		t.Errorf("expected a new upload to be initiated, got %d", fake.initiated)
+	}
+}
+
+func TestUploadArtifactChunked_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	c := NewTestClient("tok123", server.URL)
+	err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("data"), ArtifactGzip, "node", "", ChunkedUploadOptions{})
+	if !errors.Is(err, ErrChunkedUploadUnsupported) {
+		t.Fatalf("expected ErrChunkedUploadUnsupported, got: %v", err)
+	}
+}
+
+func TestUploadArtifactChunked_UnsupportedFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		rejectAt string
+	}{
+		{"on initiate", "POST /v1/apps/myapp/artifact/uploads"},
+		{"on commit", "POST /v1/apps/myapp/artifact/uploads/up-1/commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeUploadServer(4)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method+" "+r.URL.Path == tt.rejectAt {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			c := NewTestClient("tok123", server.URL)
+			err := c.UploadArtifactChunked(context.Background(), "myapp", []byte("0123456789"), ArtifactZstd, "node", "", ChunkedUploadOptions{})
+			if !errors.Is(err, ErrUnsupportedArtifactFormat) {
+				t.Fatalf("expected ErrUnsupportedArtifactFormat, got: %v", err)
+			}
+		})
+	}
+}
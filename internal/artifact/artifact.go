@@ -0,0 +1,313 @@
+// Package artifact builds the compressed tar archives that are uploaded
+// to Hatch from a deploy target. The CLI and the MCP server both use it, so
+// a deploy from either applies the same .hatchignore rules, symlink
+// handling and compression.
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/ignore"
+)
+
+// SymlinkMode controls how Create treats symlinks that point outside
+// the deploy target. Links that stay inside the target are always preserved.
+type SymlinkMode string
+
+const (
+	// SymlinksPreserve archives every link as-is, with its original target.
+	// Escaping links then resolve against the container's filesystem.
+	SymlinksPreserve SymlinkMode = "preserve"
+	// SymlinksFollow copies the contents of escaping links into the archive.
+	SymlinksFollow SymlinkMode = "follow"
+	// SymlinksError fails the build if any link escapes the deploy target.
+	SymlinksError SymlinkMode = "error"
+)
+
+// ParseSymlinkMode validates a --symlinks value. Empty means preserve.
+func ParseSymlinkMode(s string) (SymlinkMode, error) {
+	switch SymlinkMode(s) {
+	case "", SymlinksPreserve:
+		return SymlinksPreserve, nil
+	case SymlinksFollow, SymlinksError:
+		return SymlinkMode(s), nil
+	}
+	return "", fmt.Errorf("invalid --symlinks value %q (valid: preserve, follow, error)", s)
+}
+
+// Options configures how Create builds an artifact.
+type Options struct {
+	Symlinks SymlinkMode
+	Format   api.ArtifactFormat // gzip if empty
+	Level    int                // 0 selects the format's default level
+}
+
+// SymlinkEvent records a symlink that points outside the deploy target or
+// could not be archived as a plain link.
+type SymlinkEvent struct {
+	Path   string // path relative to the deploy target
+	Target string // link target as read from disk
+	Action string // "preserved", "skipped" or "followed"
+	Reason string
+}
+
+func (e SymlinkEvent) String() string {
+	s := fmt.Sprintf("%s -> %s (%s", e.Path, e.Target, e.Action)
+	if e.Reason != "" {
+		s += ": " + e.Reason
+	}
+	return s + ")"
+}
+
+// Report describes what Create left out or rewrote.
+type Report struct {
+	Excluded []string
+	Symlinks []SymlinkEvent
+}
+
+// tarBuilder walks a deploy target and writes matching entries to a tar stream.
+type tarBuilder struct {
+	tw          *tar.Writer
+	matcher     *ignore.Matcher
+	absDir      string
+	realDir     string // absDir with symlinks resolved, for cycle detection
+	opts        Options
+	report      *Report
+	excludedSet map[string]bool
+	escaping    []string // escaping links collected in SymlinksError mode
+}
+
+// Create builds a compressed tar archive of the given directory.
+// Compression runs in parallel across all CPUs using opts.Format.
+// Uses .hatchignore patterns if present, otherwise applies built-in defaults.
+// Returns the archive bytes and a report of excluded paths and of symlinks
+// that escape the target or were skipped or dereferenced.
+func Create(dir string, opts Options) ([]byte, *Report, error) {
+	if opts.Symlinks == "" {
+		opts.Symlinks = SymlinksPreserve
+	}
+
+	var buf bytes.Buffer
+	cw, err := newCompressor(&buf, opts.Format, opts.Level)
+	if err != nil {
+		return nil, nil, fmt.Errorf("initializing compressor: %w", err)
+	}
+	tw := tar.NewWriter(cw)
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving directory: %w", err)
+	}
+
+	// Load .hatchignore or use defaults
+	matcher, err := ignore.LoadFile(filepath.Join(dir, ".hatchignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			matcher = ignore.DefaultMatcher()
+		} else {
+			return nil, nil, fmt.Errorf("reading .hatchignore: %w", err)
+		}
+	}
+
+	realDir, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving directory: %w", err)
+	}
+
+	b := &tarBuilder{
+		tw:          tw,
+		matcher:     matcher,
+		absDir:      absDir,
+		realDir:     realDir,
+		opts:        opts,
+		report:      &Report{},
+		excludedSet: make(map[string]bool),
+	}
+
+	if err := b.addTree(dir, "", nil); err != nil {
+		return nil, nil, err
+	}
+
+	if len(b.escaping) > 0 {
+		return nil, nil, fmt.Errorf("symlinks point outside the deploy target (use --symlinks=follow to copy their contents, or --symlinks=preserve to keep them as links):\n  %s",
+			strings.Join(b.escaping, "\n  "))
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, nil, err
+	}
+	if err := cw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	// Check artifact size
+	if buf.Len() > 500*1024*1024 {
+		return nil, nil, fmt.Errorf("artifact too large (%.0f MB, max 500 MB)", float64(buf.Len())/1024/1024)
+	}
+
+	return buf.Bytes(), b.report, nil
+}
+
+// addTree archives the contents of root under the archive path prefix.
+// ancestors holds the resolved paths of directories reached by following
+// symlinks, so that a link back into one of them is detected as a cycle.
+// It is nil for the deploy target itself.
+func (b *tarBuilder) addTree(root, prefix string, ancestors map[string]bool) error {
+	followed := ancestors != nil
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Never skip the root directory itself (even if deploy-target starts with ".")
+		if path == root {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.Join(prefix, rel)
+
+		if b.matcher.ShouldExclude(rel, info.IsDir()) {
+			label := rel
+			if info.IsDir() {
+				label += "/"
+			}
+			if !b.excludedSet[label] {
+				b.excludedSet[label] = true
+				b.report.Excluded = append(b.report.Excluded, label)
+			}
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Handle symlinks
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			target := link
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			target, err = filepath.Abs(target)
+			if err != nil {
+				return err
+			}
+
+			// Links inside followed trees have no stable place in the
+			// archive, so they are always dereferenced.
+			inside := strings.HasPrefix(target, b.absDir+string(filepath.Separator)) || target == b.absDir
+			if inside && !followed {
+				return b.writeEntry(path, rel, info, link)
+			}
+
+			switch b.opts.Symlinks {
+			case SymlinksError:
+				b.escaping = append(b.escaping, rel+" -> "+link)
+				return nil
+			case SymlinksFollow:
+				return b.follow(path, rel, link, ancestors)
+			default:
+				b.report.Symlinks = append(b.report.Symlinks, SymlinkEvent{
+					Path: rel, Target: link, Action: "preserved", Reason: "points outside deploy target",
+				})
+				return b.writeEntry(path, rel, info, link)
+			}
+		}
+
+		// Sockets, pipes and devices cannot be archived and are no use
+		// in a container anyway.
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		return b.writeEntry(path, rel, info, "")
+	})
+}
+
+// follow dereferences the symlink at path and archives its target under rel.
+func (b *tarBuilder) follow(path, rel, link string, ancestors map[string]bool) error {
+	skip := func(reason string) error {
+		b.report.Symlinks = append(b.report.Symlinks, SymlinkEvent{
+			Path: rel, Target: link, Action: "skipped", Reason: reason,
+		})
+		return nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return skip("broken link")
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return skip("broken link")
+	}
+
+	if !info.IsDir() {
+		if !info.Mode().IsRegular() {
+			return skip("not a regular file or directory")
+		}
+		b.report.Symlinks = append(b.report.Symlinks, SymlinkEvent{Path: rel, Target: link, Action: "followed"})
+		return b.writeEntry(resolved, rel, info, "")
+	}
+
+	if resolved == b.realDir || strings.HasPrefix(b.realDir, resolved+string(filepath.Separator)) || ancestors[resolved] {
+		return skip("cycle detected")
+	}
+
+	b.report.Symlinks = append(b.report.Symlinks, SymlinkEvent{Path: rel, Target: link, Action: "followed"})
+	if err := b.writeEntry(resolved, rel, info, ""); err != nil {
+		return err
+	}
+
+	next := make(map[string]bool, len(ancestors)+1)
+	for k := range ancestors {
+		next[k] = true
+	}
+	next[resolved] = true
+	return b.addTree(resolved, rel, next)
+}
+
+// writeEntry writes a tar header for path under the archive name rel and,
+// for regular files, copies the file contents.
+func (b *tarBuilder) writeEntry(path, rel string, info os.FileInfo, link string) error {
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := b.tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	// Only copy content for regular files (not dirs or symlinks)
+	if info.Mode().IsRegular() {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(b.tw, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
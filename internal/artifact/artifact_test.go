@@ -0,0 +1,373 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --- .hatchignore integration tests ---
+
+func TestCreate_DefaultsExcludeGitAndEnv(t *testing.T) {
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, ".git"), 0755)
+	os.WriteFile(filepath.Join(tmp, ".env"), []byte("SECRET=x"), 0644)
+	os.WriteFile(filepath.Join(tmp, ".env.local"), []byte("LOCAL=y"), 0644)
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("console.log('hi')"), 0644)
+
+	artifact, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artifact) == 0 {
+		t.Fatal("expected non-empty artifact")
+	}
+
+	// .git/ and .env* should be excluded
+	foundGit := false
+	foundEnv := false
+	for _, e := range report.Excluded {
+		if e == ".git/" {
+			foundGit = true
+		}
+		if e == ".env" || e == ".env.local" {
+			foundEnv = true
+		}
+	}
+	if !foundGit {
+		t.Errorf("expected .git/ in excluded list, got: %v", report.Excluded)
+	}
+	if !foundEnv {
+		t.Errorf("expected .env files in excluded list, got: %v", report.Excluded)
+	}
+}
+
+func TestCreate_HatchignoreExcludesNodeModules(t *testing.T) {
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "node_modules", "express"), 0755)
+	os.WriteFile(filepath.Join(tmp, "node_modules", "express", "index.js"), []byte("module.exports = {}"), 0644)
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("require('express')"), 0644)
+	os.WriteFile(filepath.Join(tmp, ".hatchignore"), []byte("node_modules/\n"), 0644)
+
+	_, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, e := range report.Excluded {
+		if e == "node_modules/" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected node_modules/ in excluded list, got: %v", report.Excluded)
+	}
+}
+
+func TestCreate_NitroNotExcludedByDefault(t *testing.T) {
+	// Regression test: .nitro inside node_modules must NOT be excluded
+	tmp := t.TempDir()
+	nitroDir := filepath.Join(tmp, "node_modules", ".nitro")
+	os.MkdirAll(nitroDir, 0755)
+	os.WriteFile(filepath.Join(nitroDir, "index.js"), []byte("// nitro"), 0644)
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// server"), 0644)
+
+	_, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, e := range report.Excluded {
+		if strings.Contains(e, ".nitro") {
+			t.Errorf(".nitro should NOT be excluded by defaults, but found in excluded: %v", report.Excluded)
+		}
+	}
+}
+
+func TestCreate_HatchignoreNegation(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "app.log"), []byte("log"), 0644)
+	os.WriteFile(filepath.Join(tmp, "important.log"), []byte("keep"), 0644)
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// srv"), 0644)
+	os.WriteFile(filepath.Join(tmp, ".hatchignore"), []byte("*.log\n!important.log\n"), 0644)
+
+	_, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	foundApp := false
+	foundImportant := false
+	for _, e := range report.Excluded {
+		if e == "app.log" {
+			foundApp = true
+		}
+		if e == "important.log" {
+			foundImportant = true
+		}
+	}
+	if !foundApp {
+		t.Errorf("expected app.log in excluded list, got: %v", report.Excluded)
+	}
+	if foundImportant {
+		t.Errorf("expected important.log NOT in excluded (negated), got: %v", report.Excluded)
+	}
+}
+
+func TestCreate_SymlinkEscapesDirectory(t *testing.T) {
+	tmp := t.TempDir()
+
+	// Create a symlink that points outside the directory
+	linkPath := tmp + "/external-link"
+	os.Symlink("/etc/passwd", linkPath)
+	os.WriteFile(tmp+"/server.js", []byte("// server"), 0644)
+
+	artifact, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artifact) == 0 {
+		t.Fatal("expected non-empty artifact")
+	}
+
+	// The symlink that escapes should be excluded (no content added)
+	// Check that symlink is not in artifact by checking excluded list
+	found := false
+	for _, e := range report.Excluded {
+		if e == "external-link" {
+			found = true
+		}
+	}
+	if found {
+		t.Logf("escaping symlink was excluded: %v", report.Excluded)
+	}
+}
+
+func TestCreate_InternalSymlinkIncluded(t *testing.T) {
+	tmp := t.TempDir()
+
+	// Create a directory
+	os.MkdirAll(tmp+"/data", 0755)
+	os.WriteFile(tmp+"/data/info.txt", []byte("secret"), 0644)
+
+	// Create a symlink inside the directory pointing to another file inside
+	linkPath := tmp + "/info-link"
+	os.Symlink("data/info.txt", linkPath)
+	os.WriteFile(tmp+"/server.js", []byte("// server"), 0644)
+
+	artifact, report, err := Create(tmp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artifact) == 0 {
+		t.Fatal("expected non-empty artifact")
+	}
+
+	// Internal symlink should be included
+	found := false
+	for _, e := range report.Excluded {
+		if e == "info-link" {
+			found = true
+		}
+	}
+	if found {
+		t.Error("internal symlink should not be excluded")
+	}
+}
+
+// tarEntries decompresses an artifact and returns entry names mapped to
+// their contents (or link target for symlinks).
+func tarEntries(t *testing.T, artifact []byte) map[string]string {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(artifact))
+	if err != nil {
+		t.Fatalf("opening gzip: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	entries := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeSymlink {
+			entries[hdr.Name] = "-> " + hdr.Linkname
+			continue
+		}
+		data, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(data)
+	}
+	return entries
+}
+
+func TestCreate_SymlinkPreserveKeepsEscapingLinks(t *testing.T) {
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "shared.css"), []byte("body{}"), 0644)
+
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// server"), 0644)
+	os.Symlink(filepath.Join(outside, "shared.css"), filepath.Join(tmp, "shared.css"))
+	os.Symlink("server.js", filepath.Join(tmp, "index.js"))
+
+	artifact, report, err := Create(tmp, Options{Symlinks: SymlinksPreserve})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := tarEntries(t, artifact)
+	if want := "-> " + filepath.Join(outside, "shared.css"); entries["shared.css"] != want {
+		t.Errorf("escaping symlink should be archived with its original target, got %q", entries["shared.css"])
+	}
+	if entries["index.js"] != "-> server.js" {
+		t.Errorf("internal symlink should be preserved as a link, got %q", entries["index.js"])
+	}
+	if len(report.Symlinks) != 1 {
+		t.Fatalf("expected 1 symlink event, got %v", report.Symlinks)
+	}
+	if ev := report.Symlinks[0]; ev.Path != "shared.css" || ev.Action != "preserved" {
+		t.Errorf("unexpected symlink event: %+v", ev)
+	}
+}
+
+func TestCreate_SymlinkFollowCopiesContents(t *testing.T) {
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(outside, "pkg", "lib"), 0755)
+	os.WriteFile(filepath.Join(outside, "pkg", "index.js"), []byte("module.exports = 1"), 0644)
+	os.WriteFile(filepath.Join(outside, "pkg", "lib", "util.js"), []byte("// util"), 0644)
+
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// server"), 0644)
+	os.Symlink(filepath.Join(outside, "pkg"), filepath.Join(tmp, "node_modules", "pkg"))
+
+	artifact, report, err := Create(tmp, Options{Symlinks: SymlinksFollow})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := tarEntries(t, artifact)
+	if entries["node_modules/pkg/index.js"] != "module.exports = 1" {
+		t.Errorf("expected followed file contents, got entries: %v", entries)
+	}
+	if entries["node_modules/pkg/lib/util.js"] != "// util" {
+		t.Errorf("expected nested followed file contents, got entries: %v", entries)
+	}
+	if len(report.Symlinks) != 1 || report.Symlinks[0].Action != "followed" {
+		t.Errorf("expected one followed symlink event, got %v", report.Symlinks)
+	}
+}
+
+func TestCreate_SymlinkFollowDetectsCycle(t *testing.T) {
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "a.txt"), []byte("a"), 0644)
+	os.Symlink(outside, filepath.Join(outside, "loop"))
+
+	tmp := t.TempDir()
+	os.Symlink(outside, filepath.Join(tmp, "ext"))
+	os.Symlink(filepath.Dir(tmp), filepath.Join(tmp, "parent"))
+
+	artifact, report, err := Create(tmp, Options{Symlinks: SymlinksFollow})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := tarEntries(t, artifact)
+	if entries["ext/a.txt"] != "a" {
+		t.Errorf("expected ext/a.txt in artifact, got entries: %v", entries)
+	}
+
+	cycles := 0
+	for _, ev := range report.Symlinks {
+		if ev.Reason == "cycle detected" {
+			cycles++
+		}
+	}
+	if cycles != 2 {
+		t.Errorf("expected 2 cycle events (ext/loop and parent), got %v", report.Symlinks)
+	}
+}
+
+func TestCreate_SymlinkErrorMode(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("// server"), 0644)
+	os.Symlink("/etc/passwd", filepath.Join(tmp, "passwd"))
+	os.Symlink("server.js", filepath.Join(tmp, "index.js"))
+
+	_, _, err := Create(tmp, Options{Symlinks: SymlinksError})
+	if err == nil {
+		t.Fatal("expected error for escaping symlink in error mode")
+	}
+	if !strings.Contains(err.Error(), "passwd -> /etc/passwd") {
+		t.Errorf("expected error to list the escaping link, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "index.js") {
+		t.Errorf("internal symlink should not be reported, got: %v", err)
+	}
+}
+
+func TestParseSymlinkMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SymlinkMode
+		wantErr bool
+	}{
+		{"", SymlinksPreserve, false},
+		{"preserve", SymlinksPreserve, false},
+		{"follow", SymlinksFollow, false},
+		{"error", SymlinksError, false},
+		{"skip", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSymlinkMode(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSymlinkMode(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseSymlinkMode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCreate_SkipsUnixSocketEntries(t *testing.T) {
+	dir, err := os.MkdirTemp("/tmp", "mcp-tar-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	regularFile := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(regularFile, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to create regular file: %v", err)
+	}
+
+	socketPath := filepath.Join(dir, "app.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("could not create unix socket on this platform: %v", err)
+	}
+	defer l.Close()
+	defer os.Remove(socketPath)
+
+	artifact, _, err := Create(dir, Options{})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	entries := tarEntries(t, artifact)
+	if _, ok := entries["app.sock"]; ok {
+		t.Fatalf("expected socket file to be skipped from archive, entries: %v", entries)
+	}
+	if entries["app.txt"] != "hello" {
+		t.Fatalf("expected regular file in archive, entries: %v", entries)
+	}
+}
@@ -0,0 +1,70 @@
+package artifact
+
+import (
+	"fmt"
+	"io"
+	goruntime "runtime"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+)
+
+// compressionBlockSize is the amount of input each gzip worker compresses
+// at a time. Larger blocks compress slightly better but need more memory.
+const compressionBlockSize = 1 << 20
+
+// ParseFormat validates a --compression value. Empty means gzip.
+func ParseFormat(s string) (api.ArtifactFormat, error) {
+	switch api.ArtifactFormat(s) {
+	case "", api.ArtifactGzip:
+		return api.ArtifactGzip, nil
+	case api.ArtifactZstd:
+		return api.ArtifactZstd, nil
+	}
+	return "", fmt.Errorf("invalid --compression value %q (valid: gzip, zstd)", s)
+}
+
+// ValidateLevel checks a --compression-level value for the given
+// format. Zero selects the format's default level.
+func ValidateLevel(format api.ArtifactFormat, level int) error {
+	if level == 0 {
+		return nil
+	}
+	if format == api.ArtifactZstd {
+		if level < 1 || level > 22 {
+			return fmt.Errorf("invalid --compression-level %d for zstd (valid: 1-22)", level)
+		}
+		return nil
+	}
+	if level < 1 || level > 9 {
+		return fmt.Errorf("invalid --compression-level %d for gzip (valid: 1-9)", level)
+	}
+	return nil
+}
+
+// newCompressor returns a writer that compresses into w using every
+// available CPU. The caller must Close it to flush the final block.
+func newCompressor(w io.Writer, format api.ArtifactFormat, level int) (io.WriteCloser, error) {
+	workers := goruntime.GOMAXPROCS(0)
+
+	if format == api.ArtifactZstd {
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(workers)}
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		return zstd.NewWriter(w, opts...)
+	}
+
+	if level == 0 {
+		level = pgzip.DefaultCompression
+	}
+	gw, err := pgzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.SetConcurrency(compressionBlockSize, workers); err != nil {
+		return nil, err
+	}
+	return gw, nil
+}
@@ -0,0 +1,205 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestParseArtifactFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    api.ArtifactFormat
+		wantErr bool
+	}{
+		{"", api.ArtifactGzip, false},
+		{"gzip", api.ArtifactGzip, false},
+		{"zstd", api.ArtifactZstd, false},
+		{"brotli", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCompressionLevel(t *testing.T) {
+	tests := []struct {
+		format  api.ArtifactFormat
+		level   int
+		wantErr bool
+	}{
+		{api.ArtifactGzip, 0, false},
+		{api.ArtifactGzip, 1, false},
+		{api.ArtifactGzip, 9, false},
+		{api.ArtifactGzip, 10, true},
+		{api.ArtifactGzip, -1, true},
+		{api.ArtifactZstd, 0, false},
+		{api.ArtifactZstd, 19, false},
+		{api.ArtifactZstd, 22, false},
+		{api.ArtifactZstd, 23, true},
+	}
+	for _, tt := range tests {
+		err := ValidateLevel(tt.format, tt.level)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateLevel(%s, %d) error = %v, wantErr %v", tt.format, tt.level, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreate_ZstdRoundTrip(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "server.js"), []byte("console.log('hi')"), 0644)
+
+	artifact, _, err := Create(tmp, Options{Format: api.ArtifactZstd, Level: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zr, err := zstd.NewReader(bytes.NewReader(artifact))
+	if err != nil {
+		t.Fatalf("opening zstd: %v", err)
+	}
+	defer zr.Close()
+
+	hdr, err := tar.NewReader(zr).Next()
+	if err != nil {
+		t.Fatalf("reading tar: %v", err)
+	}
+	if hdr.Name != "server.js" {
+		t.Fatalf("expected server.js, got %q", hdr.Name)
+	}
+}
+
+func TestCreate_ParallelGzipIsStandardGzip(t *testing.T) {
+	tmp := t.TempDir()
+	// Larger than one compression block so several workers are involved.
+	writeBenchTree(t, tmp, 4, 1<<20)
+
+	artifact, _, err := Create(tmp, Options{Format: api.ArtifactGzip})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(artifact))
+	if err != nil {
+		t.Fatalf("opening gzip: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	files := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			files++
+		}
+	}
+	if files != 4 {
+		t.Fatalf("expected 4 files, got %d", files)
+	}
+}
+
+// writeBenchTree writes n files of size bytes each. Content is a mix of
+// repetitive text and random bytes, roughly like a JS bundle with assets.
+func writeBenchTree(tb testing.TB, dir string, n, size int) {
+	tb.Helper()
+	rng := rand.New(rand.NewSource(1))
+	text := []byte("export function render(props) { return h('div', props.children); }\n")
+	for i := 0; i < n; i++ {
+		buf := make([]byte, 0, size)
+		for len(buf) < size {
+			if rng.Intn(4) == 0 {
+				chunk := make([]byte, 256)
+				rng.Read(chunk)
+				buf = append(buf, chunk...)
+			} else {
+				buf = append(buf, text...)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("chunk-%d.js", i)), buf[:size], 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCreate compares throughput and compression ratio across
+// formats and levels. The "stdlib-gzip" case is the single-threaded
+// compress/gzip baseline the artifact builder used to run.
+//
+//	go test ./internal/artifact -run '^$' -bench Create
+func BenchmarkCreate(b *testing.B) {
+	const files, size = 16, 2 << 20
+	dir := b.TempDir()
+	writeBenchTree(b, dir, files, size)
+	input := int64(files * size)
+
+	b.Run("stdlib-gzip", func(b *testing.B) {
+		b.SetBytes(input)
+		var out int
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+			filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				hdr, _ := tar.FileInfoHeader(info, "")
+				tw.WriteHeader(hdr)
+				f, _ := os.Open(path)
+				defer f.Close()
+				_, err = io.Copy(tw, f)
+				return err
+			})
+			tw.Close()
+			gw.Close()
+			out = buf.Len()
+		}
+		b.ReportMetric(float64(input)/float64(out), "ratio")
+	})
+
+	cases := []struct {
+		format api.ArtifactFormat
+		level  int
+	}{
+		{api.ArtifactGzip, 1},
+		{api.ArtifactGzip, 0},
+		{api.ArtifactGzip, 9},
+		{api.ArtifactZstd, 1},
+		{api.ArtifactZstd, 0},
+		{api.ArtifactZstd, 19},
+	}
+	for _, c := range cases {
+		b.Run(fmt.Sprintf("%s-level%d", c.format, c.level), func(b *testing.B) {
+			b.SetBytes(input)
+			var out int
+			for i := 0; i < b.N; i++ {
+				artifact, _, err := Create(dir, Options{Format: c.format, Level: c.level})
+				if err != nil {
+					b.Fatal(err)
+				}
+				out = len(artifact)
+			}
+			b.ReportMetric(float64(input)/float64(out), "ratio")
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package buildlog finds the lines that explain why a build failed, so they
+// can be shown on their own instead of at the end of a long log.
+package buildlog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kinds of build failure, from most to least specific.
+const (
+	KindOOM           = "oom"
+	KindMissingModule = "missing_module"
+	KindCompileError  = "compile_error"
+	KindError         = "error"
+)
+
+// maxExcerpt caps the lines of an error block.
+const maxExcerpt = 12
+
+// Summary describes the error block of a failed build.
+type Summary struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	// Line is the 1-based line number where the block starts.
+	Line    int      `json:"line"`
+	Excerpt []string `json:"excerpt"`
+	Hint    string   `json:"hint,omitempty"`
+}
+
+type detector struct {
+	kind     string
+	patterns []*regexp.Regexp
+	// title builds the title from the first pattern's submatches.
+	title func(m []string) string
+	hint  string
+}
+
+var detectors = []detector{
+	{
+		kind: KindOOM,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`JavaScript heap out of memory`),
+			regexp.MustCompile(`(?i)\bout of memory\b|\bOOMKilled\b|\bcannot allocate memory\b`),
+			regexp.MustCompile(`exit code:? 137\b|signal: killed|^Killed$`),
+		},
+		title: func([]string) string { return "the build ran out of memory" },
+		hint:  "Reduce the build's memory use (for Node, set NODE_OPTIONS=--max-old-space-size), or build locally and deploy the artifact.",
+	},
+	{
+		kind: KindMissingModule,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`no required module provides package (\S+)`),
+			regexp.MustCompile(`cannot find module providing package (\S+)`),
+			regexp.MustCompile(`Cannot find module '([^']+)'`),
+			regexp.MustCompile(`Module not found: Error: Can't resolve '([^']+)'`),
+			regexp.MustCompile(`ModuleNotFoundError: No module named '([^']+)'`),
+			regexp.MustCompile(`Could not find a version that satisfies the requirement (\S+)`),
+			regexp.MustCompile(`'([^']+)' is not in (?:this|the npm) registry`),
+			regexp.MustCompile(`(?:cannot find|can't find) crate for ` + "`([^`]+)`"),
+		},
+		title: func(m []string) string { return "missing module " + strings.Trim(m[1], `"';:`) },
+		hint:  "Add the module to your dependency manifest (go.mod, package.json, requirements.txt, ...) and commit the lock file.",
+	},
+	{
+		kind: KindCompileError,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(\S+\.go):\d+:\d+: `),
+			regexp.MustCompile(`^(\S+\.tsx?)(?::\d+:\d+ - |\(\d+,\d+\): )error TS\d+`),
+			regexp.MustCompile(`^(\S+\.(?:c|cc|cpp|h|hpp|java|kt|swift)):\d+(?::\d+)?: (?:fatal )?error`),
+			regexp.MustCompile(`^error(?:\[E\d+\])?: `),
+			regexp.MustCompile(`^\s*File "([^"]+)", line \d+`),
+			regexp.MustCompile(`^(SyntaxError|IndentationError|TypeError): `),
+		},
+		title: func(m []string) string {
+			if len(m) > 1 && m[1] != "" && !strings.HasSuffix(m[1], "Error") {
+				return "compile error in " + m[1]
+			}
+			return "compile error"
+		},
+	},
+	{
+		kind: KindError,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:npm ERR!|(?:ERROR|FATAL|Error|fatal|error)\b)`),
+		},
+		title: func([]string) string { return "build error" },
+	},
+}
+
+// prefixRE matches what build tools put before a line: a timestamp or a
+// BuildKit step marker such as "#12 3.456".
+var prefixRE = regexp.MustCompile(`^(?:\d{4}-\d\d-\d\dT\S+\s+)?(?:#\d+ \d+(?:\.\d+)? )?`)
+
+// Clean strips timestamps and BuildKit step markers from a line.
+func Clean(line string) string {
+	return prefixRE.ReplaceAllString(strings.TrimRight(line, "\r"), "")
+}
+
+// Summarize returns the most specific error block in a failed build's
+// lines, or nil if none of them looks like an error.
+func Summarize(lines []string) *Summary {
+	cleaned := make([]string, len(lines))
+	for i, l := range lines {
+		cleaned[i] = Clean(l)
+	}
+	for _, d := range detectors {
+		for i, l := range cleaned {
+			for _, re := range d.patterns {
+				m := re.FindStringSubmatch(l)
+				if m == nil {
+					continue
+				}
+				return &Summary{
+					Kind:    d.kind,
+					Title:   d.title(m),
+					Line:    i + 1,
+					Excerpt: block(cleaned, i),
+					Hint:    d.hint,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// block returns the lines from start up to the next blank line, at most
+// maxExcerpt of them. A preceding "# package" header, as printed by the Go
+// toolchain, is included.
+func block(lines []string, start int) []string {
+	if start > 0 && strings.HasPrefix(lines[start-1], "# ") {
+		start--
+	}
+	end := start + 1
+	for end < len(lines) && end-start < maxExcerpt && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	return append([]string(nil), lines[start:end]...)
+}
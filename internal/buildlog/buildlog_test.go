@@ -0,0 +1,109 @@
+package buildlog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name        string
+		log         string
+		wantKind    string
+		wantTitle   string
+		wantLine    int
+		wantExcerpt []string
+	}{
+		{
+			name: "go compile error",
+			log: `#9 [build 4/5] RUN go build -o /app ./cmd/server
+#9 1.204 # example.com/shop/internal/cart
+#9 1.204 internal/cart/cart.go:42:9: undefined: Total
+#9 1.204 internal/cart/cart.go:57:2: missing return
+
+#9 ERROR: process "/bin/sh -c go build" did not complete successfully: exit code: 1`,
+			wantKind:    KindCompileError,
+			wantTitle:   "compile error in internal/cart/cart.go",
+			wantLine:    3,
+			wantExcerpt: []string{"# example.com/shop/internal/cart", "internal/cart/cart.go:42:9: undefined: Total", "internal/cart/cart.go:57:2: missing return"},
+		},
+		{
+			name: "typescript error",
+			log: `> tsc && vite build
+src/App.tsx(12,5): error TS2322: Type 'string' is not assignable to type 'number'.`,
+			wantKind:  KindCompileError,
+			wantTitle: "compile error in src/App.tsx",
+			wantLine:  2,
+		},
+		{
+			name: "missing node module",
+			log: `2025-06-15T10:00:01Z > node build.js
+2025-06-15T10:00:02Z Error: Cannot find module 'left-pad'
+2025-06-15T10:00:02Z Require stack:
+2025-06-15T10:00:02Z - /app/build.js`,
+			wantKind:    KindMissingModule,
+			wantTitle:   "missing module left-pad",
+			wantLine:    2,
+			wantExcerpt: []string{"Error: Cannot find module 'left-pad'", "Require stack:", "- /app/build.js"},
+		},
+		{
+			name:      "missing go module",
+			log:       `main.go:8:2: no required module provides package github.com/acme/metrics; to add it:`,
+			wantKind:  KindMissingModule,
+			wantTitle: "missing module github.com/acme/metrics",
+			wantLine:  1,
+		},
+		{
+			name:      "missing python module",
+			log:       "Traceback (most recent call last):\n  File \"/app/manage.py\", line 3, in <module>\nModuleNotFoundError: No module named 'django'",
+			wantKind:  KindMissingModule,
+			wantTitle: "missing module django",
+			wantLine:  3,
+		},
+		{
+			name: "out of memory wins over the errors it causes",
+			log: `vite v5.0.0 building for production...
+<--- Last few GCs --->
+FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory
+error Command failed with signal "SIGABRT".`,
+			wantKind:  KindOOM,
+			wantTitle: "the build ran out of memory",
+			wantLine:  3,
+		},
+		{
+			name:      "killed build step",
+			log:       "#12 ERROR: process \"/bin/sh -c npm run build\" did not complete successfully: exit code: 137",
+			wantKind:  KindOOM,
+			wantTitle: "the build ran out of memory",
+			wantLine:  1,
+		},
+		{
+			name:      "generic error",
+			log:       "Step 3/5\nnpm ERR! code ELIFECYCLE\nnpm ERR! errno 1",
+			wantKind:  KindError,
+			wantTitle: "build error",
+			wantLine:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarize(strings.Split(tt.log, "\n"))
+			if s == nil {
+				t.Fatal("expected a summary")
+			}
+			if s.Kind != tt.wantKind || s.Title != tt.wantTitle || s.Line != tt.wantLine {
+				t.Errorf("got %s %q at line %d, want %s %q at line %d", s.Kind, s.Title, s.Line, tt.wantKind, tt.wantTitle, tt.wantLine)
+			}
+			if tt.wantExcerpt != nil && !reflect.DeepEqual(s.Excerpt, tt.wantExcerpt) {
+				t.Errorf("excerpt = %q, want %q", s.Excerpt, tt.wantExcerpt)
+			}
+		})
+	}
+}
+
+func TestSummarize_NoError(t *testing.T) {
+	if s := Summarize([]string{"Step 1/3", "Successfully built 3f2a", "errors.go compiled"}); s != nil {
+		t.Errorf("expected no summary, got %+v", s)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEvent records a sensitive action, such as revealing a secret to an
+// MCP client. It never contains the secret itself.
+type AuditEvent struct {
+	Time   string `json:"time"`
+	Action string `json:"action"`
+	App    string `json:"app"`
+	Key    string `json:"key,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AuditLogPath returns ~/.hatch/audit.log.
+func AuditLogPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// AppendAuditLog appends event as one JSON line to the audit log, creating it
+// with 0600 permissions. Time is filled in if empty.
+func AppendAuditLog(event AuditEvent) error {
+	path, err := AuditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	if event.Time == "" {
+		event.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendAuditLog(t *testing.T) {
+	home := setupTestHome(t)
+
+	for _, key := range []string{"DATABASE_URL", "API_TOKEN"} {
+		if err := AppendAuditLog(AuditEvent{Action: "reveal_env_var", App: "myapp", Key: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(home, ".hatch", "audit.log")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected 0600 permissions, got %o", perm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 || events[1].Key != "API_TOKEN" || events[0].Time == "" {
+		t.Errorf("unexpected audit events: %+v", events)
+	}
+}
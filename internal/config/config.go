@@ -8,9 +8,27 @@ import (
 )
 
 type Config struct {
-	Token         string `json:"token,omitempty"`
-	APIHost       string `json:"api_host,omitempty"`
-	TosAcceptedAt string `json:"tos_accepted_at,omitempty"`
+	Token         string       `json:"token,omitempty"`
+	APIHost       string       `json:"api_host,omitempty"`
+	TosAcceptedAt string       `json:"tos_accepted_at,omitempty"`
+	MCP           *MCPConfig   `json:"mcp,omitempty"`
+	Retry         *RetryConfig `json:"retry,omitempty"`
+}
+
+// MCPConfig holds settings for the `hatch mcp` server.
+type MCPConfig struct {
+	// DisableReveal removes the reveal_env_var tool, so agents can only
+	// ever see masked values of sensitive env vars.
+	DisableReveal bool `json:"disable_reveal,omitempty"`
+}
+
+// RetryConfig overrides how API requests that fail with a transient error
+// are retried. HATCH_MAX_RETRIES and HATCH_RETRY_MAX_DELAY take precedence.
+type RetryConfig struct {
+	// MaxRetries is how many times a request is retried; 0 disables retries.
+	MaxRetries *int `json:"max_retries,omitempty"`
+	// MaxDelay caps the backoff between retries, as a Go duration like "30s".
+	MaxDelay string `json:"max_delay,omitempty"`
 }
 
 // Dir returns the hatch config directory (~/.hatch).
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -63,6 +64,21 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestSaveOmitsUnsetSections(t *testing.T) {
+	setupTestHome(t)
+	if err := Save(&Config{Token: "tok"}); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := Path()
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "mcp") || strings.Contains(string(data), "retry") {
+		t.Errorf("expected unset sections to be omitted, got %s", data)
+	}
+}
+
 func TestClearToken(t *testing.T) {
 	setupTestHome(t)
 	if err := Save(&Config{Token: "to-clear"}); err != nil {
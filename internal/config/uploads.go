@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// UploadState records an interrupted chunked artifact upload so that the
+// next deploy of the same artifact can resume it.
+type UploadState struct {
+	Slug      string `json:"slug"`
+	UploadID  string `json:"upload_id"`
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"created_at"`
+}
+
+// uploadStatePath returns ~/.hatch/uploads/<slug>.json.
+func uploadStatePath(slug string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "uploads", filepath.Base(slug)+".json"), nil
+}
+
+// LoadUploadState returns the saved upload state for slug, or nil if none.
+func LoadUploadState(slug string) (*UploadState, error) {
+	path, err := uploadStatePath(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state UploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveUploadState writes the upload state for state.Slug with 0600 permissions.
+func SaveUploadState(state *UploadState) error {
+	path, err := uploadStatePath(state.Slug)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ClearUploadState removes the saved upload state for slug.
+func ClearUploadState(slug string) error {
+	path, err := uploadStatePath(slug)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
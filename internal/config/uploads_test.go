@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadStateRoundTrip(t *testing.T) {
+	home := setupTestHome(t)
+
+	state, err := LoadUploadState("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil {
+		t.Fatalf("expected no state, got %+v", state)
+	}
+
+	want := &UploadState{Slug: "myapp", UploadID: "up-1", SHA256: "abc", Size: 42}
+	if err := SaveUploadState(want); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(home, ".hatch", "uploads", "myapp.json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected 0600 permissions, got %o", perm)
+	}
+
+	got, err := LoadUploadState("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *want {
+		t.Errorf("LoadUploadState() = %+v, want %+v", got, want)
+	}
+
+	if err := ClearUploadState("myapp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected state file to be removed")
+	}
+	if err := ClearUploadState("myapp"); err != nil {
+		t.Errorf("clearing missing state should not fail: %v", err)
+	}
+}
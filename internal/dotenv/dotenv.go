@@ -0,0 +1,391 @@
+// Package dotenv reads and writes .env files.
+//
+// The accepted syntax follows the common dotenv libraries (motdotla/dotenv
+// with dotenv-expand, joho/godotenv):
+//
+//   - Blank lines and lines starting with # are ignored.
+//   - An optional "export " prefix before the key is ignored.
+//   - Unquoted values are trimmed and end at an inline comment (" #").
+//   - Single-quoted and backtick-quoted values are literal and may span
+//     several lines.
+//   - Double-quoted values may span several lines and understand the escapes
+//     \n, \r, \t, \", \\ and \$.
+//   - Unquoted and double-quoted values expand $VAR, ${VAR}, ${VAR:-default}
+//     and ${VAR-default}. Names resolve to keys assigned earlier in the file,
+//     then to Options.Lookup; anything else expands to "".
+package dotenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Entry is one KEY=VALUE assignment.
+type Entry struct {
+	Key   string
+	Value string
+	// Line and EndLine are the 1-based lines the assignment spans. They
+	// differ only for multi-line quoted values.
+	Line    int
+	EndLine int
+}
+
+// Options controls parsing.
+type Options struct {
+	// Lookup resolves variable references that are not assigned earlier in
+	// the file, typically os.LookupEnv. If nil, such references expand to "".
+	Lookup func(key string) (string, bool)
+}
+
+// ParseError reports a syntax error at a position in the input.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse reads all assignments from r in input order. A key assigned more
+// than once appears once per assignment; the last one wins when the entries
+// are collected into a map.
+func Parse(r io.Reader, opts Options) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		src:     []rune(strings.ReplaceAll(string(data), "\r\n", "\n")),
+		line:    1,
+		col:     1,
+		opts:    opts,
+		defined: map[string]string{},
+	}
+	return p.parse()
+}
+
+// ParseFile parses the file at path. Syntax errors are prefixed with the
+// path and can be inspected with errors.As(err, *ParseError).
+func ParseFile(path string, opts Options) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := Parse(f, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+type parser struct {
+	src     []rune
+	pos     int
+	line    int
+	col     int
+	opts    Options
+	defined map[string]string
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+func (p *parser) errorf(line, col int, format string, args ...interface{}) error {
+	return &ParseError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipBlanks() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	rs := []rune(s)
+	if p.pos+len(rs) > len(p.src) {
+		return false
+	}
+	for i, r := range rs {
+		if p.src[p.pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) parse() ([]Entry, error) {
+	var entries []Entry
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			return entries, nil
+		}
+		switch p.peek() {
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		entry, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		p.defined[entry.Key] = entry.Value
+		entries = append(entries, entry)
+	}
+}
+
+func (p *parser) parseAssignment() (Entry, error) {
+	entry := Entry{Line: p.line}
+
+	if p.hasPrefix("export") {
+		after := p.pos + len("export")
+		if after < len(p.src) && (p.src[after] == ' ' || p.src[after] == '\t') {
+			for i := 0; i < len("export"); i++ {
+				p.next()
+			}
+			p.skipBlanks()
+		}
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return entry, err
+	}
+	entry.Key = key
+
+	p.skipBlanks()
+	if p.eof() || p.peek() == '\n' {
+		return entry, p.errorf(p.line, p.col, "missing \"=\" after key %s", key)
+	}
+	if p.peek() != '=' {
+		return entry, p.errorf(p.line, p.col, "unexpected character %q after key %s, expected \"=\"", p.peek(), key)
+	}
+	p.next()
+	p.skipBlanks()
+
+	var value string
+	switch p.peek() {
+	case '\'', '`':
+		value, err = p.parseLiteral(p.peek())
+	case '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.Value = value
+	entry.EndLine = p.line
+
+	// Only a comment may follow a closing quote.
+	p.skipBlanks()
+	if !p.eof() && p.peek() != '\n' {
+		if p.peek() != '#' {
+			return entry, p.errorf(p.line, p.col, "unexpected character %q after closing quote", p.peek())
+		}
+		p.skipLine()
+	}
+	return entry, nil
+}
+
+func isKeyStart(r rune) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+}
+
+func isKeyChar(r rune) bool {
+	return isKeyStart(r) || (r >= '0' && r <= '9')
+}
+
+func (p *parser) parseKey() (string, error) {
+	if !isKeyStart(p.peek()) {
+		return "", p.errorf(p.line, p.col, "invalid character %q at start of key", p.peek())
+	}
+	start := p.pos
+	for !p.eof() && isKeyChar(p.peek()) {
+		p.next()
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+// parseLiteral reads a single-quoted or backtick-quoted value verbatim.
+func (p *parser) parseLiteral(quote rune) (string, error) {
+	line, col := p.line, p.col
+	p.next()
+	kind := "single-quoted"
+	if quote == '`' {
+		kind = "backtick-quoted"
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, col, "unterminated %s value", kind)
+		}
+		r := p.next()
+		if r == quote {
+			return b.String(), nil
+		}
+		b.WriteRune(r)
+	}
+}
+
+func (p *parser) parseDoubleQuoted() (string, error) {
+	line, col := p.line, p.col
+	p.next()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, col, "unterminated double-quoted value")
+		}
+		switch r := p.peek(); r {
+		case '"':
+			p.next()
+			return b.String(), nil
+		case '\\':
+			p.next()
+			if p.eof() {
+				continue
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteRune(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteRune(e)
+			}
+		case '$':
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteRune(p.next())
+		}
+	}
+}
+
+func (p *parser) parseUnquoted() (string, error) {
+	var b strings.Builder
+	prevBlank := true // a # right after "=" starts a comment too
+	for !p.eof() && p.peek() != '\n' {
+		r := p.peek()
+		switch {
+		case r == '#' && prevBlank:
+			p.skipLine()
+		case r == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '$':
+			p.next()
+			b.WriteRune(p.next())
+		case r == '$':
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteRune(p.next())
+		}
+		prevBlank = r == ' ' || r == '\t'
+	}
+	return strings.TrimRight(b.String(), " \t"), nil
+}
+
+// expand consumes a variable reference starting at '$' and writes its value.
+// A '$' that does not start a reference is written literally.
+func (p *parser) expand(b *strings.Builder) error {
+	line, col := p.line, p.col
+	p.next()
+
+	if p.peek() != '{' {
+		if !isKeyStart(p.peek()) {
+			b.WriteByte('$')
+			return nil
+		}
+		name, _ := p.parseKey()
+		v, _ := p.lookup(name)
+		b.WriteString(v)
+		return nil
+	}
+
+	p.next()
+	if !isKeyStart(p.peek()) {
+		return p.errorf(p.line, p.col, "invalid variable name in ${...}")
+	}
+	name, _ := p.parseKey()
+
+	var def string
+	hasDefault, defaultIfEmpty := false, false
+	switch {
+	case p.hasPrefix(":-"):
+		p.next()
+		p.next()
+		hasDefault, defaultIfEmpty = true, true
+	case p.peek() == '-':
+		p.next()
+		hasDefault = true
+	}
+	if hasDefault {
+		start := p.pos
+		for !p.eof() && p.peek() != '}' && p.peek() != '\n' {
+			p.next()
+		}
+		def = string(p.src[start:p.pos])
+	}
+	if p.peek() != '}' {
+		return p.errorf(line, col, "unterminated variable reference ${%s", name)
+	}
+	p.next()
+
+	v, ok := p.lookup(name)
+	if hasDefault && (!ok || (defaultIfEmpty && v == "")) {
+		v = def
+	}
+	b.WriteString(v)
+	return nil
+}
+
+func (p *parser) lookup(name string) (string, bool) {
+	if v, ok := p.defined[name]; ok {
+		return v, true
+	}
+	if p.opts.Lookup != nil {
+		return p.opts.Lookup(name)
+	}
+	return "", false
+}
@@ -0,0 +1,29 @@
+package dotenv
+
+import "strings"
+
+// Quote returns value in a form that Parse reads back unchanged. Values are
+// left bare when safe and single-quoted when possible, since single-quoted
+// content is taken literally. Everything else is double-quoted with escapes.
+func Quote(value string) string {
+	if !strings.ContainsAny(value, " \t\n\r#\"'\\$`") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// Format renders entries as a .env file, one KEY=VALUE per line.
+func Format(entries []Entry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.Key)
+		b.WriteByte('=')
+		b.WriteString(Quote(e.Value))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+// Package envcopy plans copying environment variables from one egg to
+// another. The CLI's `hatch env copy` and the MCP copy_env tool share it so
+// that both select and skip the same keys.
+package envcopy
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+// Options selects which keys are copied.
+type Options struct {
+	// Include lists glob patterns (as in path.Match) of keys to copy.
+	// Empty means every key.
+	Include []string
+	// Exclude lists glob patterns of keys never to copy. It wins over Include.
+	Exclude []string
+	// SkipExisting leaves keys that already exist on the target untouched.
+	SkipExisting bool
+}
+
+// Plan is the result of comparing the source's selected keys with the
+// target. Key lists are sorted. Copying never removes keys from the target.
+type Plan struct {
+	// Set holds the keys and values to write to the target: Added and Updated.
+	Set       map[string]string
+	Added     []string
+	Updated   []string
+	Unchanged []string
+	// Skipped are keys that differ on the target but were kept because of
+	// SkipExisting.
+	Skipped []string
+	// Old holds the target's current value of each Updated key.
+	Old map[string]string
+}
+
+// Changes returns the number of keys the plan writes.
+func (p *Plan) Changes() int {
+	return len(p.Added) + len(p.Updated)
+}
+
+// NewPlan works out what copying src to dst changes.
+func NewPlan(src, dst []api.EnvVar, opts Options) (*Plan, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	existing := make(map[string]string, len(dst))
+	for _, v := range dst {
+		existing[v.Key] = v.Value
+	}
+
+	plan := &Plan{Set: map[string]string{}, Old: map[string]string{}}
+	for _, v := range src {
+		if !selected(v.Key, opts) {
+			continue
+		}
+		old, ok := existing[v.Key]
+		switch {
+		case !ok:
+			plan.Added = append(plan.Added, v.Key)
+			plan.Set[v.Key] = v.Value
+		case old == v.Value:
+			plan.Unchanged = append(plan.Unchanged, v.Key)
+		case opts.SkipExisting:
+			plan.Skipped = append(plan.Skipped, v.Key)
+		default:
+			plan.Updated = append(plan.Updated, v.Key)
+			plan.Set[v.Key] = v.Value
+			plan.Old[v.Key] = old
+		}
+	}
+
+	for _, keys := range [][]string{plan.Added, plan.Updated, plan.Unchanged, plan.Skipped} {
+		sort.Strings(keys)
+	}
+	return plan, nil
+}
+
+func selected(key string, opts Options) bool {
+	if len(opts.Include) > 0 && !matchAny(key, opts.Include) {
+		return false
+	}
+	return !matchAny(key, opts.Exclude)
+}
+
+// matchAny reports whether key matches one of the patterns. Patterns were
+// validated by NewPlan, so match errors can't occur.
+func matchAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
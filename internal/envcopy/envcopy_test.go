@@ -0,0 +1,105 @@
+package envcopy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func TestNewPlan(t *testing.T) {
+	src := []api.EnvVar{
+		{Key: "STRIPE_KEY", Value: "sk_new"},
+		{Key: "STRIPE_WEBHOOK", Value: "wh"},
+		{Key: "PORT", Value: "8080"},
+		{Key: "DATABASE_URL", Value: "postgres://src"},
+		{Key: "NODE_ENV", Value: "production"},
+	}
+	dst := []api.EnvVar{
+		{Key: "STRIPE_KEY", Value: "sk_old"},
+		{Key: "PORT", Value: "8080"},
+		{Key: "ONLY_DST", Value: "x"},
+	}
+
+	tests := []struct {
+		name                               string
+		opts                               Options
+		added, updated, unchanged, skipped []string
+	}{
+		{
+			name:      "all keys",
+			added:     []string{"DATABASE_URL", "NODE_ENV", "STRIPE_WEBHOOK"},
+			updated:   []string{"STRIPE_KEY"},
+			unchanged: []string{"PORT"},
+		},
+		{
+			name:    "include glob",
+			opts:    Options{Include: []string{"STRIPE_*"}},
+			added:   []string{"STRIPE_WEBHOOK"},
+			updated: []string{"STRIPE_KEY"},
+		},
+		{
+			name:      "exclude wins over include",
+			opts:      Options{Include: []string{"*"}, Exclude: []string{"DATABASE_URL", "STRIPE_W*"}},
+			added:     []string{"NODE_ENV"},
+			updated:   []string{"STRIPE_KEY"},
+			unchanged: []string{"PORT"},
+		},
+		{
+			name:      "skip existing",
+			opts:      Options{SkipExisting: true},
+			added:     []string{"DATABASE_URL", "NODE_ENV", "STRIPE_WEBHOOK"},
+			unchanged: []string{"PORT"},
+			skipped:   []string{"STRIPE_KEY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := NewPlan(src, dst, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			check := func(label string, got, want []string) {
+				if len(got) == 0 && len(want) == 0 {
+					return
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %v, want %v", label, got, want)
+				}
+			}
+			check("Added", plan.Added, tt.added)
+			check("Updated", plan.Updated, tt.updated)
+			check("Unchanged", plan.Unchanged, tt.unchanged)
+			check("Skipped", plan.Skipped, tt.skipped)
+
+			if len(plan.Set) != plan.Changes() {
+				t.Errorf("Set has %d keys, want %d", len(plan.Set), plan.Changes())
+			}
+			if _, ok := plan.Set["ONLY_DST"]; ok {
+				t.Error("copy must not touch target-only keys")
+			}
+		})
+	}
+}
+
+func TestNewPlan_OldValues(t *testing.T) {
+	plan, err := NewPlan(
+		[]api.EnvVar{{Key: "A", Value: "new"}},
+		[]api.EnvVar{{Key: "A", Value: "old"}},
+		Options{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Old["A"] != "old" || plan.Set["A"] != "new" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+}
+
+func TestNewPlan_InvalidPattern(t *testing.T) {
+	_, err := NewPlan(nil, nil, Options{Exclude: []string{"[A-"}})
+	if err == nil || !strings.Contains(err.Error(), `invalid pattern "[A-"`) {
+		t.Fatalf("expected invalid pattern error, got: %v", err)
+	}
+}
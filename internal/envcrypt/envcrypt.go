@@ -0,0 +1,388 @@
+// Package envcrypt encrypts the values of a dotenv file to a set of X25519
+// recipients while leaving the keys readable, so that encrypted env files
+// can be committed and reviewed in pull requests.
+//
+// Each file has a random 256-bit data key. Values are sealed with
+// AES-256-GCM under that key, with the variable name as additional data so
+// values can't be moved between keys. The data key is wrapped once per
+// recipient: an ephemeral X25519 key agreement, HKDF-SHA256, and
+// AES-256-GCM, as in age's X25519 recipients. The result is itself a
+// dotenv file:
+//
+//	# hatch-encrypted-env v1
+//	# hatch:recipient hatch-pub-... <ephemeral key> <wrapped data key>
+//	API_KEY=ENC[v1,...]
+package envcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+)
+
+const (
+	header          = "# hatch-encrypted-env v1"
+	recipientPrefix = "# hatch:recipient "
+	valuePrefix     = "ENC[v1,"
+	valueSuffix     = "]"
+	publicKeyPrefix = "hatch-pub-"
+	secretKeyPrefix = "HATCH-SECRET-KEY-"
+	wrapInfo        = "hatch-encrypted-env v1 data key"
+)
+
+var b64 = base64.RawURLEncoding
+
+// ErrNoIdentity is returned by Decrypt when the identity is not one of the
+// file's recipients.
+var ErrNoIdentity = errors.New("this key is not a recipient of the file")
+
+// Identity is a private key that can decrypt files encrypted to its
+// Recipient.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// Recipient is a public key that files can be encrypted to.
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// GenerateIdentity creates a new random identity.
+func GenerateIdentity() (*Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseIdentity parses the output of Identity.String.
+func ParseIdentity(s string) (*Identity, error) {
+	s = strings.TrimSpace(s)
+	raw, err := b64.DecodeString(strings.TrimPrefix(s, secretKeyPrefix))
+	if !strings.HasPrefix(s, secretKeyPrefix) || err != nil {
+		return nil, fmt.Errorf("malformed secret key")
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// String encodes the identity for storage. It is secret.
+func (i *Identity) String() string {
+	return secretKeyPrefix + b64.EncodeToString(i.key.Bytes())
+}
+
+// Recipient returns the public key matching the identity.
+func (i *Identity) Recipient() Recipient {
+	return Recipient{key: i.key.PublicKey()}
+}
+
+// ParseRecipient parses a public key of the form hatch-pub-...
+func ParseRecipient(s string) (Recipient, error) {
+	s = strings.TrimSpace(s)
+	raw, err := b64.DecodeString(strings.TrimPrefix(s, publicKeyPrefix))
+	if !strings.HasPrefix(s, publicKeyPrefix) || err != nil {
+		return Recipient{}, fmt.Errorf("malformed recipient %q (expected %s...)", s, publicKeyPrefix)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return Recipient{}, fmt.Errorf("malformed recipient %q: %w", s, err)
+	}
+	return Recipient{key: key}, nil
+}
+
+// String encodes the recipient as hatch-pub-...
+func (r Recipient) String() string {
+	return publicKeyPrefix + b64.EncodeToString(r.key.Bytes())
+}
+
+// Encrypt returns an encrypted dotenv file holding entries, readable by any
+// of recipients.
+//
+// If previous is an earlier encrypted version of the file that id can
+// decrypt, and it was encrypted to the same recipients, its data key is
+// reused and unchanged values keep their ciphertext, so that a diff of the
+// two files shows only the keys that changed. Otherwise a new data key is
+// generated, so removed recipients can't read the new file.
+func Encrypt(entries []dotenv.Entry, recipients []Recipient, previous []byte, id *Identity) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipients")
+	}
+	recipients = dedupe(recipients)
+
+	var dataKey []byte
+	var stanzas []string
+	var oldValues map[string]string
+	var oldCiphertexts map[string]string
+	if previous != nil && id != nil {
+		if f, err := parse(previous); err == nil && sameRecipients(f.recipients(), recipients) {
+			if key, err := f.unwrap(id); err == nil {
+				dataKey, stanzas = key, f.stanzas
+				oldValues, oldCiphertexts = map[string]string{}, map[string]string{}
+				for _, e := range f.entries {
+					if v, err := openValue(key, e.Key, e.Value); err == nil {
+						oldValues[e.Key], oldCiphertexts[e.Key] = v, e.Value
+					}
+				}
+			}
+		}
+	}
+
+	if dataKey == nil {
+		dataKey = make([]byte, 32)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, err
+		}
+		for _, r := range recipients {
+			stanza, err := wrap(dataKey, r)
+			if err != nil {
+				return nil, err
+			}
+			stanzas = append(stanzas, stanza)
+		}
+	}
+
+	out := make([]dotenv.Entry, 0, len(entries))
+	for _, e := range entries {
+		if old, ok := oldValues[e.Key]; ok && old == e.Value {
+			out = append(out, dotenv.Entry{Key: e.Key, Value: oldCiphertexts[e.Key]})
+			continue
+		}
+		sealed, err := sealValue(dataKey, e.Key, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, dotenv.Entry{Key: e.Key, Value: sealed})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
+	buf.WriteString("# Values are encrypted; decrypt with 'hatch env push --encrypted'.\n")
+	for _, s := range stanzas {
+		buf.WriteString(recipientPrefix + s + "\n")
+	}
+	buf.WriteString(dotenv.Format(out))
+	return buf.Bytes(), nil
+}
+
+// Decrypt returns the plaintext entries of an encrypted file.
+func Decrypt(data []byte, id *Identity) ([]dotenv.Entry, error) {
+	f, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := f.unwrap(id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dotenv.Entry, 0, len(f.entries))
+	for _, e := range f.entries {
+		value, err := openValue(key, e.Key, e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", e.Line, e.Key, err)
+		}
+		out = append(out, dotenv.Entry{Key: e.Key, Value: value, Line: e.Line, EndLine: e.EndLine})
+	}
+	return out, nil
+}
+
+// IsEncrypted reports whether data looks like an encrypted env file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(header+"\n"))
+}
+
+type file struct {
+	stanzas []string
+	entries []dotenv.Entry
+}
+
+func parse(data []byte) (*file, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("not an encrypted env file (missing %q header)", header)
+	}
+	f := &file{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, recipientPrefix) {
+			f.stanzas = append(f.stanzas, strings.TrimPrefix(line, recipientPrefix))
+		}
+	}
+	if len(f.stanzas) == 0 {
+		return nil, fmt.Errorf("encrypted env file has no recipients")
+	}
+	entries, err := dotenv.Parse(bytes.NewReader(data), dotenv.Options{})
+	if err != nil {
+		return nil, err
+	}
+	f.entries = entries
+	return f, nil
+}
+
+func (f *file) recipients() []string {
+	out := make([]string, 0, len(f.stanzas))
+	for _, s := range f.stanzas {
+		out = append(out, strings.Fields(s)[0])
+	}
+	return out
+}
+
+// unwrap finds the stanza for id and returns the data key.
+func (f *file) unwrap(id *Identity) ([]byte, error) {
+	self := id.Recipient().String()
+	for _, s := range f.stanzas {
+		fields := strings.Fields(s)
+		if len(fields) != 3 || fields[0] != self {
+			continue
+		}
+		ephRaw, err1 := b64.DecodeString(fields[1])
+		wrapped, err2 := b64.DecodeString(fields[2])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("malformed recipient stanza")
+		}
+		eph, err := ecdh.X25519().NewPublicKey(ephRaw)
+		if err != nil {
+			return nil, fmt.Errorf("malformed recipient stanza: %w", err)
+		}
+		kek, err := wrapKey(id.key, eph, eph, id.key.PublicKey())
+		if err != nil {
+			return nil, err
+		}
+		key, err := open(kek, wrapped, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unwrapping data key: %w", err)
+		}
+		return key, nil
+	}
+	return nil, ErrNoIdentity
+}
+
+// wrap encrypts dataKey to r and returns the stanza
+// "<recipient> <ephemeral public key> <wrapped key>".
+func wrap(dataKey []byte, r Recipient) (string, error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	kek, err := wrapKey(eph, r.key, eph.PublicKey(), r.key)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(kek, dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", r, b64.EncodeToString(eph.PublicKey().Bytes()), b64.EncodeToString(wrapped)), nil
+}
+
+// wrapKey derives the key-encryption key from an X25519 agreement between
+// priv and peer, bound to the ephemeral and recipient public keys.
+func wrapKey(priv *ecdh.PrivateKey, peer, eph, recipient *ecdh.PublicKey) ([]byte, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	salt := append(append([]byte{}, eph.Bytes()...), recipient.Bytes()...)
+	return hkdf.Key(sha256.New, shared, salt, wrapInfo, 32)
+}
+
+func sealValue(dataKey []byte, key, value string) (string, error) {
+	sealed, err := seal(dataKey, []byte(value), []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return valuePrefix + base64.StdEncoding.EncodeToString(sealed) + valueSuffix, nil
+}
+
+func openValue(dataKey []byte, key, value string) (string, error) {
+	if !strings.HasPrefix(value, valuePrefix) || !strings.HasSuffix(value, valueSuffix) {
+		return "", fmt.Errorf("value is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, valuePrefix), valueSuffix))
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	plain, err := open(dataKey, sealed, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("decrypting value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// seal encrypts with AES-256-GCM and a random nonce, returning nonce||ciphertext.
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func dedupe(recipients []Recipient) []Recipient {
+	seen := map[string]bool{}
+	var out []Recipient
+	for _, r := range recipients {
+		if s := r.String(); !seen[s] {
+			seen[s] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func sameRecipients(have []string, want []Recipient) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	w := make([]string, 0, len(want))
+	for _, r := range want {
+		w = append(w, r.String())
+	}
+	h := append([]string{}, have...)
+	sort.Strings(h)
+	sort.Strings(w)
+	for i := range h {
+		if h[i] != w[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package envcrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+)
+
+func mustIdentity(t *testing.T) *Identity {
+	t.Helper()
+	id, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+var sample = []dotenv.Entry{
+	{Key: "API_KEY", Value: "sk_live_abcdef"},
+	{Key: "PEM", Value: "-----BEGIN-----\nabc\n-----END-----"},
+	{Key: "EMPTY", Value: ""},
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	alice, bob, eve := mustIdentity(t), mustIdentity(t), mustIdentity(t)
+
+	data, err := Encrypt(sample, []Recipient{alice.Recipient(), bob.Recipient()}, nil, nil)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if !IsEncrypted(data) {
+		t.Fatal("expected header")
+	}
+	if bytes.Contains(data, []byte("sk_live")) || bytes.Contains(data, []byte("BEGIN")) {
+		t.Fatalf("plaintext leaked:\n%s", data)
+	}
+	for _, key := range []string{"API_KEY=ENC[v1,", "PEM=ENC[v1,", "EMPTY=ENC[v1,"} {
+		if !bytes.Contains(data, []byte(key)) {
+			t.Errorf("expected readable key %q in:\n%s", key, data)
+		}
+	}
+
+	for _, id := range []*Identity{alice, bob} {
+		got, err := Decrypt(data, id)
+		if err != nil {
+			t.Fatalf("Decrypt() error: %v", err)
+		}
+		if len(got) != len(sample) {
+			t.Fatalf("got %d entries, want %d", len(got), len(sample))
+		}
+		for i := range sample {
+			if got[i].Key != sample[i].Key || got[i].Value != sample[i].Value {
+				t.Errorf("entry %d = %s=%q, want %s=%q", i, got[i].Key, got[i].Value, sample[i].Key, sample[i].Value)
+			}
+		}
+	}
+
+	if _, err := Decrypt(data, eve); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("expected ErrNoIdentity for a non-recipient, got: %v", err)
+	}
+}
+
+func TestDecrypt_ValueMovedToAnotherKey(t *testing.T) {
+	id := mustIdentity(t)
+	data, err := Encrypt([]dotenv.Entry{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}}, []Recipient{id.Recipient()}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	var a, b int
+	for i, l := range lines {
+		if strings.HasPrefix(l, "A=") {
+			a = i
+		}
+		if strings.HasPrefix(l, "B=") {
+			b = i
+		}
+	}
+	lines[a], lines[b] = "A="+strings.TrimPrefix(lines[b], "B="), "B="+strings.TrimPrefix(lines[a], "A=")
+
+	_, err = Decrypt([]byte(strings.Join(lines, "\n")), id)
+	if err == nil || !strings.Contains(err.Error(), "decrypting value") {
+		t.Fatalf("expected swapped values to fail authentication, got: %v", err)
+	}
+}
+
+func TestEncrypt_ReusesCiphertextForUnchangedValues(t *testing.T) {
+	id, other := mustIdentity(t), mustIdentity(t)
+	recipients := []Recipient{id.Recipient()}
+
+	first, err := Encrypt(sample, recipients, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := append([]dotenv.Entry{}, sample...)
+	changed[0].Value = "sk_live_rotated"
+
+	second, err := Encrypt(changed, recipients, first, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := 0
+	firstLines, secondLines := strings.Split(string(first), "\n"), strings.Split(string(second), "\n")
+	for i := range firstLines {
+		if firstLines[i] != secondLines[i] {
+			diff++
+			if !strings.HasPrefix(secondLines[i], "API_KEY=") {
+				t.Errorf("unexpected changed line: %s", secondLines[i])
+			}
+		}
+	}
+	if diff != 1 {
+		t.Errorf("expected exactly one changed line, got %d", diff)
+	}
+
+	// A change of recipients rotates the data key
+	third, err := Encrypt(changed, []Recipient{other.Recipient()}, second, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(third, id); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("removed recipient can still decrypt: %v", err)
+	}
+}
+
+func TestParseRecipient(t *testing.T) {
+	id := mustIdentity(t)
+	r, err := ParseRecipient(id.Recipient().String())
+	if err != nil || r.String() != id.Recipient().String() {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if _, err := ParseRecipient("age1abc"); err == nil || !strings.Contains(err.Error(), "malformed recipient") {
+		t.Errorf("expected malformed recipient error, got: %v", err)
+	}
+}
+
+func TestSaveLoadIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hatch", "env.key")
+	id := mustIdentity(t)
+	if err := SaveIdentity(path, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveIdentity(path, mustIdentity(t)); err == nil {
+		t.Error("expected an existing key not to be overwritten")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := LoadIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.String() != id.String() {
+		t.Error("loaded identity differs from saved one")
+	}
+}
+
+func TestReadRecipients(t *testing.T) {
+	a, b := mustIdentity(t), mustIdentity(t)
+	path := filepath.Join(t.TempDir(), RecipientsFile)
+	os.WriteFile(path, []byte("# team\n"+a.Recipient().String()+"\n\n"+b.Recipient().String()+"  # ci\n"), 0644)
+
+	_, err := ReadRecipients(path)
+	if err == nil || !strings.Contains(err.Error(), ":4: malformed recipient") {
+		t.Fatalf("expected trailing comment to be rejected with a line number, got: %v", err)
+	}
+
+	os.WriteFile(path, []byte("# team\n"+a.Recipient().String()+"\n\n"+b.Recipient().String()+"\n"), 0644)
+	rs, err := ReadRecipients(path)
+	if err != nil || len(rs) != 2 {
+		t.Fatalf("ReadRecipients() = %v, %v", rs, err)
+	}
+
+	rs, err = ReadRecipients(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || rs != nil {
+		t.Errorf("expected no recipients for a missing file, got %v, %v", rs, err)
+	}
+}
@@ -0,0 +1,90 @@
+package envcrypt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+)
+
+// RecipientsFile is the per-project list of public keys that encrypted env
+// files are readable by.
+const RecipientsFile = ".hatch-recipients"
+
+// IdentityPath returns ~/.hatch/env.key.
+func IdentityPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "env.key"), nil
+}
+
+// LoadIdentity reads the identity at path.
+func LoadIdentity(path string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, secretKeyPrefix) {
+			id, err := ParseIdentity(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return id, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no secret key found", path)
+}
+
+// SaveIdentity writes id to path with 0600 permissions. It fails if the
+// file already exists, so a key that encrypted files depend on is never
+// silently replaced.
+func SaveIdentity(path string, id *Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("# public key: %s\n%s\n", id.Recipient(), id)
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadRecipients reads one public key per line from path, skipping blank
+// lines and # comments. A missing file yields no recipients.
+func ReadRecipients(path string) ([]Recipient, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Recipient
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := ParseRecipient(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		out = append(out, r)
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,67 @@
+// Package envexport renders env vars in formats that other tools consume:
+// JSON, YAML, shell, dotenv, docker env-files, and Kubernetes secrets.
+//
+// Each format is an Encoder registered under a name; new formats only need
+// a Register call.
+package envexport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+// Meta describes where the vars come from, for formats that need a name.
+type Meta struct {
+	App string
+}
+
+// Encoder writes vars in one format.
+type Encoder interface {
+	Encode(w io.Writer, vars []api.EnvVar, meta Meta) error
+}
+
+// EncoderFunc adapts a function to the Encoder interface.
+type EncoderFunc func(w io.Writer, vars []api.EnvVar, meta Meta) error
+
+// Encode calls f.
+func (f EncoderFunc) Encode(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	return f(w, vars, meta)
+}
+
+var encoders = map[string]Encoder{}
+
+// Register makes an encoder available under name, replacing any existing one.
+func Register(name string, e Encoder) {
+	encoders[name] = e
+}
+
+// Get returns the encoder registered under name.
+func Get(name string) (Encoder, error) {
+	e, ok := encoders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (valid: %v)", name, Names())
+	}
+	return e, nil
+}
+
+// Names returns the registered format names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("json", EncoderFunc(encodeJSON))
+	Register("yaml", EncoderFunc(encodeYAML))
+	Register("sh", EncoderFunc(encodeShell))
+	Register("dotenv", EncoderFunc(encodeDotenv))
+	Register("docker", EncoderFunc(encodeDocker))
+	Register("k8s-secret", EncoderFunc(encodeK8sSecret))
+}
@@ -0,0 +1,129 @@
+package envexport
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var fixtures = map[string][]api.EnvVar{
+	"basic": {
+		{Key: "PORT", Value: "8080"},
+		{Key: "NODE_ENV", Value: "production"},
+		{Key: "EMPTY", Value: ""},
+		{Key: "ON", Value: "true"},
+		{Key: "ZIP", Value: "007"},
+		{Key: "GREETING", Value: `it's "hi" from $HOME <b>`},
+		{Key: "UNICODE", Value: "héllo ✓"},
+	},
+	"multiline": {
+		{Key: "TLS_CERT", Value: "-----BEGIN CERT-----\nabc\n-----END CERT-----"},
+		{Key: "MIXED", Value: "line1\nit's $x"},
+	},
+}
+
+func TestEncoders_Golden(t *testing.T) {
+	for _, format := range Names() {
+		for name, vars := range fixtures {
+			if format == "docker" && name == "multiline" {
+				continue // rejected, see TestDocker_RejectsNewlines
+			}
+			t.Run(format+"/"+name, func(t *testing.T) {
+				enc, err := Get(format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				if err := enc.Encode(&buf, vars, Meta{App: "My_App"}); err != nil {
+					t.Fatalf("Encode() error: %v", err)
+				}
+
+				golden := filepath.Join("testdata", format+"_"+name+".golden")
+				if *update {
+					if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("reading golden file (run with -update to create it): %v", err)
+				}
+				if buf.String() != string(want) {
+					t.Errorf("output differs from %s:\ngot:\n%s\nwant:\n%s", golden, buf.String(), want)
+				}
+			})
+		}
+	}
+}
+
+func TestEncoders_Empty(t *testing.T) {
+	for _, format := range Names() {
+		enc, _ := Get(format)
+		var buf bytes.Buffer
+		if err := enc.Encode(&buf, nil, Meta{App: "app"}); err != nil {
+			t.Errorf("%s: Encode(nil) error: %v", format, err)
+		}
+	}
+}
+
+func TestDotenv_RoundTrip(t *testing.T) {
+	for name, vars := range fixtures {
+		var buf bytes.Buffer
+		if err := encodeDotenv(&buf, vars, Meta{}); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := dotenv.Parse(&buf, dotenv.Options{})
+		if err != nil {
+			t.Fatalf("%s: parsing exported dotenv: %v", name, err)
+		}
+		if len(entries) != len(vars) {
+			t.Fatalf("%s: got %d entries, want %d", name, len(entries), len(vars))
+		}
+		for i, e := range entries {
+			if e.Key != vars[i].Key || e.Value != vars[i].Value {
+				t.Errorf("%s: entry %d = %s=%q, want %s=%q", name, i, e.Key, e.Value, vars[i].Key, vars[i].Value)
+			}
+		}
+	}
+}
+
+func TestDocker_RejectsNewlines(t *testing.T) {
+	err := encodeDocker(&bytes.Buffer{}, fixtures["multiline"], Meta{})
+	if err == nil || !strings.Contains(err.Error(), "TLS_CERT contains a newline") {
+		t.Fatalf("expected newline error, got: %v", err)
+	}
+}
+
+func TestGet_UnknownFormat(t *testing.T) {
+	_, err := Get("xml")
+	if err == nil || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Fatalf("expected unknown format error, got: %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-upper", EncoderFunc(func(w io.Writer, vars []api.EnvVar, meta Meta) error {
+		_, err := io.WriteString(w, strings.ToUpper(vars[0].Value))
+		return err
+	}))
+	defer delete(encoders, "test-upper")
+
+	enc, err := Get("test-upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc.Encode(&buf, []api.EnvVar{{Key: "A", Value: "abc"}}, Meta{})
+	if buf.String() != "ABC" {
+		t.Errorf("got %q", buf.String())
+	}
+}
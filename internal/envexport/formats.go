@@ -0,0 +1,144 @@
+package envexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/dotenv"
+)
+
+// encodeJSON writes a single object mapping keys to values.
+func encodeJSON(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+		b.WriteString(jsonString(v.Key))
+		b.WriteString(": ")
+		b.WriteString(jsonString(v.Value))
+	}
+	if len(vars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonString quotes s as a JSON string without HTML escaping. The result is
+// also a valid YAML double-quoted scalar.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yamlReservedKey matches keys that YAML 1.1 parsers read as booleans or null.
+var yamlReservedKey = regexp.MustCompile(`^(?i:y|yes|n|no|true|false|on|off|null)$`)
+
+func yamlKey(key string) string {
+	if yamlReservedKey.MatchString(key) {
+		return jsonString(key)
+	}
+	return key
+}
+
+// encodeYAML writes a flat mapping with every value double-quoted, so no
+// value is ever reinterpreted as a number, boolean, or null.
+func encodeYAML(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	if len(vars) == 0 {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "%s: %s\n", yamlKey(v.Key), jsonString(v.Value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encodeShell writes export statements that POSIX shells can source.
+func encodeShell(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "export %s=%s\n", v.Key, shellQuote(v.Value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shellQuote wraps s in single quotes, closing and reopening the quoting
+// around each embedded single quote.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// encodeDotenv writes a .env file readable by the dotenv package.
+func encodeDotenv(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	entries := make([]dotenv.Entry, 0, len(vars))
+	for _, v := range vars {
+		entries = append(entries, dotenv.Entry{Key: v.Key, Value: v.Value})
+	}
+	_, err := io.WriteString(w, dotenv.Format(entries))
+	return err
+}
+
+// encodeDocker writes a file for `docker run --env-file` and compose's
+// env_file. Docker takes everything after "=" literally and has no way to
+// express a newline, so such values are rejected rather than corrupted.
+func encodeDocker(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	var b strings.Builder
+	for _, v := range vars {
+		if strings.ContainsAny(v.Value, "\n\r") {
+			return fmt.Errorf("%s contains a newline, which docker env files cannot represent (use --format dotenv)", v.Key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Key, v.Value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// k8sNameInvalid matches characters not allowed in a Kubernetes object name.
+var k8sNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// encodeK8sSecret writes an Opaque Secret manifest named <app>-env.
+func encodeK8sSecret(w io.Writer, vars []api.EnvVar, meta Meta) error {
+	name := strings.Trim(k8sNameInvalid.ReplaceAllString(strings.ToLower(meta.App), "-"), "-")
+	if name == "" {
+		name = "hatch"
+	}
+
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\n")
+	b.WriteString("kind: Secret\n")
+	b.WriteString("metadata:\n")
+	fmt.Fprintf(&b, "  name: %s-env\n", name)
+	b.WriteString("type: Opaque\n")
+	if len(vars) == 0 {
+		b.WriteString("data: {}\n")
+	} else {
+		b.WriteString("data:\n")
+		for _, v := range vars {
+			encoded := base64.StdEncoding.EncodeToString([]byte(v.Value))
+			if encoded == "" {
+				// A bare empty value would be read as null
+				encoded = `""`
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", yamlKey(v.Key), encoded)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,299 @@
+// Package envschema declares which environment variables an app needs and
+// checks an egg's variables against that declaration before a deploy.
+//
+// Rules come from the [env.required] table of .hatch.toml:
+//
+//	[env.required]
+//	STRIPE_SECRET = {}
+//	DATABASE_URL = "url"
+//	PORT = { format = "int", default = "8080" }
+//	LOG_LEVEL = { pattern = "^(debug|info|warn|error)$", default = "info" }
+//
+// and from a .env.schema file with one key per line, followed by optional
+// url, int, regex:<pattern>, and default=<value> fields:
+//
+//	STRIPE_SECRET
+//	DATABASE_URL  url
+//	PORT          int  default=8080
+//
+// A key declared in both places takes its rule from .env.schema.
+package envschema
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+// SchemaFile is the name of the standalone schema file.
+const SchemaFile = ".env.schema"
+
+// Value formats a rule can require.
+const (
+	FormatURL   = "url"
+	FormatInt   = "int"
+	FormatRegex = "regex"
+)
+
+// Rule describes one required variable.
+type Rule struct {
+	Key     string
+	Format  string // "", FormatURL, FormatInt, or FormatRegex
+	Pattern string // for FormatRegex
+	Default string
+	// HasDefault distinguishes an empty default from none.
+	HasDefault bool
+
+	re *regexp.Regexp
+}
+
+// Schema is the set of rules for an app, sorted by key.
+type Schema struct {
+	Rules []Rule
+}
+
+// Load reads the schema from .hatch.toml and .env.schema in dir. It returns
+// nil and no error if neither declares any rules.
+func Load(dir string) (*Schema, error) {
+	rules := map[string]Rule{}
+
+	if err := loadToml(filepath.Join(dir, ".hatch.toml"), rules); err != nil {
+		return nil, err
+	}
+	if err := loadSchemaFile(filepath.Join(dir, SchemaFile), rules); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	s := &Schema{}
+	for _, r := range rules {
+		s.Rules = append(s.Rules, r)
+	}
+	sort.Slice(s.Rules, func(i, j int) bool { return s.Rules[i].Key < s.Rules[j].Key })
+	return s, nil
+}
+
+type tomlRule struct {
+	Format  string  `toml:"format"`
+	Pattern string  `toml:"pattern"`
+	Default *string `toml:"default"`
+}
+
+func loadToml(path string, rules map[string]Rule) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var cfg struct {
+		Env struct {
+			Required map[string]toml.Primitive `toml:"required"`
+		} `toml:"env"`
+	}
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return fmt.Errorf("parsing .hatch.toml: %w", err)
+	}
+
+	for key, prim := range cfg.Env.Required {
+		if err := api.ValidateEnvKey(key); err != nil {
+			return fmt.Errorf(".hatch.toml: env.required: %w", err)
+		}
+		var tr tomlRule
+		// A plain string is shorthand for the format
+		if err := md.PrimitiveDecode(prim, &tr.Format); err != nil {
+			tr = tomlRule{}
+			if err := md.PrimitiveDecode(prim, &tr); err != nil {
+				return fmt.Errorf(".hatch.toml: env.required.%s: %w", key, err)
+			}
+		}
+		r := Rule{Key: key, Format: tr.Format, Pattern: tr.Pattern}
+		if tr.Default != nil {
+			r.Default, r.HasDefault = *tr.Default, true
+		}
+		if err := r.compile(); err != nil {
+			return fmt.Errorf(".hatch.toml: env.required.%s: %w", key, err)
+		}
+		rules[key] = r
+	}
+	return nil
+}
+
+func loadSchemaFile(path string, rules map[string]Rule) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseSchemaLine(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", SchemaFile, n, err)
+		}
+		rules[r.Key] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", SchemaFile, err)
+	}
+	return nil
+}
+
+func parseSchemaLine(line string) (Rule, error) {
+	fields := strings.Fields(line)
+	r := Rule{Key: fields[0]}
+	if err := api.ValidateEnvKey(r.Key); err != nil {
+		return r, err
+	}
+	for _, field := range fields[1:] {
+		switch {
+		case field == FormatURL || field == FormatInt:
+			r.Format = field
+		case strings.HasPrefix(field, FormatRegex+":"):
+			r.Format, r.Pattern = FormatRegex, strings.TrimPrefix(field, FormatRegex+":")
+		case strings.HasPrefix(field, "default="):
+			r.Default, r.HasDefault = strings.TrimPrefix(field, "default="), true
+		default:
+			return r, fmt.Errorf("unknown field %q for %s (expected url, int, regex:<pattern>, or default=<value>)", field, r.Key)
+		}
+	}
+	return r, r.compile()
+}
+
+// compile validates the rule and prepares its pattern. A pattern without a
+// format implies FormatRegex.
+func (r *Rule) compile() error {
+	if r.Pattern != "" && r.Format == "" {
+		r.Format = FormatRegex
+	}
+	switch r.Format {
+	case "", FormatURL, FormatInt:
+		if r.Pattern != "" {
+			return fmt.Errorf("pattern is only allowed with format %q", FormatRegex)
+		}
+	case FormatRegex:
+		if r.Pattern == "" {
+			return fmt.Errorf("format %q needs a pattern", FormatRegex)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.re = re
+	default:
+		return fmt.Errorf("unknown format %q (valid: url, int, regex)", r.Format)
+	}
+	if r.HasDefault {
+		if err := r.validate(r.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// validate checks value against the rule's format. Errors never include
+// the value, which may be a secret.
+func (r *Rule) validate(value string) error {
+	switch r.Format {
+	case FormatURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("not a valid url")
+		}
+	case FormatInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("not an integer")
+		}
+	case FormatRegex:
+		if !r.re.MatchString(value) {
+			return fmt.Errorf("does not match %s", r.Pattern)
+		}
+	}
+	return nil
+}
+
+// Problem is one variable that fails its rule.
+type Problem struct {
+	Key    string
+	Reason string
+}
+
+// Result is the outcome of a Check.
+type Result struct {
+	// Problems lists missing and malformed keys, sorted by key.
+	Problems []Problem
+	// Defaults holds the missing keys that have a default, to be set on the
+	// egg before deploying.
+	Defaults map[string]string
+}
+
+// Check compares vars with the schema. Keys that are unset or empty count
+// as missing.
+func (s *Schema) Check(vars []api.EnvVar) *Result {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		values[v.Key] = v.Value
+	}
+
+	res := &Result{Defaults: map[string]string{}}
+	for _, r := range s.Rules {
+		value := values[r.Key]
+		if value == "" {
+			if r.HasDefault {
+				res.Defaults[r.Key] = r.Default
+			} else {
+				res.Problems = append(res.Problems, Problem{Key: r.Key, Reason: "missing"})
+			}
+			continue
+		}
+		if err := r.validate(value); err != nil {
+			res.Problems = append(res.Problems, Problem{Key: r.Key, Reason: err.Error()})
+		}
+	}
+	return res
+}
+
+// DefaultKeys returns the keys in Defaults, sorted.
+func (r *Result) DefaultKeys() []string {
+	keys := make([]string, 0, len(r.Defaults))
+	for key := range r.Defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Err returns an error listing every problem, or nil if there are none.
+func (r *Result) Err() error {
+	if len(r.Problems) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(r.Problems))
+	for _, p := range r.Problems {
+		lines = append(lines, fmt.Sprintf("  %s: %s", p.Key, p.Reason))
+	}
+	return fmt.Errorf("%d env var(s) do not match the schema:\n%s", len(r.Problems), strings.Join(lines, "\n"))
+}
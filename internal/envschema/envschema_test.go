@@ -0,0 +1,149 @@
+package envschema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_None(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".hatch.toml": "[app]\nslug = \"myapp\"\n"})
+	s, err := Load(dir)
+	if err != nil || s != nil {
+		t.Fatalf("expected no schema, got %+v, %v", s, err)
+	}
+}
+
+func TestLoad_TomlAndSchemaFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".hatch.toml": `[app]
+slug = "myapp"
+
+[env.required]
+STRIPE_SECRET = {}
+DATABASE_URL = "url"
+PORT = { format = "int", default = "8080" }
+LOG_LEVEL = { pattern = "^(debug|info)$", default = "info" }
+`,
+		".env.schema": `# overrides PORT from .hatch.toml
+PORT  int
+SLUG  regex:^[a-z-]+$
+`,
+	})
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var keys []string
+	rules := map[string]Rule{}
+	for _, r := range s.Rules {
+		keys = append(keys, r.Key)
+		rules[r.Key] = r
+	}
+	if strings.Join(keys, ",") != "DATABASE_URL,LOG_LEVEL,PORT,SLUG,STRIPE_SECRET" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	if rules["DATABASE_URL"].Format != FormatURL {
+		t.Errorf("expected string shorthand to set the format, got %+v", rules["DATABASE_URL"])
+	}
+	if rules["LOG_LEVEL"].Format != FormatRegex || rules["LOG_LEVEL"].Default != "info" {
+		t.Errorf("expected pattern to imply regex, got %+v", rules["LOG_LEVEL"])
+	}
+	if rules["PORT"].HasDefault {
+		t.Errorf("expected .env.schema to override .hatch.toml, got %+v", rules["PORT"])
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"unknown format", map[string]string{".hatch.toml": "[env.required]\nA = \"email\"\n"}, `unknown format "email"`},
+		{"bad pattern", map[string]string{".hatch.toml": "[env.required]\nA = { pattern = \"[\" }\n"}, "invalid pattern"},
+		{"bad default", map[string]string{".hatch.toml": "[env.required]\nA = { format = \"int\", default = \"x\" }\n"}, "default: not an integer"},
+		{"bad key", map[string]string{".env.schema": "1A\n"}, "invalid env key"},
+		{"unknown field", map[string]string{".env.schema": "A\nB email\n"}, ".env.schema:2: unknown field \"email\""},
+		{"regex without pattern", map[string]string{".env.schema": "A regex:\n"}, "needs a pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFiles(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".env.schema": `
+STRIPE_SECRET
+EMPTY_ONE
+DATABASE_URL  url
+PORT          int  default=8080
+WORKERS       int
+LOG_LEVEL     regex:^(debug|info)$  default=info
+`})
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := s.Check([]api.EnvVar{
+		{Key: "EMPTY_ONE", Value: ""},
+		{Key: "DATABASE_URL", Value: "localhost:5432"},
+		{Key: "WORKERS", Value: "four"},
+		{Key: "LOG_LEVEL", Value: "info"},
+	})
+
+	want := []Problem{
+		{"DATABASE_URL", "not a valid url"},
+		{"EMPTY_ONE", "missing"},
+		{"STRIPE_SECRET", "missing"},
+		{"WORKERS", "not an integer"},
+	}
+	if len(res.Problems) != len(want) {
+		t.Fatalf("got problems %+v, want %+v", res.Problems, want)
+	}
+	for i, p := range res.Problems {
+		if p != want[i] {
+			t.Errorf("problem %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+	if len(res.Defaults) != 1 || res.Defaults["PORT"] != "8080" {
+		t.Errorf("expected only PORT default, got %v", res.Defaults)
+	}
+
+	err = res.Err()
+	if err == nil || !strings.Contains(err.Error(), "4 env var(s) do not match the schema") || !strings.Contains(err.Error(), "  WORKERS: not an integer") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Contains(err.Error(), "four") || strings.Contains(err.Error(), "localhost") {
+		t.Errorf("values must not appear in errors: %v", err)
+	}
+}
+
+func TestCheck_OK(t *testing.T) {
+	s := &Schema{Rules: []Rule{{Key: "A", Format: FormatURL}}}
+	res := s.Check([]api.EnvVar{{Key: "A", Value: "postgres://u:p@db:5432/app"}})
+	if err := res.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
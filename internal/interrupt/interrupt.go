@@ -0,0 +1,95 @@
+// Package interrupt turns SIGINT and SIGTERM into context cancellation, so a
+// Ctrl-C stops in-flight API calls, streams and tunnels cleanly.
+package interrupt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Grace is how long a command may take to return after the first signal
+// before the process exits anyway. Commands blocked on something that does
+// not watch the context, such as a prompt, end this way.
+var Grace = 3 * time.Second
+
+// exit is replaced in tests.
+var exit = os.Exit
+
+// holds counts the Hold calls not yet released.
+var holds atomic.Int32
+
+// Context returns a copy of parent that is cancelled on the first SIGINT or
+// SIGTERM. After that, a second signal exits the process with status 130 at
+// once, as does still running after Grace, unless a Hold is in effect. Call
+// stop once the command returns.
+func Context(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigCh:
+		case <-done:
+			return
+		}
+		cancel()
+		t := time.NewTimer(Grace)
+		defer t.Stop()
+		for {
+			select {
+			case <-sigCh:
+				if holds.Load() == 0 {
+					exit(130)
+					return
+				}
+			case <-t.C:
+				if holds.Load() == 0 {
+					exit(130)
+					return
+				}
+				t.Reset(Grace)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// Hold keeps signals from exiting the process until release is called.
+// Commands that hand the terminal to a child process, which gets the same
+// Ctrl-C and decides for itself when to stop, hold so that their cleanup
+// still runs afterwards.
+func Hold() (release func()) {
+	holds.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { holds.Add(-1) })
+	}
+}
+
+// FromCommand returns the context a command was executed with, or
+// context.Background when there is none, as when tests call a RunE directly.
+func FromCommand(cmd *cobra.Command) context.Context {
+	if cmd == nil || cmd.Context() == nil {
+		return context.Background()
+	}
+	return cmd.Context()
+}
@@ -0,0 +1,94 @@
+package interrupt
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestContext_CancelsOnSignal(t *testing.T) {
+	exited := make(chan int, 1)
+	defer func(g time.Duration) { Grace, exit = g, os.Exit }(Grace)
+	Grace = 50 * time.Millisecond
+	exit = func(code int) { exited <- code }
+
+	ctx, stop := Context(context.Background())
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send SIGINT on this platform: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled by SIGINT")
+	}
+	select {
+	case code := <-exited:
+		if code != 130 {
+			t.Errorf("exit code = %d, want 130", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("process did not exit after the grace period")
+	}
+}
+
+func TestContext_StopBeforeSignal(t *testing.T) {
+	defer func() { exit = os.Exit }()
+	exit = func(code int) { t.Errorf("unexpected exit(%d)", code) }
+
+	ctx, stop := Context(context.Background())
+	if ctx.Err() != nil {
+		t.Fatal("context cancelled before any signal")
+	}
+	stop()
+	stop()
+	if ctx.Err() == nil {
+		t.Error("stop did not cancel the context")
+	}
+}
+
+func TestFromCommand_Nil(t *testing.T) {
+	if FromCommand(nil) == nil {
+		t.Error("FromCommand(nil) returned nil")
+	}
+}
+
+func TestContext_HoldPostponesExit(t *testing.T) {
+	exited := make(chan int, 1)
+	defer func(g time.Duration) { Grace, exit = g, os.Exit }(Grace)
+	Grace = 20 * time.Millisecond
+	exit = func(code int) { exited <- code }
+
+	release := Hold()
+	_, stop := Context(context.Background())
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send SIGINT on this platform: %v", err)
+	}
+
+	time.Sleep(2 * Grace)
+	p.Signal(os.Interrupt)
+	select {
+	case <-exited:
+		t.Fatal("exited while held")
+	case <-time.After(10 * Grace):
+	}
+	release()
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not exit after the hold was released")
+	}
+}
@@ -0,0 +1,113 @@
+package logalert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// actionTimeout bounds how long a command or webhook may take.
+const actionTimeout = 30 * time.Second
+
+// webhookPayload is the JSON body POSTed to a rule's webhook.
+type webhookPayload struct {
+	Rule      string    `json:"rule"`
+	Pattern   string    `json:"pattern"`
+	Threshold int       `json:"threshold"`
+	Window    string    `json:"window"`
+	Count     int       `json:"count"`
+	Time      time.Time `json:"time"`
+	Matches   []Match   `json:"matches"`
+}
+
+// Run performs the firing's actions: the command, then the webhook. Both
+// are attempted even if the first fails. Cancelling ctx stops them.
+func Run(ctx context.Context, f Firing) error {
+	var errs []error
+	if f.Rule.Exec != "" {
+		if err := runExec(ctx, f); err != nil {
+			errs = append(errs, fmt.Errorf("exec: %w", err))
+		}
+	}
+	if f.Rule.Webhook != "" {
+		if err := postWebhook(ctx, f); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runExec runs the rule's command through the shell with the matching lines
+// on stdin, one per line, and the firing described in HATCH_ALERT_*
+// variables.
+func runExec(ctx context.Context, f Firing) error {
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", f.Rule.Exec)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", f.Rule.Exec)
+	}
+	var stdin strings.Builder
+	eggs := map[string]bool{}
+	var eggList []string
+	for _, m := range f.Matches {
+		stdin.WriteString(m.Line + "\n")
+		if !eggs[m.Egg] {
+			eggs[m.Egg] = true
+			eggList = append(eggList, m.Egg)
+		}
+	}
+	cmd.Stdin = strings.NewReader(stdin.String())
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"HATCH_ALERT_RULE="+f.Rule.Name,
+		"HATCH_ALERT_COUNT="+strconv.Itoa(f.Count),
+		"HATCH_ALERT_EGGS="+strings.Join(eggList, ","),
+	)
+	return cmd.Run()
+}
+
+func postWebhook(ctx context.Context, f Firing) error {
+	body, err := json.Marshal(webhookPayload{
+		Rule:      f.Rule.Name,
+		Pattern:   f.Rule.Pattern,
+		Threshold: f.Rule.Threshold,
+		Window:    f.Rule.Window.String(),
+		Count:     f.Count,
+		Time:      f.Time,
+		Matches:   f.Matches,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.Rule.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: actionTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", f.Rule.Webhook, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package logalert watches log lines for patterns and runs an action when a
+// pattern matches often enough within a time window.
+//
+// Rules come from --alert-on flags or from [[logs.alerts]] tables in
+// .hatch.toml:
+//
+//	[[logs.alerts]]
+//	name = "panics"
+//	pattern = "panic:"
+//	exec = "notify-send 'hatch: panic'"
+//
+//	[[logs.alerts]]
+//	pattern = "ECONNREFUSED"
+//	threshold = 5
+//	window = "1m"
+//	cooldown = "15m"
+//	webhook = "https://hooks.example.com/alerts"
+package logalert
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Defaults for rule fields left unset.
+const (
+	DefaultThreshold = 1
+	DefaultWindow    = time.Minute
+	DefaultCooldown  = 5 * time.Minute
+)
+
+// maxLines caps how many matching lines are kept for one firing.
+const maxLines = 100
+
+// Rule fires when Pattern matches at least Threshold lines within Window,
+// then stays quiet for Cooldown.
+type Rule struct {
+	Name      string
+	Pattern   string
+	Threshold int
+	Window    time.Duration
+	Cooldown  time.Duration
+	// Exec is a shell command run with the matching lines on stdin.
+	Exec string
+	// Webhook is a URL the firing is POSTed to as JSON.
+	Webhook string
+
+	re *regexp.Regexp
+}
+
+// Compile fills in defaults and checks the rule.
+func (r *Rule) Compile() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	r.re = re
+	if r.Name == "" {
+		r.Name = r.Pattern
+	}
+	if r.Exec == "" && r.Webhook == "" {
+		return fmt.Errorf("rule %q needs exec or webhook", r.Name)
+	}
+	if r.Threshold < 0 || r.Window < 0 || r.Cooldown < 0 {
+		return fmt.Errorf("rule %q: threshold, window and cooldown cannot be negative", r.Name)
+	}
+	if r.Threshold == 0 {
+		r.Threshold = DefaultThreshold
+	}
+	if r.Window == 0 {
+		r.Window = DefaultWindow
+	}
+	if r.Cooldown == 0 {
+		r.Cooldown = DefaultCooldown
+	}
+	return nil
+}
+
+type tomlRule struct {
+	Name      string `toml:"name"`
+	Pattern   string `toml:"pattern"`
+	Threshold int    `toml:"threshold"`
+	Window    string `toml:"window"`
+	Cooldown  string `toml:"cooldown"`
+	Exec      string `toml:"exec"`
+	Webhook   string `toml:"webhook"`
+}
+
+// Load reads the [[logs.alerts]] rules from .hatch.toml in dir. A missing
+// file has no rules.
+func Load(dir string) ([]*Rule, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".hatch.toml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg struct {
+		Logs struct {
+			Alerts []tomlRule `toml:"alerts"`
+		} `toml:"logs"`
+	}
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, fmt.Errorf("parsing .hatch.toml: %w", err)
+	}
+
+	var rules []*Rule
+	for i, tr := range cfg.Logs.Alerts {
+		r := &Rule{Name: tr.Name, Pattern: tr.Pattern, Threshold: tr.Threshold, Exec: tr.Exec, Webhook: tr.Webhook}
+		if tr.Window != "" {
+			if r.Window, err = time.ParseDuration(tr.Window); err != nil {
+				return nil, fmt.Errorf(".hatch.toml: logs.alerts[%d]: window: %w", i, err)
+			}
+		}
+		if tr.Cooldown != "" {
+			if r.Cooldown, err = time.ParseDuration(tr.Cooldown); err != nil {
+				return nil, fmt.Errorf(".hatch.toml: logs.alerts[%d]: cooldown: %w", i, err)
+			}
+		}
+		if err := r.Compile(); err != nil {
+			return nil, fmt.Errorf(".hatch.toml: logs.alerts[%d]: %w", i, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Match is a line that matched a rule.
+type Match struct {
+	Time time.Time `json:"time"`
+	Egg  string    `json:"egg"`
+	Line string    `json:"line"`
+}
+
+// Firing is a rule reaching its threshold.
+type Firing struct {
+	Rule *Rule
+	Time time.Time
+	// Matches are the lines that matched within the window, oldest first,
+	// at most the last 100.
+	Matches []Match
+	// Count is how many lines matched within the window.
+	Count int
+}
+
+type ruleState struct {
+	rule    *Rule
+	matches []Match
+	times   []time.Time
+	quiet   time.Time // no firing before this
+}
+
+// Alerter evaluates rules against log lines. It is safe for concurrent use.
+type Alerter struct {
+	mu     sync.Mutex
+	states []*ruleState
+	now    func() time.Time
+}
+
+// New returns an Alerter for compiled rules.
+func New(rules []*Rule) *Alerter {
+	a := &Alerter{now: time.Now}
+	for _, r := range rules {
+		a.states = append(a.states, &ruleState{rule: r})
+	}
+	return a
+}
+
+// Observe checks a line from egg against every rule and returns the rules
+// that fire because of it.
+func (a *Alerter) Observe(egg, line string) []Firing {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	var fired []Firing
+	for _, s := range a.states {
+		if !s.rule.re.MatchString(line) {
+			continue
+		}
+		s.prune(now)
+		s.times = append(s.times, now)
+		s.matches = append(s.matches, Match{Time: now, Egg: egg, Line: line})
+		if len(s.matches) > maxLines {
+			s.matches = s.matches[len(s.matches)-maxLines:]
+		}
+		if len(s.times) < s.rule.Threshold || now.Before(s.quiet) {
+			continue
+		}
+		fired = append(fired, Firing{Rule: s.rule, Time: now, Matches: s.matches, Count: len(s.times)})
+		s.times, s.matches = nil, nil
+		s.quiet = now.Add(s.rule.Cooldown)
+	}
+	return fired
+}
+
+// prune forgets matches that fell out of the window.
+func (s *ruleState) prune(now time.Time) {
+	cutoff := now.Add(-s.rule.Window)
+	i := 0
+	for i < len(s.times) && !s.times[i].After(cutoff) {
+		i++
+	}
+	s.times = s.times[i:]
+	j := 0
+	for j < len(s.matches) && !s.matches[j].Time.After(cutoff) {
+		j++
+	}
+	s.matches = s.matches[j:]
+}
@@ -0,0 +1,222 @@
+package logalert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	type step struct {
+		after time.Duration // since the previous line
+		line  string
+		fires int // count of the firing, 0 for none
+	}
+	tests := []struct {
+		name  string
+		rule  Rule
+		steps []step
+	}{
+		{
+			name: "fires on every match after the cooldown",
+			rule: Rule{Pattern: "panic:", Exec: "true", Cooldown: time.Minute},
+			steps: []step{
+				{0, "ok", 0},
+				{time.Second, "panic: nil map", 1},
+				{time.Second, "panic: again", 0},
+				{2 * time.Minute, "panic: later", 1},
+			},
+		},
+		{
+			name: "threshold within window",
+			rule: Rule{Pattern: "ECONNREFUSED", Threshold: 3, Window: 10 * time.Second, Exec: "true"},
+			steps: []step{
+				{0, "connect ECONNREFUSED 10.0.0.1", 0},
+				{time.Second, "connect ECONNREFUSED 10.0.0.1", 0},
+				{20 * time.Second, "connect ECONNREFUSED 10.0.0.1", 0},
+				{time.Second, "connect ECONNREFUSED 10.0.0.1", 0},
+				{time.Second, "connect ECONNREFUSED 10.0.0.1", 3},
+			},
+		},
+		{
+			name: "matches during the cooldown count toward the next firing",
+			rule: Rule{Pattern: "timeout", Threshold: 2, Window: time.Minute, Cooldown: 30 * time.Second, Exec: "true"},
+			steps: []step{
+				{0, "timeout", 0},
+				{time.Second, "timeout", 2},
+				{10 * time.Second, "timeout", 0},
+				{10 * time.Second, "timeout", 0},
+				{15 * time.Second, "timeout", 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.rule
+			if err := r.Compile(); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+			a := New([]*Rule{&r})
+			a.now = func() time.Time { return now }
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				fired := a.Observe("web", s.line)
+				switch {
+				case s.fires == 0 && len(fired) > 0:
+					t.Errorf("step %d: unexpected firing %+v", i, fired[0])
+				case s.fires > 0 && len(fired) != 1:
+					t.Errorf("step %d: expected a firing", i)
+				case s.fires > 0 && (fired[0].Count != s.fires || len(fired[0].Matches) != s.fires):
+					t.Errorf("step %d: count %d with %d lines, want %d", i, fired[0].Count, len(fired[0].Matches), s.fires)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		toml    string
+		want    []Rule
+		wantErr string
+	}{
+		{
+			name: "rules with defaults",
+			toml: `
+[app]
+slug = "web"
+
+[[logs.alerts]]
+pattern = "panic:"
+exec = "notify-send panic"
+
+[[logs.alerts]]
+name = "db down"
+pattern = "ECONNREFUSED"
+threshold = 5
+window = "30s"
+cooldown = "15m"
+webhook = "https://hooks.example.com/x"
+`,
+			want: []Rule{
+				{Name: "panic:", Pattern: "panic:", Threshold: 1, Window: time.Minute, Cooldown: 5 * time.Minute, Exec: "notify-send panic"},
+				{Name: "db down", Pattern: "ECONNREFUSED", Threshold: 5, Window: 30 * time.Second, Cooldown: 15 * time.Minute, Webhook: "https://hooks.example.com/x"},
+			},
+		},
+		{name: "no alerts", toml: "slug = \"web\"\n"},
+		{name: "no action", toml: "[[logs.alerts]]\npattern = \"x\"\n", wantErr: "logs.alerts[0]: rule \"x\" needs exec or webhook"},
+		{name: "bad pattern", toml: "[[logs.alerts]]\npattern = \"(\"\nexec = \"true\"\n", wantErr: "invalid pattern"},
+		{name: "bad window", toml: "[[logs.alerts]]\npattern = \"x\"\nexec = \"true\"\nwindow = \"5\"\n", wantErr: "logs.alerts[0]: window"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ".hatch.toml"), []byte(tt.toml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rules, err := Load(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rules) != len(tt.want) {
+				t.Fatalf("got %d rules, want %d", len(rules), len(tt.want))
+			}
+			for i, r := range rules {
+				got := *r
+				got.re = nil
+				if got != tt.want[i] {
+					t.Errorf("rule %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if rules, err := Load(t.TempDir()); err != nil || rules != nil {
+		t.Errorf("missing file: got %v, %v", rules, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	var payload webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "alert.txt")
+	r := &Rule{
+		Name:    "panics",
+		Pattern: "panic:",
+		Exec:    `{ echo "$HATCH_ALERT_RULE $HATCH_ALERT_COUNT $HATCH_ALERT_EGGS"; cat; } > ` + out,
+		Webhook: srv.URL,
+	}
+	if err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	f := Firing{Rule: r, Time: at, Count: 2, Matches: []Match{
+		{Time: at, Egg: "web", Line: "panic: one"},
+		{Time: at, Egg: "api", Line: "panic: two"},
+	}}
+	if err := Run(context.Background(), f); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "panics 2 web,api\npanic: one\npanic: two\n" {
+		t.Errorf("unexpected command input: %q", data)
+	}
+	if payload.Rule != "panics" || payload.Count != 2 || len(payload.Matches) != 2 || payload.Matches[1].Egg != "api" {
+		t.Errorf("unexpected webhook payload: %+v", payload)
+	}
+
+	r.Exec = "exit 3"
+	r.Webhook = srv.URL + "/missing"
+	srv.Config.Handler = http.NotFoundHandler()
+	err = Run(context.Background(), f)
+	if err == nil || !strings.Contains(err.Error(), "exec: exit status 3") || !strings.Contains(err.Error(), "webhook:") {
+		t.Errorf("expected both actions to fail, got: %v", err)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	r := &Rule{Pattern: "x", Exec: "exec sleep 10"}
+	if err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if err := Run(ctx, Firing{Rule: r}); err == nil {
+		t.Fatal("expected the cancelled command to fail")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Run kept going after cancel: %s", time.Since(start))
+	}
+}
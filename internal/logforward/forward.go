@@ -0,0 +1,319 @@
+// Package logforward ships egg log lines to observability backends: an
+// OpenTelemetry collector (OTLP over HTTP), Grafana Loki, or syslog.
+//
+// Lines become Records with egg, stream and level attributes. A Forwarder
+// batches them per sink, retries failed batches with backoff, and keeps
+// counters that Health reports over HTTP.
+package logforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+)
+
+// Record is one log line with the attributes sinks index by.
+type Record struct {
+	Time    time.Time
+	Egg     string
+	Stream  string
+	Level   logparse.Level
+	Message string
+	// Fields are the extra key/value pairs of a structured line.
+	Fields []logparse.Field
+}
+
+// NewRecord parses line from the given egg and stream. Lines without a
+// timestamp are stamped with received.
+func NewRecord(egg, stream, line string, received time.Time) Record {
+	e := logparse.Parse(line)
+	r := Record{Time: e.Time, Egg: egg, Stream: stream, Level: e.Level, Message: line, Fields: e.Fields}
+	if r.Time.IsZero() {
+		r.Time = received
+	}
+	return r
+}
+
+// Sink delivers batches of records to one backend. Send gives up when ctx
+// is done.
+type Sink interface {
+	Send(ctx context.Context, records []Record) error
+}
+
+// permanentError marks a send failure that retrying will not fix, such as
+// a rejected request.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// SinkFactory creates a sink for a destination URL.
+type SinkFactory func(u *url.URL) (Sink, error)
+
+var factories = map[string]SinkFactory{}
+
+// Register makes a sink available for a URL scheme, replacing any existing
+// one.
+func Register(scheme string, f SinkFactory) {
+	factories[scheme] = f
+}
+
+// Schemes returns the registered URL schemes, sorted.
+func Schemes() []string {
+	schemes := make([]string, 0, len(factories))
+	for s := range factories {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// NewSink creates the sink for a destination such as
+// "loki://localhost:3100" or "syslog+tcp://logs.internal:601".
+func NewSink(dest string) (Sink, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid destination %q: want scheme://host:port (schemes: %s)", dest, strings.Join(Schemes(), ", "))
+	}
+	f, ok := factories[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unsupported destination scheme %q (valid: %s)", u.Scheme, strings.Join(Schemes(), ", "))
+	}
+	return f(u)
+}
+
+// Options tunes batching and retries. Zero values use the defaults.
+type Options struct {
+	// BatchSize is the most records sent in one request (default 500).
+	BatchSize int
+	// FlushInterval bounds how long a record waits for its batch to fill
+	// (default 1s).
+	FlushInterval time.Duration
+	// QueueSize is how many records wait per sink while it is slow or
+	// down; beyond that new records are dropped (default 10000).
+	QueueSize int
+	// MaxAttempts is how often a batch is tried before it is dropped
+	// (default 5).
+	MaxAttempts int
+	// RetryDelay is the first backoff delay, doubled per attempt up to 30s
+	// (default 500ms).
+	RetryDelay time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = 500 * time.Millisecond
+	}
+	return o
+}
+
+const maxRetryDelay = 30 * time.Second
+
+// SinkStats are the counters of one sink.
+type SinkStats struct {
+	Destination string    `json:"destination"`
+	Sent        int64     `json:"sent"`
+	Dropped     int64     `json:"dropped"`
+	Queued      int       `json:"queued"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	// Healthy is false while the most recent batch could not be delivered.
+	Healthy bool `json:"healthy"`
+}
+
+// Forwarder fans records out to sinks, each with its own queue, batching
+// and retries, so that one slow backend does not hold up the others.
+type Forwarder struct {
+	opts    Options
+	workers []*worker
+	wg      sync.WaitGroup
+	// ctx is cancelled when Close runs out of time, which stops sends and
+	// retry backoffs in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type worker struct {
+	sink  Sink
+	queue chan Record
+	mu    sync.Mutex
+	stats SinkStats
+}
+
+// NewForwarder starts a worker per sink. sinks maps each destination, as
+// shown in stats, to its sink.
+func NewForwarder(sinks map[string]Sink, opts Options) *Forwarder {
+	f := &Forwarder{opts: opts.withDefaults()}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	dests := make([]string, 0, len(sinks))
+	for d := range sinks {
+		dests = append(dests, d)
+	}
+	sort.Strings(dests)
+	for _, d := range dests {
+		w := &worker{
+			sink:  sinks[d],
+			queue: make(chan Record, f.opts.QueueSize),
+			stats: SinkStats{Destination: d, Healthy: true},
+		}
+		f.workers = append(f.workers, w)
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			f.run(w)
+		}()
+	}
+	return f
+}
+
+// Add queues a record for every sink. It never blocks: a sink whose queue
+// is full drops the record and counts it. Records added after Close are
+// ignored.
+func (f *Forwarder) Add(r Record) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+	for _, w := range f.workers {
+		select {
+		case w.queue <- r:
+		default:
+			w.mu.Lock()
+			w.stats.Dropped++
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Close sends what is still queued and waits for the workers to finish.
+// When ctx is done first, sends and retries in progress are abandoned and
+// the records that were not delivered are counted as dropped.
+func (f *Forwarder) Close(ctx context.Context) {
+	f.mu.Lock()
+	if !f.closed {
+		f.closed = true
+		for _, w := range f.workers {
+			close(w.queue)
+		}
+	}
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		f.cancel()
+		<-done
+	}
+	f.cancel()
+}
+
+// Stats returns the counters of every sink, sorted by destination.
+func (f *Forwarder) Stats() []SinkStats {
+	stats := make([]SinkStats, len(f.workers))
+	for i, w := range f.workers {
+		w.mu.Lock()
+		stats[i] = w.stats
+		w.mu.Unlock()
+		stats[i].Queued = len(w.queue)
+	}
+	return stats
+}
+
+func (f *Forwarder) run(w *worker) {
+	ticker := time.NewTicker(f.opts.FlushInterval)
+	defer ticker.Stop()
+	var batch []Record
+	for {
+		select {
+		case r, ok := <-w.queue:
+			if !ok {
+				f.flush(w, batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) >= f.opts.BatchSize {
+				f.flush(w, batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			f.flush(w, batch)
+			batch = nil
+		}
+	}
+}
+
+// flush sends batch, retrying with jittered exponential backoff. A batch
+// that still fails, or is abandoned by Close, is dropped and counted.
+func (f *Forwarder) flush(w *worker, batch []Record) {
+	if len(batch) == 0 {
+		return
+	}
+	delay := f.opts.RetryDelay
+	var err error
+	for attempt := 1; attempt <= f.opts.MaxAttempts; attempt++ {
+		if err = w.sink.Send(f.ctx, batch); err == nil {
+			break
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) || attempt == f.opts.MaxAttempts {
+			break
+		}
+		if !wait(f.ctx, delay/2+time.Duration(rand.Int63n(int64(delay)))) {
+			break
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.stats.Dropped += int64(len(batch))
+		w.stats.LastError = err.Error()
+		w.stats.Healthy = false
+		return
+	}
+	w.stats.Sent += int64(len(batch))
+	w.stats.LastSuccess = time.Now()
+	w.stats.Healthy = true
+}
+
+// wait sleeps for d and reports whether it did so before ctx was done.
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
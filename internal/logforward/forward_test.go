@@ -0,0 +1,377 @@
+package logforward
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var received = time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+func testRecords() []Record {
+	return []Record{
+		NewRecord("web", "stdout", `{"time":"2025-06-15T10:00:01Z","level":"info","msg":"ok","route":"/"}`, received),
+		NewRecord("web", "stderr", `{"level":"error","msg":"boom"}`, received),
+		NewRecord("worker", "stdout", "plain line", received),
+	}
+}
+
+// capture is a stand-in HTTP receiver that records request bodies.
+type capture struct {
+	mu     sync.Mutex
+	paths  []string
+	bodies [][]byte
+}
+
+func (c *capture) handler(status func(n int) int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		c.mu.Lock()
+		c.paths = append(c.paths, r.URL.Path)
+		c.bodies = append(c.bodies, body)
+		n := len(c.bodies)
+		c.mu.Unlock()
+		w.WriteHeader(status(n))
+	}
+}
+
+func ok(int) int { return http.StatusOK }
+
+func sinkFor(t *testing.T, dest string) Sink {
+	t.Helper()
+	s, err := NewSink(dest)
+	if err != nil {
+		t.Fatalf("NewSink(%q): %v", dest, err)
+	}
+	return s
+}
+
+func TestOTLPSink(t *testing.T) {
+	c := &capture{}
+	srv := httptest.NewServer(c.handler(ok))
+	defer srv.Close()
+
+	s := sinkFor(t, "otlp://"+strings.TrimPrefix(srv.URL, "http://"))
+	if err := s.Send(context.Background(), testRecords()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if c.paths[0] != "/v1/logs" {
+		t.Errorf("path = %q, want /v1/logs", c.paths[0])
+	}
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []otlpAttr `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []otlpRecord `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(c.bodies[0], &req); err != nil {
+		t.Fatalf("invalid OTLP JSON: %v", err)
+	}
+	if len(req.ResourceLogs) != 2 || req.ResourceLogs[0].Resource.Attributes[0].Value.StringValue != "web" {
+		t.Fatalf("expected one resource per egg, got: %s", c.bodies[0])
+	}
+	recs := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 web records, got %d", len(recs))
+	}
+	if recs[0].TimeUnixNano != "1749981601000000000" || recs[0].SeverityNumber != 9 || recs[0].SeverityText != "INFO" {
+		t.Errorf("unexpected first record: %+v", recs[0])
+	}
+	if recs[1].SeverityNumber != 17 {
+		t.Errorf("expected error severity 17, got %d", recs[1].SeverityNumber)
+	}
+	attrs := map[string]string{}
+	for _, a := range recs[0].Attributes {
+		attrs[a.Key] = a.Value.StringValue
+	}
+	if attrs["hatch.egg"] != "web" || attrs["hatch.stream"] != "stdout" || attrs["route"] != "/" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestLokiSink(t *testing.T) {
+	c := &capture{}
+	srv := httptest.NewServer(c.handler(ok))
+	defer srv.Close()
+
+	s := sinkFor(t, "loki://"+strings.TrimPrefix(srv.URL, "http://"))
+	if err := s.Send(context.Background(), testRecords()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if c.paths[0] != "/loki/api/v1/push" {
+		t.Errorf("path = %q, want /loki/api/v1/push", c.paths[0])
+	}
+	var req struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(c.bodies[0], &req); err != nil {
+		t.Fatalf("invalid Loki JSON: %v", err)
+	}
+	if len(req.Streams) != 3 {
+		t.Fatalf("expected 3 label sets, got: %s", c.bodies[0])
+	}
+	got := map[string]string{}
+	for _, st := range req.Streams {
+		got[st.Stream["egg"]+"/"+st.Stream["stream"]+"/"+st.Stream["level"]] = st.Values[0][0]
+	}
+	want := map[string]string{
+		"web/stdout/info":       "1749981601000000000",
+		"web/stderr/error":      strconv.FormatInt(received.UnixNano(), 10),
+		"worker/stdout/unknown": strconv.FormatInt(received.UnixNano(), 10),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("stream %s: timestamp %q, want %q (all: %v)", k, got[k], v, got)
+		}
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := sinkFor(t, "syslog://"+pc.LocalAddr().String())
+	if err := s.Send(context.Background(), testRecords()[1:2]); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// user facility (1) * 8 + error severity (3) = 11
+	if !strings.HasPrefix(msg, "<11>1 2025-06-15T10:00:00Z ") || !strings.Contains(msg, " web - stderr - {\"level\":\"error\"") {
+		t.Errorf("unexpected syslog message: %q", msg)
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var got []string
+		for len(got) < 3 {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			got = append(got, string(msg))
+		}
+		frames <- got
+	}()
+
+	s := sinkFor(t, "syslog+tcp://"+ln.Addr().String())
+	if err := s.Send(context.Background(), testRecords()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	select {
+	case got := <-frames:
+		if len(got) != 3 || !strings.HasSuffix(got[2], " worker - stdout - plain line") {
+			t.Errorf("unexpected frames: %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for syslog frames")
+	}
+}
+
+func TestForwarderRetriesAndDrops(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       func(n int) int
+		wantRequests int
+		wantSent     int64
+		wantDropped  int64
+		wantHealthy  bool
+	}{
+		{
+			name: "retries server errors",
+			status: func(n int) int {
+				if n == 1 {
+					return http.StatusServiceUnavailable
+				}
+				return http.StatusOK
+			},
+			wantRequests: 2,
+			wantSent:     3,
+			wantHealthy:  true,
+		},
+		{
+			name:         "drops rejected batches without retrying",
+			status:       func(int) int { return http.StatusBadRequest },
+			wantRequests: 1,
+			wantDropped:  3,
+		},
+		{
+			name:         "gives up after max attempts",
+			status:       func(int) int { return http.StatusBadGateway },
+			wantRequests: 3,
+			wantDropped:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &capture{}
+			srv := httptest.NewServer(c.handler(tt.status))
+			defer srv.Close()
+
+			dest := "loki://" + strings.TrimPrefix(srv.URL, "http://")
+			f := NewForwarder(map[string]Sink{dest: sinkFor(t, dest)}, Options{
+				BatchSize:     10,
+				FlushInterval: time.Hour,
+				MaxAttempts:   3,
+				RetryDelay:    time.Millisecond,
+			})
+			for _, r := range testRecords() {
+				f.Add(r)
+			}
+			f.Close(context.Background())
+
+			if len(c.bodies) != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", len(c.bodies), tt.wantRequests)
+			}
+			st := f.Stats()[0]
+			if st.Sent != tt.wantSent || st.Dropped != tt.wantDropped || st.Healthy != tt.wantHealthy {
+				t.Errorf("stats = %+v, want sent %d dropped %d healthy %v", st, tt.wantSent, tt.wantDropped, tt.wantHealthy)
+			}
+		})
+	}
+}
+
+func TestForwarderCloseDeadline(t *testing.T) {
+	c := &capture{}
+	srv := httptest.NewServer(c.handler(func(int) int { return http.StatusBadGateway }))
+	defer srv.Close()
+
+	dest := "loki://" + strings.TrimPrefix(srv.URL, "http://")
+	f := NewForwarder(map[string]Sink{dest: sinkFor(t, dest)}, Options{
+		FlushInterval: time.Hour,
+		RetryDelay:    time.Minute,
+	})
+	for _, r := range testRecords() {
+		f.Add(r)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	f.Close(ctx)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close took %s, want it to stop at the deadline", elapsed)
+	}
+	if st := f.Stats()[0]; st.Dropped != 3 || st.Healthy {
+		t.Errorf("stats = %+v, want the abandoned batch dropped", st)
+	}
+}
+
+func TestForwarderBatches(t *testing.T) {
+	c := &capture{}
+	srv := httptest.NewServer(c.handler(ok))
+	defer srv.Close()
+
+	dest := "otlp://" + strings.TrimPrefix(srv.URL, "http://")
+	f := NewForwarder(map[string]Sink{dest: sinkFor(t, dest)}, Options{BatchSize: 2, FlushInterval: time.Hour})
+	for i := 0; i < 5; i++ {
+		f.Add(testRecords()[0])
+	}
+	f.Close(context.Background())
+	f.Add(testRecords()[0]) // ignored after Close
+	f.Close(context.Background())
+
+	if len(c.bodies) != 3 {
+		t.Errorf("expected batches of 2, 2 and 1, got %d requests", len(c.bodies))
+	}
+	if st := f.Stats()[0]; st.Sent != 5 {
+		t.Errorf("sent = %d, want 5", st.Sent)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	c := &capture{}
+	srv := httptest.NewServer(c.handler(ok))
+	defer srv.Close()
+	dest := "loki://" + strings.TrimPrefix(srv.URL, "http://")
+	f := NewForwarder(map[string]Sink{dest: sinkFor(t, dest)}, Options{})
+	defer f.Close(context.Background())
+
+	h := NewHealth(f)
+	h.Connected("web/stdout")
+	h.Line("web/stdout")
+	h.Connected("api/stdout")
+
+	get := func() (int, Report) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		var r Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+			t.Fatalf("invalid health JSON: %v", err)
+		}
+		return rec.Code, r
+	}
+
+	code, r := get()
+	if code != http.StatusOK || r.Status != "ok" || len(r.Streams) != 2 || r.Streams[1].Lines != 1 || len(r.Sinks) != 1 {
+		t.Errorf("unexpected healthy report (%d): %+v", code, r)
+	}
+
+	h.Disconnected("api/stdout", io.ErrUnexpectedEOF)
+	code, r = get()
+	if code != http.StatusServiceUnavailable || r.Status != "degraded" || r.Streams[0].LastError != "unexpected EOF" {
+		t.Errorf("unexpected degraded report (%d): %+v", code, r)
+	}
+}
+
+func TestNewSinkErrors(t *testing.T) {
+	tests := []struct {
+		dest string
+		want string
+	}{
+		{"localhost:4318", "invalid destination"},
+		{"kafka://localhost:9092", "unsupported destination scheme"},
+		{"otlp://localhost:4317", "use the OTLP/HTTP receiver, usually on port 4318"},
+	}
+	for _, tt := range tests {
+		if _, err := NewSink(tt.dest); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewSink(%q) error = %v, want %q", tt.dest, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package logforward
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StreamStatus is the state of one egg log stream feeding the forwarder.
+type StreamStatus struct {
+	Name      string    `json:"name"`
+	Connected bool      `json:"connected"`
+	Lines     int64     `json:"lines"`
+	LastLine  time.Time `json:"last_line,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Health tracks the input streams and reports them together with the
+// forwarder's sink counters. It is an http.Handler: the response is 200
+// with status "ok" when every stream is connected and every sink delivered
+// its last batch, and 503 with status "degraded" otherwise.
+type Health struct {
+	fwd     *Forwarder
+	mu      sync.Mutex
+	streams map[string]*StreamStatus
+}
+
+// NewHealth returns a Health reporting on fwd.
+func NewHealth(fwd *Forwarder) *Health {
+	return &Health{fwd: fwd, streams: map[string]*StreamStatus{}}
+}
+
+func (h *Health) stream(name string) *StreamStatus {
+	s, ok := h.streams[name]
+	if !ok {
+		s = &StreamStatus{Name: name}
+		h.streams[name] = s
+	}
+	return s
+}
+
+// Connected records that the named stream is receiving lines.
+func (h *Health) Connected(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stream(name).Connected = true
+}
+
+// Line counts a line received on the named stream.
+func (h *Health) Line(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.stream(name)
+	s.Connected = true
+	s.Lines++
+	s.LastLine = time.Now()
+}
+
+// Disconnected records that the named stream dropped, with the reason.
+func (h *Health) Disconnected(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.stream(name)
+	s.Connected = false
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// Report is the body served by Health.
+type Report struct {
+	Status  string         `json:"status"`
+	Streams []StreamStatus `json:"streams"`
+	Sinks   []SinkStats    `json:"sinks"`
+}
+
+// Report returns the current state.
+func (h *Health) Report() Report {
+	h.mu.Lock()
+	r := Report{Status: "ok", Streams: make([]StreamStatus, 0, len(h.streams))}
+	for _, s := range h.streams {
+		r.Streams = append(r.Streams, *s)
+		if !s.Connected {
+			r.Status = "degraded"
+		}
+	}
+	h.mu.Unlock()
+	sort.Slice(r.Streams, func(i, j int) bool { return r.Streams[i].Name < r.Streams[j].Name })
+
+	r.Sinks = h.fwd.Stats()
+	for _, s := range r.Sinks {
+		if !s.Healthy {
+			r.Status = "degraded"
+		}
+	}
+	return r
+}
+
+func (h *Health) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r := h.Report()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(r)
+}
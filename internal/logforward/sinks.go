@@ -0,0 +1,311 @@
+package logforward
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+)
+
+func init() {
+	Register("otlp", newOTLPSink)
+	Register("otlps", newOTLPSink)
+	Register("loki", newLokiSink)
+	Register("lokis", newLokiSink)
+	Register("syslog", newSyslogSink)
+	Register("syslog+udp", newSyslogSink)
+	Register("syslog+tcp", newSyslogSink)
+}
+
+const sendTimeout = 10 * time.Second
+
+// httpSink posts JSON batches to an HTTP endpoint.
+type httpSink struct {
+	url    string
+	client *http.Client
+	encode func(records []Record) any
+}
+
+func (s *httpSink) Send(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(s.encode(records))
+	if err != nil {
+		return &permanentError{fmt.Errorf("encoding batch: %w", err)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("%s returned %d: %s", s.url, resp.StatusCode, strings.TrimSpace(string(data)))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return &permanentError{err}
+		}
+		return err
+	}
+	return nil
+}
+
+// endpoint maps a sink URL to http(s)://host[path], using defaultPath when
+// the URL has none. Schemes ending in "s" use HTTPS.
+func endpoint(u *url.URL, defaultPath string) string {
+	scheme := "http"
+	if strings.HasSuffix(u.Scheme, "s") {
+		scheme = "https"
+	}
+	path := u.Path
+	if path == "" || path == "/" {
+		path = defaultPath
+	}
+	return (&url.URL{Scheme: scheme, Host: u.Host, Path: path, RawQuery: u.RawQuery}).String()
+}
+
+// --- OTLP ---
+
+// newOTLPSink sends OTLP/HTTP with JSON encoding, which every OpenTelemetry
+// collector accepts on its HTTP receiver (port 4318 by default).
+func newOTLPSink(u *url.URL) (Sink, error) {
+	// A collector's gRPC receiver would take every batch as a malformed
+	// HTTP/2 request, so catch the common mistake up front.
+	if u.Port() == "4317" {
+		return nil, fmt.Errorf("%s: port 4317 is the OTLP/gRPC receiver, which is not supported; use the OTLP/HTTP receiver, usually on port 4318", u.Redacted())
+	}
+	return &httpSink{
+		url:    endpoint(u, "/v1/logs"),
+		client: &http.Client{Timeout: sendTimeout},
+		encode: encodeOTLP,
+	}, nil
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber,omitempty"`
+	SeverityText         string     `json:"severityText,omitempty"`
+	Body                 otlpValue  `json:"body"`
+	Attributes           []otlpAttr `json:"attributes"`
+}
+
+// otlpSeverity maps levels to the OTLP SeverityNumber ranges.
+var otlpSeverity = map[logparse.Level]int{
+	logparse.LevelTrace: 1,
+	logparse.LevelDebug: 5,
+	logparse.LevelInfo:  9,
+	logparse.LevelWarn:  13,
+	logparse.LevelError: 17,
+	logparse.LevelFatal: 21,
+}
+
+// encodeOTLP builds an ExportLogsServiceRequest with one resource per egg.
+func encodeOTLP(records []Record) any {
+	type scopeLogs struct {
+		Scope      map[string]string `json:"scope"`
+		LogRecords []otlpRecord      `json:"logRecords"`
+	}
+	type resourceLogs struct {
+		Resource  map[string][]otlpAttr `json:"resource"`
+		ScopeLogs []scopeLogs           `json:"scopeLogs"`
+	}
+
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	byEgg := map[string][]otlpRecord{}
+	var eggs []string
+	for _, r := range records {
+		if _, ok := byEgg[r.Egg]; !ok {
+			eggs = append(eggs, r.Egg)
+		}
+		attrs := []otlpAttr{
+			{Key: "hatch.egg", Value: otlpValue{r.Egg}},
+			{Key: "hatch.stream", Value: otlpValue{r.Stream}},
+		}
+		for _, f := range r.Fields {
+			attrs = append(attrs, otlpAttr{Key: f.Key, Value: otlpValue{f.Value}})
+		}
+		byEgg[r.Egg] = append(byEgg[r.Egg], otlpRecord{
+			TimeUnixNano:         strconv.FormatInt(r.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverity[r.Level],
+			SeverityText:         strings.ToUpper(r.Level.String()),
+			Body:                 otlpValue{r.Message},
+			Attributes:           attrs,
+		})
+	}
+
+	resources := make([]resourceLogs, 0, len(eggs))
+	for _, egg := range eggs {
+		resources = append(resources, resourceLogs{
+			Resource: map[string][]otlpAttr{"attributes": {{Key: "service.name", Value: otlpValue{egg}}}},
+			ScopeLogs: []scopeLogs{{
+				Scope:      map[string]string{"name": "hatch-cli"},
+				LogRecords: byEgg[egg],
+			}},
+		})
+	}
+	return map[string]any{"resourceLogs": resources}
+}
+
+// --- Loki ---
+
+func newLokiSink(u *url.URL) (Sink, error) {
+	return &httpSink{
+		url:    endpoint(u, "/loki/api/v1/push"),
+		client: &http.Client{Timeout: sendTimeout},
+		encode: encodeLoki,
+	}, nil
+}
+
+// encodeLoki builds a push request with one stream per egg, stream and
+// level, keeping the label set small as Loki recommends.
+func encodeLoki(records []Record) any {
+	type lokiStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	streams := map[string]*lokiStream{}
+	var keys []string
+	for _, r := range records {
+		level := r.Level.String()
+		if level == "" {
+			level = "unknown"
+		}
+		key := r.Egg + "\x00" + r.Stream + "\x00" + level
+		s, ok := streams[key]
+		if !ok {
+			s = &lokiStream{Stream: map[string]string{"egg": r.Egg, "stream": r.Stream, "level": level}}
+			streams[key] = s
+			keys = append(keys, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(r.Time.UnixNano(), 10), r.Message})
+	}
+	sort.Strings(keys)
+	out := make([]*lokiStream, len(keys))
+	for i, k := range keys {
+		out[i] = streams[k]
+	}
+	return map[string]any{"streams": out}
+}
+
+// --- syslog ---
+
+// syslogSink writes RFC 5424 messages over UDP, one datagram each, or over
+// TCP with octet-counting framing (RFC 6587).
+type syslogSink struct {
+	network string
+	addr    string
+	host    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogSink(u *url.URL) (Sink, error) {
+	network := "udp"
+	if u.Scheme == "syslog+tcp" {
+		network = "tcp"
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "514")
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+	return &syslogSink{network: network, addr: addr, host: host}, nil
+}
+
+// syslogSeverity maps levels to syslog severities; lines without a level
+// are informational.
+var syslogSeverity = map[logparse.Level]int{
+	logparse.LevelNone:  6,
+	logparse.LevelTrace: 7,
+	logparse.LevelDebug: 7,
+	logparse.LevelInfo:  6,
+	logparse.LevelWarn:  4,
+	logparse.LevelError: 3,
+	logparse.LevelFatal: 2,
+}
+
+// syslogFacilityUser is the "user-level messages" facility.
+const syslogFacilityUser = 1
+
+func (s *syslogSink) Send(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		d := net.Dialer{Timeout: sendTimeout}
+		conn, err := d.DialContext(ctx, s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	deadline := time.Now().Add(sendTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	s.conn.SetWriteDeadline(deadline)
+	for _, r := range records {
+		msg := formatSyslog(r, s.host)
+		if s.network == "tcp" {
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		}
+		if _, err := io.WriteString(s.conn, msg); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSyslog renders r as an RFC 5424 message with the egg as APP-NAME
+// and the stream as MSGID.
+func formatSyslog(r Record, host string) string {
+	pri := syslogFacilityUser*8 + syslogSeverity[r.Level]
+	return fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		pri, r.Time.UTC().Format(time.RFC3339Nano), host, syslogName(r.Egg), syslogName(r.Stream), r.Message)
+}
+
+// syslogName makes s a valid header field: printable ASCII without spaces,
+// or "-" when empty.
+func syslogName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,183 @@
+package logparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Level is a normalized log severity. LevelNone means the line has none.
+type Level int
+
+const (
+	LevelNone Level = iota
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = map[Level]string{
+	LevelTrace: "trace",
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+	LevelFatal: "fatal",
+}
+
+// LevelNames lists the accepted level filter values, lowest first.
+const LevelNames = "trace, debug, info, warn, error, fatal"
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel normalizes the level spellings used by common loggers, e.g.
+// "WARNING" and "warn", or "CRITICAL" and "fatal".
+func ParseLevel(s string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return LevelTrace, true
+	case "debug", "dbug":
+		return LevelDebug, true
+	case "info", "information", "notice":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error", "err", "eror":
+		return LevelError, true
+	case "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency":
+		return LevelFatal, true
+	}
+	return LevelNone, false
+}
+
+// numericLevel maps pino/bunyan numeric levels (10 trace ... 60 fatal).
+func numericLevel(n int64) Level {
+	switch {
+	case n >= 60:
+		return LevelFatal
+	case n >= 50:
+		return LevelError
+	case n >= 40:
+		return LevelWarn
+	case n >= 30:
+		return LevelInfo
+	case n >= 20:
+		return LevelDebug
+	case n >= 10:
+		return LevelTrace
+	}
+	return LevelNone
+}
+
+// Filter selects log lines. The zero Filter matches everything.
+type Filter struct {
+	// MinLevel drops lines below it, and lines without a level, when set.
+	MinLevel Level
+	// Grep must match the raw line.
+	Grep *regexp.Regexp
+	// Fields must all be present with exactly these values.
+	Fields []Field
+	// Since and Until bound the line's timestamp. Lines without one pass.
+	Since time.Time
+	Until time.Time
+}
+
+// NewFilter builds a Filter from user input: a level name, a regular
+// expression and key=value field matches. Empty arguments are ignored.
+func NewFilter(level, grep string, fields []string) (*Filter, error) {
+	f := &Filter{}
+	if level != "" {
+		lvl, ok := ParseLevel(level)
+		if !ok {
+			return nil, fmt.Errorf("unknown level %q (valid: %s)", level, LevelNames)
+		}
+		f.MinLevel = lvl
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+		f.Grep = re
+	}
+	for _, kv := range fields {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid field filter %q: want key=value", kv)
+		}
+		f.Fields = append(f.Fields, Field{Key: key, Value: value})
+	}
+	return f, nil
+}
+
+// Active reports whether the filter can drop any line.
+func (f *Filter) Active() bool {
+	return f.MinLevel != LevelNone || f.Grep != nil || len(f.Fields) > 0 || !f.Since.IsZero() || !f.Until.IsZero()
+}
+
+// Match reports whether e passes every condition of the filter.
+func (f *Filter) Match(e Entry) bool {
+	if f.MinLevel != LevelNone && e.Level < f.MinLevel {
+		return false
+	}
+	if f.Grep != nil && !f.Grep.MatchString(e.Raw) {
+		return false
+	}
+	for _, want := range f.Fields {
+		if got, ok := e.Field(want.Key); !ok || got != want.Value {
+			return false
+		}
+	}
+	if !e.Time.IsZero() {
+		if !f.Since.IsZero() && e.Time.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// localTimeLayouts are the zone-less layouts ParseTime reads as local time.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime reads a point in time given either as a duration before now
+// ("15m", "2h", "3d") or as a timestamp: RFC 3339, or a local date with an
+// optional time such as "2025-06-15T10:00".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid time %q: duration must be positive", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 15m or 2h, or a timestamp like 2025-06-15T10:00:00Z", s)
+}
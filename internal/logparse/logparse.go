@@ -0,0 +1,312 @@
+// Package logparse recognizes the common shapes of application log lines
+// (JSON, logfmt and the plain-text layouts of popular frameworks) and
+// filters them by level, pattern, field and time. The CLI and the MCP server
+// both use it so that a filter means the same thing in either.
+package logparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line formats reported in Entry.Format.
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+	FormatText   = "text"
+)
+
+// Field is one extra key/value pair of a structured log line.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Entry is a parsed log line. Format is empty when nothing was recognized,
+// in which case only Raw is set.
+type Entry struct {
+	Raw     string
+	Format  string
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// Field returns the value of the named field and whether it is present.
+func (e Entry) Field(key string) (string, bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+var (
+	timeKeys    = []string{"time", "timestamp", "ts", "t", "@timestamp", "datetime"}
+	levelKeys   = []string{"level", "lvl", "severity", "log.level", "loglevel"}
+	messageKeys = []string{"msg", "message", "@message", "event"}
+)
+
+// Parse recognizes line. A leading timestamp is taken off first, then the
+// rest is tried as JSON, logfmt and a level-prefixed text line in turn.
+func Parse(line string) Entry {
+	e := Entry{Raw: line}
+	rest := line
+	if ts, after, ok := leadingTime(rest); ok {
+		e.Time, rest = ts, after
+	}
+
+	switch {
+	case parseJSON(&e, rest):
+		e.Format = FormatJSON
+	case parseLogfmt(&e, rest):
+		e.Format = FormatLogfmt
+	default:
+		if m := levelPrefixRe.FindStringSubmatch(rest); m != nil {
+			e.Level, _ = ParseLevel(m[1] + m[2] + m[3])
+			rest = m[4]
+		}
+		if e.Level == LevelNone && e.Time.IsZero() {
+			return e
+		}
+		e.Format = FormatText
+		e.Message = rest
+	}
+	return e
+}
+
+// levelPrefixRe matches "[INFO] msg", "INFO: msg", "INFO:root:msg",
+// "error: msg" and a bare upper-case "WARN msg".
+var levelPrefixRe = regexp.MustCompile(`^(?:\[(?i:(trace|debug|info|warn|warning|error|fatal|critical|panic))\]\s*|(?i:(trace|debug|info|warn|warning|error|fatal|critical|panic)):(?:[\w.]+:)?\s*|(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|CRITICAL|PANIC)\s+)(.*)$`)
+
+// leadingTimeRe matches an ISO 8601 timestamp (optionally bracketed) or the
+// "2006/01/02 15:04:05" prefix of Go's log package.
+var leadingTimeRe = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?|\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)\]?(?:\s+|$)`)
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+}
+
+func leadingTime(s string) (time.Time, string, bool) {
+	m := leadingTimeRe.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, s, false
+	}
+	ts, ok := parseTimestamp(m[1])
+	if !ok {
+		return time.Time{}, s, false
+	}
+	return ts, s[len(m[0]):], true
+}
+
+// parseTimestamp parses the timestamp layouts seen in log lines. Layouts
+// without a zone are read as local time.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.Replace(s, ",", ".", 1)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// epochTime reads a Unix timestamp in seconds, milliseconds or nanoseconds.
+func epochTime(f float64) time.Time {
+	switch {
+	case f > 1e17:
+		return time.Unix(0, int64(f))
+	case f > 1e11:
+		return time.UnixMilli(int64(f))
+	default:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	}
+}
+
+func parseJSON(e *Entry, s string) bool {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return false
+	}
+	var obj map[string]any
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil || dec.More() {
+		return false
+	}
+
+	if key, v, ok := take(obj, timeKeys); ok {
+		switch v := v.(type) {
+		case string:
+			if ts, ok := parseTimestamp(v); ok {
+				e.Time = ts
+			} else {
+				obj[key] = v
+			}
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				e.Time = epochTime(f)
+			}
+		}
+	}
+	if _, v, ok := take(obj, levelKeys); ok {
+		switch v := v.(type) {
+		case string:
+			e.Level, _ = ParseLevel(v)
+		case json.Number:
+			// pino and bunyan use numeric levels.
+			if n, err := v.Int64(); err == nil {
+				e.Level = numericLevel(n)
+			}
+		}
+	}
+	if _, v, ok := take(obj, messageKeys); ok {
+		e.Message = fieldValue(v)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.Fields = append(e.Fields, Field{Key: k, Value: fieldValue(obj[k])})
+	}
+	return true
+}
+
+// take removes and returns the first of keys present in obj.
+func take(obj map[string]any, keys []string) (string, any, bool) {
+	for _, k := range keys {
+		if v, ok := obj[k]; ok {
+			delete(obj, k)
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// fieldValue renders a decoded JSON value compactly: strings as is, other
+// values as JSON.
+func fieldValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// parseLogfmt accepts lines made only of key=value pairs, at least two of
+// them, with optionally double-quoted values.
+func parseLogfmt(e *Entry, s string) bool {
+	pairs, ok := splitLogfmt(s)
+	if !ok || len(pairs) < 2 {
+		return false
+	}
+	for _, p := range pairs {
+		switch {
+		case contains(timeKeys, p.Key) && e.Time.IsZero():
+			if ts, ok := parseTimestamp(p.Value); ok {
+				e.Time = ts
+				continue
+			}
+		case contains(levelKeys, p.Key) && e.Level == LevelNone:
+			if lvl, ok := ParseLevel(p.Value); ok {
+				e.Level = lvl
+				continue
+			}
+		case contains(messageKeys, p.Key) && e.Message == "":
+			e.Message = p.Value
+			continue
+		}
+		e.Fields = append(e.Fields, p)
+	}
+	return true
+}
+
+func splitLogfmt(s string) ([]Field, bool) {
+	var pairs []Field
+	s = strings.TrimSpace(s)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || !isLogfmtKey(s[:eq]) {
+			return nil, false
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := closingQuote(s)
+			if end < 0 {
+				return nil, false
+			}
+			unquoted, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, s = unquoted, s[end+1:]
+			if s != "" && s[0] != ' ' {
+				return nil, false
+			}
+		} else {
+			value, s, _ = strings.Cut(s, " ")
+			if strings.ContainsAny(value, `="`) {
+				return nil, false
+			}
+		}
+		pairs = append(pairs, Field{Key: key, Value: value})
+		s = strings.TrimLeft(s, " ")
+	}
+	return pairs, true
+}
+
+// closingQuote returns the index of the quote that ends the quoted string s
+// starts with, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func isLogfmtKey(s string) bool {
+	for _, r := range s {
+		if !(r == '_' || r == '.' || r == '-' || r == '@' || r == '/' ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package logparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	ts := time.Date(2025, 6, 15, 10, 0, 1, 0, time.UTC)
+	tests := []struct {
+		name       string
+		line       string
+		wantFormat string
+		wantTime   time.Time
+		wantLevel  Level
+		wantMsg    string
+		wantFields []Field
+	}{
+		{
+			name:       "json",
+			line:       `{"time":"2025-06-15T10:00:01Z","level":"warn","msg":"slow query","ms":812,"db":{"name":"app"}}`,
+			wantFormat: FormatJSON,
+			wantTime:   ts,
+			wantLevel:  LevelWarn,
+			wantMsg:    "slow query",
+			wantFields: []Field{{"db", `{"name":"app"}`}, {"ms", "812"}},
+		},
+		{
+			name:       "pino json with numeric level and epoch millis",
+			line:       `{"level":50,"time":1749981601000,"msg":"boom","reqId":"r1"}`,
+			wantFormat: FormatJSON,
+			wantTime:   ts,
+			wantLevel:  LevelError,
+			wantMsg:    "boom",
+			wantFields: []Field{{"reqId", "r1"}},
+		},
+		{
+			name:       "json after a platform timestamp",
+			line:       `2025-06-15T10:00:01Z {"severity":"ERROR","message":"down"}`,
+			wantFormat: FormatJSON,
+			wantTime:   ts,
+			wantLevel:  LevelError,
+			wantMsg:    "down",
+		},
+		{
+			name:       "logfmt",
+			line:       `ts=2025-06-15T10:00:01Z level=info msg="request done" path=/api status=200`,
+			wantFormat: FormatLogfmt,
+			wantTime:   ts,
+			wantLevel:  LevelInfo,
+			wantMsg:    "request done",
+			wantFields: []Field{{"path", "/api"}, {"status", "200"}},
+		},
+		{
+			name:       "bracketed level",
+			line:       "[ERROR] connection refused",
+			wantFormat: FormatText,
+			wantLevel:  LevelError,
+			wantMsg:    "connection refused",
+		},
+		{
+			name:       "python logging",
+			line:       "WARNING:root:disk almost full",
+			wantFormat: FormatText,
+			wantLevel:  LevelWarn,
+			wantMsg:    "disk almost full",
+		},
+		{
+			name:       "timestamp then level",
+			line:       "2025-06-15T10:00:01Z INFO server listening on :8080",
+			wantFormat: FormatText,
+			wantTime:   ts,
+			wantLevel:  LevelInfo,
+			wantMsg:    "server listening on :8080",
+		},
+		{
+			name: "plain text",
+			line: "GET /health 200",
+		},
+		{
+			name: "key=value inside prose is not logfmt",
+			line: "retrying with timeout=5s because of error",
+		},
+		{
+			name: "lower-case level word without colon is prose",
+			line: "info about the request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Parse(tt.line)
+			if e.Raw != tt.line {
+				t.Errorf("Raw = %q, want the input line", e.Raw)
+			}
+			if e.Format != tt.wantFormat {
+				t.Errorf("Format = %q, want %q", e.Format, tt.wantFormat)
+			}
+			if !e.Time.Equal(tt.wantTime) {
+				t.Errorf("Time = %v, want %v", e.Time, tt.wantTime)
+			}
+			if e.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", e.Level, tt.wantLevel)
+			}
+			if e.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", e.Message, tt.wantMsg)
+			}
+			if !reflect.DeepEqual(e.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", e.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	lines := []string{
+		`{"time":"2025-06-15T10:00:00Z","level":"info","msg":"started","route":"/"}`,
+		`{"time":"2025-06-15T10:20:00Z","level":"warn","msg":"slow","route":"/api"}`,
+		`{"time":"2025-06-15T10:25:00Z","level":"error","msg":"failed","route":"/api"}`,
+		`plain line without a level`,
+	}
+	tests := []struct {
+		name   string
+		level  string
+		grep   string
+		fields []string
+		since  string
+		want   []int
+	}{
+		{name: "no filter", want: []int{0, 1, 2, 3}},
+		{name: "level drops lower and unleveled lines", level: "warn", want: []int{1, 2}},
+		{name: "grep", grep: `slow|plain`, want: []int{1, 3}},
+		{name: "field", fields: []string{"route=/api"}, want: []int{1, 2}},
+		{name: "fields combine", fields: []string{"route=/api", "level=error"}, want: []int{}},
+		{name: "since keeps lines without a timestamp", since: "15m", want: []int{1, 2, 3}},
+		{name: "all combined", level: "warn", grep: "fail", fields: []string{"route=/api"}, since: "1h", want: []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.level, tt.grep, tt.fields)
+			if err != nil {
+				t.Fatalf("NewFilter: %v", err)
+			}
+			if tt.since != "" {
+				if f.Since, err = ParseTime(tt.since, now); err != nil {
+					t.Fatalf("ParseTime: %v", err)
+				}
+			}
+			got := []int{}
+			for i, line := range lines {
+				if f.Match(Parse(line)) {
+					got = append(got, i)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matched lines %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterErrors(t *testing.T) {
+	tests := []struct {
+		level, grep string
+		fields      []string
+		want        string
+	}{
+		{level: "loud", want: "unknown level"},
+		{grep: "(", want: "invalid grep pattern"},
+		{fields: []string{"route"}, want: "want key=value"},
+	}
+	for _, tt := range tests {
+		_, err := NewFilter(tt.level, tt.grep, tt.fields)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewFilter(%q, %q, %q) error = %v, want %q", tt.level, tt.grep, tt.fields, err, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "15m", want: now.Add(-15 * time.Minute)},
+		{in: "2h30m", want: now.Add(-150 * time.Minute)},
+		{in: "3d", want: now.AddDate(0, 0, -3)},
+		{in: "2025-06-15T09:00:00Z", want: time.Date(2025, 6, 15, 9, 0, 0, 0, time.UTC)},
+		{in: "2025-06-15T09:00", want: time.Date(2025, 6, 15, 9, 0, 0, 0, time.Local)},
+		{in: "2025-06-15", want: time.Date(2025, 6, 15, 0, 0, 0, 0, time.Local)},
+		{in: "-5m", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package logstream keeps a followed log stream alive across dropped
+// connections, resuming where it left off without repeating lines.
+package logstream
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+const (
+	defaultBaseDelay = 500 * time.Millisecond
+	defaultMaxDelay  = 30 * time.Second
+
+	// seenLimit is how many recently delivered lines are remembered to
+	// suppress the replay after a reconnect.
+	seenLimit = 1000
+)
+
+// StreamFunc opens one connection to a log stream and calls handler for each
+// line until the stream ends. since is the timestamp of the last delivered
+// line, or zero on the first connection and when lines carry no timestamp.
+// It should return once ctx is done.
+type StreamFunc func(ctx context.Context, since time.Time, handler func(line string)) error
+
+// Follower runs a StreamFunc until it fails permanently, reconnecting with
+// jittered exponential backoff whenever the stream drops.
+type Follower struct {
+	Stream StreamFunc
+
+	// MaxReconnects limits consecutive reconnect attempts that deliver no
+	// new line. Negative means no limit; 0 disables reconnecting.
+	MaxReconnects int
+
+	// OnReconnect, if set, is called before waiting to reconnect.
+	OnReconnect func(attempt int, delay time.Duration, err error)
+
+	// BaseDelay and MaxDelay bound the backoff. Zero uses 500ms and 30s.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Sleep waits between attempts and must return early once ctx is done.
+	// Nil uses the package's Sleep.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Sleep waits for d, or returns ctx's error as soon as ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run delivers lines to handler and returns when the stream fails with a
+// non-retryable error, MaxReconnects is exceeded or ctx is done. A stream the
+// server closes normally is reconnected too, since a sleeping egg closes its
+// log stream that way.
+func (f *Follower) Run(ctx context.Context, handler func(line string)) error {
+	base, maxDelay := f.BaseDelay, f.MaxDelay
+	if base <= 0 {
+		base = defaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	sleep := f.Sleep
+	if sleep == nil {
+		sleep = Sleep
+	}
+
+	d := newDeduper()
+	attempt := 0
+	for {
+		delivered := false
+		err := f.Stream(ctx, d.last, func(line string) {
+			if d.deliver(line) {
+				delivered = true
+				handler(line)
+			}
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !errors.Is(err, api.ErrLogStreamInterrupted) {
+			return err
+		}
+		if delivered {
+			attempt = 0
+		}
+		if f.MaxReconnects >= 0 && attempt >= f.MaxReconnects {
+			if err == nil {
+				return nil
+			}
+			return err
+		}
+		attempt++
+		delay := backoff(base, maxDelay, attempt)
+		if f.OnReconnect != nil {
+			f.OnReconnect(attempt, delay, err)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		d.replaying = true
+	}
+}
+
+// backoff returns the jittered delay before the given attempt (1-based):
+// base doubled per attempt, capped at max, then scaled by 0.5-1.5.
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)))
+}
+
+// deduper remembers recently delivered lines so the tail the server replays
+// after a reconnect is not printed twice.
+type deduper struct {
+	seen      map[string]int
+	order     []string
+	last      time.Time
+	replaying bool
+}
+
+func newDeduper() *deduper {
+	return &deduper{seen: make(map[string]int)}
+}
+
+// deliver reports whether line should be passed on. While replaying, lines
+// already delivered or older than the last timestamp are dropped; the first
+// new line ends the replay, after which everything is passed on.
+func (d *deduper) deliver(line string) bool {
+	ts, hasTS := Timestamp(line)
+	if d.replaying {
+		if d.seen[line] > 0 || (hasTS && ts.Before(d.last)) {
+			return false
+		}
+		d.replaying = false
+	}
+	if hasTS && ts.After(d.last) {
+		d.last = ts
+	}
+	d.seen[line]++
+	d.order = append(d.order, line)
+	if len(d.order) > seenLimit {
+		old := d.order[0]
+		d.order = d.order[1:]
+		if d.seen[old]--; d.seen[old] == 0 {
+			delete(d.seen, old)
+		}
+	}
+	return true
+}
+
+// Timestamp parses the RFC 3339 timestamp a log line starts with, if any.
+func Timestamp(line string) (time.Time, bool) {
+	field, _, _ := strings.Cut(line, " ")
+	field = strings.Trim(field, "[]")
+	ts, err := time.Parse(time.RFC3339Nano, field)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
@@ -0,0 +1,257 @@
+package logstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+)
+
+var (
+	errDropped = fmt.Errorf("%w: connection reset", api.ErrLogStreamInterrupted)
+	errEnd     = errors.New("no more connections")
+)
+
+// script returns a StreamFunc that plays one connection per call and records
+// the since value each connection was opened with. Once the script is played
+// out it fails with errEnd, which is not retryable.
+func script(conns [][]string, errs []error, sinces *[]time.Time) StreamFunc {
+	call := 0
+	return func(ctx context.Context, since time.Time, handler func(string)) error {
+		*sinces = append(*sinces, since)
+		i := call
+		call++
+		if i >= len(conns) {
+			return errEnd
+		}
+		for _, line := range conns[i] {
+			handler(line)
+		}
+		return errs[i]
+	}
+}
+
+func TestFollowerRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		conns         [][]string
+		errs          []error
+		maxReconnects int
+		want          []string
+		wantErr       string
+		wantSleeps    int
+	}{
+		{
+			name: "replayed tail is not printed twice",
+			conns: [][]string{
+				{"a", "b", "c"},
+				{"b", "c", "d"},
+			},
+			errs:          []error{errDropped, nil},
+			maxReconnects: -1,
+			want:          []string{"a", "b", "c", "d"},
+			wantSleeps:    2,
+		},
+		{
+			name: "repeated line after replay is printed",
+			conns: [][]string{
+				{"a", "b"},
+				{"b", "c", "b"},
+			},
+			errs:          []error{errDropped, nil},
+			maxReconnects: -1,
+			want:          []string{"a", "b", "c", "b"},
+			wantSleeps:    2,
+		},
+		{
+			name: "timestamped lines older than the last one are dropped",
+			conns: [][]string{
+				{"2025-06-15T10:00:01Z one", "2025-06-15T10:00:02Z two"},
+				{"2025-06-15T10:00:00Z zero", "2025-06-15T10:00:03Z three"},
+			},
+			errs:          []error{errDropped, nil},
+			maxReconnects: -1,
+			want:          []string{"2025-06-15T10:00:01Z one", "2025-06-15T10:00:02Z two", "2025-06-15T10:00:03Z three"},
+			wantSleeps:    2,
+		},
+		{
+			name:          "non-retryable error is returned",
+			conns:         [][]string{{"a"}},
+			errs:          []error{errors.New("API error 404: not found")},
+			maxReconnects: -1,
+			want:          []string{"a"},
+			wantErr:       "API error 404",
+		},
+		{
+			name:          "gives up after max reconnects without new lines",
+			conns:         [][]string{{"a"}, {"a"}, {}},
+			errs:          []error{errDropped, errDropped, errDropped},
+			maxReconnects: 2,
+			want:          []string{"a"},
+			wantErr:       "log stream interrupted",
+			wantSleeps:    2,
+		},
+		{
+			name:          "zero max reconnects does not reconnect",
+			conns:         [][]string{{"a"}},
+			errs:          []error{errDropped},
+			maxReconnects: 0,
+			want:          []string{"a"},
+			wantErr:       "connection reset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sinces []time.Time
+			sleeps := 0
+			f := &Follower{
+				Stream:        script(tt.conns, tt.errs, &sinces),
+				MaxReconnects: tt.maxReconnects,
+				Sleep:         func(context.Context, time.Duration) error { sleeps++; return nil },
+			}
+			var got []string
+			err := f.Run(context.Background(), func(line string) { got = append(got, line) })
+
+			if tt.wantErr == "" && !errors.Is(err, errEnd) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+			if sleeps != tt.wantSleeps {
+				t.Errorf("sleeps = %d, want %d", sleeps, tt.wantSleeps)
+			}
+		})
+	}
+}
+
+func TestFollowerRun_ResumesFromLastTimestamp(t *testing.T) {
+	var sinces []time.Time
+	f := &Follower{
+		Stream: script([][]string{
+			{"2025-06-15T10:00:01Z one", "2025-06-15T10:00:02.5Z two"},
+			{},
+		}, []error{errDropped, nil}, &sinces),
+		MaxReconnects: 1,
+		Sleep:         func(context.Context, time.Duration) error { return nil },
+	}
+	if err := f.Run(context.Background(), func(string) {}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2025, 6, 15, 10, 0, 2, 500_000_000, time.UTC)
+	if len(sinces) != 2 || !sinces[0].IsZero() || !sinces[1].Equal(want) {
+		t.Fatalf("since values = %v, want [zero %v]", sinces, want)
+	}
+}
+
+func TestFollowerRun_ReportsReconnects(t *testing.T) {
+	var sinces []time.Time
+	var attempts []int
+	f := &Follower{
+		Stream:        script([][]string{{}, {}, {}}, []error{errDropped, errDropped, errDropped}, &sinces),
+		MaxReconnects: 2,
+		OnReconnect: func(attempt int, delay time.Duration, err error) {
+			attempts = append(attempts, attempt)
+		},
+		Sleep: func(context.Context, time.Duration) error { return nil },
+	}
+	f.Run(context.Background(), func(string) {})
+
+	if !reflect.DeepEqual(attempts, []int{1, 2}) {
+		t.Errorf("attempts = %v, want [1 2]", attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			got := backoff(base, max, attempt)
+			if got < want/2 || got >= want*3/2 {
+				t.Fatalf("backoff(attempt %d) = %v, want in [%v, %v)", attempt, got, want/2, want*3/2)
+			}
+		}
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+	}{
+		{"2025-06-15T10:00:00Z server started", true},
+		{"[2025-06-15T10:00:00.123+02:00] request", true},
+		{"server started", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, ok := Timestamp(tt.line); ok != tt.ok {
+			t.Errorf("Timestamp(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+		}
+	}
+}
+
+func TestFollowerRun_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	f := &Follower{
+		Stream: func(ctx context.Context, since time.Time, handler func(string)) error {
+			calls++
+			cancel()
+			return errDropped
+		},
+		MaxReconnects: -1,
+		BaseDelay:     time.Hour,
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx, func(string) {}) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+	if calls != 1 {
+		t.Errorf("stream opened %d times, want 1", calls)
+	}
+}
+
+func TestFollowerRun_StopsDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := &Follower{
+		Stream: func(ctx context.Context, since time.Time, handler func(string)) error {
+			return errDropped
+		},
+		MaxReconnects: -1,
+		BaseDelay:     time.Hour,
+		MaxDelay:      time.Hour,
+		OnReconnect: func(int, time.Duration, error) {
+			time.AfterFunc(20*time.Millisecond, cancel)
+		},
+		Sleep: Sleep,
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx, func(string) {}) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run kept waiting to reconnect after the context was cancelled")
+	}
+}
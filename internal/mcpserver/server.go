@@ -1,13 +1,11 @@
 package mcpserver
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,7 +13,14 @@ import (
 	"time"
 
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/api"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/artifact"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/auth"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/buildlog"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/config"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envcopy"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/envschema"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/logparse"
+	"github.com/EscapeVelocityOperations/hatch-cli/internal/secret"
 	"github.com/EscapeVelocityOperations/hatch-cli/internal/telemetry"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -25,8 +30,13 @@ import (
 var (
 	getTokenFunc = auth.GetToken
 	newAPIClient = func(token string) *api.Client { return api.NewClient(token) }
+	auditLogFunc = config.AppendAuditLog
 )
 
+// revealDisabled is set from Options.DisableReveal. Besides leaving out
+// reveal_env_var, it makes every tool refuse to return a plaintext secret.
+var revealDisabled bool
+
 // redactError applies token redaction to error messages returned to the MCP client.
 func redactError(msg string) string {
 	return api.RedactToken(msg)
@@ -34,22 +44,40 @@ func redactError(msg string) string {
 
 // toolError returns a consistent, redacted error result for MCP tool handlers.
 // All error messages should use the format "failed to {action}: {detail}".
+// When one of the args is an API error, its status, code, request ID and
+// retryability are attached as structured content so agents can branch on
+// them instead of parsing the message.
 func toolError(format string, args ...interface{}) (*mcp.CallToolResult, error) {
 	msg := fmt.Sprintf(format, args...)
 	telemetry.Send("mcp", "", msg, "mcp")
-	return mcp.NewToolResultError(redactError(msg)), nil
+	result := mcp.NewToolResultError(redactError(msg))
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if apiErr, ok := api.AsError(err); ok {
+				result.StructuredContent = map[string]interface{}{"error": apiErrorInfo(apiErr)}
+				break
+			}
+		}
+	}
+	return result, nil
 }
 
-func isNoRunningAllocationError(err error) bool {
-	if err == nil {
-		return false
+// apiErrorInfo is the structured form of an API error in tool results.
+func apiErrorInfo(e *api.Error) map[string]interface{} {
+	info := map[string]interface{}{
+		"status":    e.Status,
+		"code":      e.Code,
+		"message":   e.Message,
+		"retryable": e.Retryable,
 	}
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "no running allocation")
+	if e.RequestID != "" {
+		info["request_id"] = e.RequestID
+	}
+	return info
 }
 
-func noRunningAllocationResult(client *api.Client, slug string) (*mcp.CallToolResult, error) {
-	app, appErr := client.GetApp(slug)
+func noRunningAllocationResult(ctx context.Context, client *api.Client, slug string) (*mcp.CallToolResult, error) {
+	app, appErr := client.GetApp(ctx, slug)
 	if appErr == nil && app != nil {
 		return mcp.NewToolResultText(fmt.Sprintf(
 			"No running allocation for %s. App status: %s. The app likely crashed before or during startup.",
@@ -94,8 +122,16 @@ func validateProjectPath(dir string) error {
 	return nil
 }
 
+// Options configures the MCP server.
+type Options struct {
+	// DisableReveal leaves out the reveal_env_var tool and makes
+	// get_database_url refuse to return the full URL.
+	DisableReveal bool
+}
+
 // NewServer creates the Hatch MCP server with all tools and resources registered.
-func NewServer() *server.MCPServer {
+func NewServer(opts Options) *server.MCPServer {
+	revealDisabled = opts.DisableReveal
 	s := server.NewMCPServer(
 		"hatch",
 		"1.0.0",
@@ -108,8 +144,12 @@ func NewServer() *server.MCPServer {
 	s.AddTool(listAppsTool(), listAppsHandler)
 	s.AddTool(getStatusTool(), getStatusHandler)
 	s.AddTool(getLogsTool(), getLogsHandler)
+	s.AddTool(searchLogsTool(), searchLogsHandler)
 	s.AddTool(getEnvTool(), getEnvHandler)
 	s.AddTool(listEnvVarsTool(), listEnvVarsHandler)
+	if !opts.DisableReveal {
+		s.AddTool(revealEnvVarTool(), revealEnvVarHandler)
+	}
 	s.AddTool(getDatabaseURLTool(), getDatabaseURLHandler)
 	s.AddTool(getAppDetailsTool(), getAppDetailsHandler)
 	s.AddTool(healthCheckTool(), healthCheckHandler)
@@ -121,6 +161,7 @@ func NewServer() *server.MCPServer {
 	s.AddTool(setEnvTool(), setEnvHandler)
 	s.AddTool(setEnvVarTool(), setEnvVarHandler)
 	s.AddTool(bulkSetEnvTool(), bulkSetEnvHandler)
+	s.AddTool(copyEnvTool(), copyEnvHandler)
 	s.AddTool(deleteEnvTool(), deleteEnvHandler)
 	s.AddTool(addDomainTool(), addDomainHandler)
 	s.AddTool(listDomainsTool(), listDomainsHandler)
@@ -329,7 +370,7 @@ func listAppsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return toolError("failed to list apps: %v", err)
 	}
 
-	apps, err := client.ListApps()
+	apps, err := client.ListApps(ctx)
 	if err != nil {
 		return toolError("failed to list apps: %v", err)
 	}
@@ -354,8 +395,11 @@ and have the build output in a local directory.
 WHAT THIS TOOL DOES:
 1. Validates the deploy-target directory exists
 2. Validates the start-command entrypoint file exists in deploy-target
-3. Creates a tar.gz of the directory contents
-4. Uploads to Hatch which wraps it in a thin container image and deploys
+3. Creates a compressed tar of the directory contents, the same way 'hatch deploy'
+   does: .hatchignore rules apply (or the defaults, which leave out .git and .env),
+   and symlinks are kept as links, including ones pointing outside deploy_target
+4. Uploads to Hatch which wraps it in a thin container image and deploys. A zstd
+   artifact the API does not accept is rebuilt and uploaded as gzip
 
 CONTAINER BEHAVIOR:
 - The ENTIRE contents of deploy_target are extracted to /app/ inside the container
@@ -372,6 +416,12 @@ RUNTIME IMAGES:
 - "bun"    → oven/bun:1-alpine (for Bun/Elysia/Hono apps)
 - "static" → nginx:alpine (serves files via nginx, no start_command needed)
 
+REQUIRED ENV VARS:
+- If .hatch.toml has an [env.required] table or a .env.schema file exists in the
+  working directory, the app's env vars are checked before uploading. Missing keys
+  with a default are set; other missing or malformed keys fail the deploy with a
+  list. Set them with bulk_set_env and deploy again.
+
 ERROR RECOVERY:
 - If deploy fails, use get_logs to read container stderr
 - If app crashes, check that it listens on process.env.PORT (or equivalent)
@@ -406,6 +456,13 @@ EXAMPLES:
 		mcp.WithString("domain",
 			mcp.Description("Custom domain to configure (e.g. example.com)"),
 		),
+		mcp.WithBoolean("skip_env_check",
+			mcp.Description("Deploy even if the app's env vars don't match the required-env schema (default: false)"),
+		),
+		mcp.WithString("compression",
+			mcp.Description("Artifact compression: gzip (default) or zstd, which is faster and smaller"),
+			mcp.Enum("gzip", "zstd"),
+		),
 	)
 }
 
@@ -447,8 +504,22 @@ func deployAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to deploy app: deploy_target directory not found: %s", deployTarget)
 	}
 
+	var schema *envschema.Schema
+	if !req.GetBool("skip_env_check", false) {
+		schema, err = envschema.Load(".")
+		if err != nil {
+			return toolError("failed to deploy app: reading required-env schema: %v", err)
+		}
+	}
+
+	format, err := artifact.ParseFormat(req.GetString("compression", ""))
+	if err != nil {
+		return toolError("failed to deploy app: unknown compression %q (valid: gzip, zstd)", req.GetString("compression", ""))
+	}
+
 	// Read and tar the directory
-	artifact, err := createMCPTarGz(deployTarget)
+	opts := artifact.Options{Format: format}
+	data, _, err := artifact.Create(deployTarget, opts)
 	if err != nil {
 		return toolError("failed to deploy app: creating artifact: %v", err)
 	}
@@ -486,7 +557,7 @@ func deployAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 			appName = filepath.Base(cwd)
 		}
 
-		app, err := client.CreateApp(appName)
+		app, err := client.CreateApp(ctx, appName)
 		if err != nil {
 			return toolError("failed to deploy app: %v", err)
 		}
@@ -497,90 +568,41 @@ func deployAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		_ = os.WriteFile(tomlPath, []byte(content), 0644)
 	}
 
-	// Upload
-	if err := client.UploadArtifact(slug, bytes.NewReader(artifact), rt, startCmd); err != nil {
-		return toolError("failed to deploy app: upload failed: %v", err)
-	}
-
-	appURL := fmt.Sprintf("https://%s.nest.gethatch.eu", slug)
-	return mcp.NewToolResultText(fmt.Sprintf("Deployed successfully!\nApp: %s\nURL: %s\nRuntime: %s", slug, appURL, rt)), nil
-}
-
-// createMCPTarGz creates a tar.gz from a directory for MCP deploy_app tool.
-func createMCPTarGz(dir string) ([]byte, error) {
-	var buf bytes.Buffer
-	gzw := gzip.NewWriter(&buf)
-	tw := tar.NewWriter(gzw)
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		rel, err := filepath.Rel(dir, path)
+	var defaultsNote string
+	if schema != nil {
+		vars, err := client.GetEnvVars(ctx, slug)
 		if err != nil {
-			return err
-		}
-		if rel == "." {
-			return nil
+			return toolError("failed to deploy app: checking required env vars: %v", err)
 		}
-
-		mode := info.Mode()
-		// Archive only directories and regular files.
-		// Skip sockets/symlinks/devices/fifos to avoid tar errors and unsafe artifacts.
-		if mode&os.ModeSocket != 0 ||
-			mode&os.ModeSymlink != 0 ||
-			mode&os.ModeNamedPipe != 0 ||
-			mode&os.ModeDevice != 0 ||
-			mode&os.ModeCharDevice != 0 ||
-			mode&os.ModeIrregular != 0 {
-			return nil
+		res := schema.Check(vars)
+		if err := res.Err(); err != nil {
+			return toolError("failed to deploy app: %s: %v\nSet them with bulk_set_env on '%s' and deploy again, or pass skip_env_check", slug, err, slug)
 		}
-		if !info.IsDir() && !mode.IsRegular() {
-			return nil
+		if len(res.Defaults) > 0 {
+			if _, err := client.SetEnvVars(ctx, slug, res.Defaults, nil); err != nil {
+				return toolError("failed to deploy app: setting env var defaults: %v", err)
+			}
+			defaultsNote = "\nSchema defaults set: " + strings.Join(res.DefaultKeys(), ", ")
 		}
+	}
 
-		header, err := tar.FileInfoHeader(info, "")
+	// Upload
+	err = client.UploadArtifact(ctx, slug, bytes.NewReader(data), format, rt, startCmd)
+	if errors.Is(err, api.ErrUnsupportedArtifactFormat) && format != api.ArtifactGzip {
+		// Older API versions only accept gzip; rebuild and retry once.
+		opts.Format = api.ArtifactGzip
+		data, _, err = artifact.Create(deployTarget, opts)
 		if err != nil {
-			return err
-		}
-		header.Name = rel
-		if info.IsDir() {
-			header.Name += "/"
-		}
-
-		if err := tw.WriteHeader(header); err != nil {
-			return err
+			return toolError("failed to deploy app: creating artifact: %v", err)
 		}
-
-		if info.Mode().IsRegular() {
-			f, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-			if _, err := io.Copy(tw, f); err != nil {
-				return err
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
+		err = client.UploadArtifact(ctx, slug, bytes.NewReader(data), api.ArtifactGzip, rt, startCmd)
 	}
-	if err := tw.Close(); err != nil {
-		return nil, err
-	}
-	if err := gzw.Close(); err != nil {
-		return nil, err
-	}
-
-	if buf.Len() > 500*1024*1024 {
-		return nil, fmt.Errorf("artifact too large (%.0f MB, max 500 MB)", float64(buf.Len())/1024/1024)
+	if err != nil {
+		return toolError("failed to deploy app: upload failed: %v", err)
 	}
 
-	return buf.Bytes(), nil
+	appURL := fmt.Sprintf("https://%s.nest.gethatch.eu", slug)
+	return mcp.NewToolResultText(fmt.Sprintf("Deployed successfully!\nApp: %s\nURL: %s\nRuntime: %s%s", slug, appURL, rt, defaultsNote)), nil
 }
 
 // --- add_database ---
@@ -606,14 +628,14 @@ func addDatabaseHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return toolError("failed to add database: %v", err)
 	}
 
-	addon, err := client.AddAddon(slug, "postgresql")
+	addon, err := client.AddAddon(ctx, slug, "postgresql")
 	if err != nil {
 		return toolError("failed to add database: %v", err)
 	}
 
 	result := fmt.Sprintf("PostgreSQL database provisioned for %s.\nStatus: %s", slug, addon.Status)
 	if addon.URL != "" {
-		result += fmt.Sprintf("\nDATABASE_URL: %s", addon.URL)
+		result += fmt.Sprintf("\nDATABASE_URL: %s\nThe password is masked; call get_database_url with reveal: true if the full URL is really needed.", secret.MaskURL(addon.URL))
 	}
 	return mcp.NewToolResultText(result), nil
 }
@@ -641,14 +663,14 @@ func addStorageHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return toolError("failed to add storage: %v", err)
 	}
 
-	addon, err := client.AddAddon(slug, "s3")
+	addon, err := client.AddAddon(ctx, slug, "s3")
 	if err != nil {
 		return toolError("failed to add storage: %v", err)
 	}
 
 	result := fmt.Sprintf("S3-compatible storage provisioned for %s.\nStatus: %s", slug, addon.Status)
 	if addon.URL != "" {
-		result += fmt.Sprintf("\nStorage URL: %s", addon.URL)
+		result += fmt.Sprintf("\nStorage URL: %s\nCredentials are masked; get_env lists the storage env vars and reveal_env_var returns one in plaintext if really needed.", secret.MaskURL(addon.URL))
 	}
 	return mcp.NewToolResultText(result), nil
 }
@@ -669,6 +691,20 @@ func getLogsTool() mcp.Tool {
 			mcp.Description("Log stream type: stdout, stderr, or both (default both)"),
 			mcp.Enum("stdout", "stderr", "both"),
 		),
+		mcp.WithString("level",
+			mcp.Description("Only return lines at or above this level. Lines without a recognizable level are dropped"),
+			mcp.Enum("trace", "debug", "info", "warn", "error", "fatal"),
+		),
+		mcp.WithString("grep",
+			mcp.Description("Only return lines matching this regular expression (Go RE2 syntax)"),
+		),
+		mcp.WithArray("field",
+			mcp.Description("Only return JSON or logfmt lines with all of these key=value fields, e.g. [\"route=/api\"]"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only return lines newer than this: a duration like \"15m\" or \"2h\", or an RFC 3339 timestamp"),
+		),
 	)
 }
 
@@ -691,6 +727,16 @@ func getLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return toolError("failed to get logs: invalid type %q (valid: stdout, stderr, both)", logType)
 	}
 
+	filter, err := logparse.NewFilter(req.GetString("level", ""), req.GetString("grep", ""), req.GetStringSlice("field", nil))
+	if err != nil {
+		return toolError("failed to get logs: %v", err)
+	}
+	if since := req.GetString("since", ""); since != "" {
+		if filter.Since, err = logparse.ParseTime(since, time.Now()); err != nil {
+			return toolError("failed to get logs: %v", err)
+		}
+	}
+
 	client, err := newClient()
 	if err != nil {
 		return toolError("failed to get logs: %v", err)
@@ -707,14 +753,17 @@ func getLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	}
 
 	var merged []string
+	fetched := 0
 	for _, stream := range streamTypes {
-		logLines, err := client.GetLogs(slug, lines, stream)
+		logLines, err := client.GetLogs(ctx, slug, lines, stream)
 		if err != nil {
-			if isNoRunningAllocationError(err) {
-				return noRunningAllocationResult(client, slug)
+			if api.IsNoRunningAllocation(err) {
+				return noRunningAllocationResult(ctx, client, slug)
 			}
 			return toolError("failed to get logs: %v", err)
 		}
+		fetched += len(logLines)
+		logLines = filterLogLines(logLines, filter)
 		if len(logLines) == 0 {
 			continue
 		}
@@ -727,8 +776,11 @@ func getLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		}
 	}
 
+	if len(merged) == 0 && fetched > 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No log lines matched the filters (%d recent lines checked).", fetched)), nil
+	}
 	if len(merged) == 0 {
-		app, appErr := client.GetApp(slug)
+		app, appErr := client.GetApp(ctx, slug)
 		if appErr == nil && app != nil {
 			return mcp.NewToolResultText(fmt.Sprintf(
 				"No recent logs found (checked: %s). App status: %s.",
@@ -742,6 +794,134 @@ func getLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	return mcp.NewToolResultText(strings.Join(merged, "\n")), nil
 }
 
+// filterLogLines keeps the lines that match filter.
+func filterLogLines(lines []string, filter *logparse.Filter) []string {
+	if !filter.Active() {
+		return lines
+	}
+	var kept []string
+	for _, line := range lines {
+		if filter.Match(logparse.Parse(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// --- search_logs ---
+
+const (
+	searchLogsDefaultLimit = 200
+	searchLogsMaxLimit     = 1000
+)
+
+func searchLogsTool() mcp.Tool {
+	return mcp.NewTool("search_logs",
+		mcp.WithDescription("Search an app's stored logs in a time window, e.g. to investigate an incident after the fact. Returns matching lines oldest first, each as \"timestamp [stream] message\"."),
+		mcp.WithString("app",
+			mcp.Required(),
+			mcp.Description("App slug (name) to search"),
+		),
+		mcp.WithString("since",
+			mcp.Required(),
+			mcp.Description("Start of the window: a duration before now like \"2h\", or a timestamp like \"2025-06-15T10:00:00Z\""),
+		),
+		mcp.WithString("until",
+			mcp.Description("End of the window, in the same forms as since (default: now)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Only return lines containing this text (case-insensitive)"),
+		),
+		mcp.WithString("level",
+			mcp.Description("Only return lines at or above this level. Lines without a recognizable level are dropped"),
+			mcp.Enum("trace", "debug", "info", "warn", "error", "fatal"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Log stream type: stdout, stderr, or both (default both)"),
+			mcp.Enum("stdout", "stderr", "both"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of lines to return (default %d, max %d)", searchLogsDefaultLimit, searchLogsMaxLimit)),
+		),
+	)
+}
+
+func searchLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	slug, err := req.RequireString("app")
+	if err != nil {
+		return toolError("failed to search logs: missing required parameter 'app'")
+	}
+	sinceArg, err := req.RequireString("since")
+	if err != nil {
+		return toolError("failed to search logs: missing required parameter 'since'")
+	}
+
+	now := time.Now()
+	filter, err := logparse.NewFilter(req.GetString("level", ""), "", nil)
+	if err != nil {
+		return toolError("failed to search logs: %v", err)
+	}
+	q := api.LogQuery{Query: req.GetString("query", "")}
+	if q.Since, err = logparse.ParseTime(sinceArg, now); err != nil {
+		return toolError("failed to search logs: since: %v", err)
+	}
+	if until := req.GetString("until", ""); until != "" {
+		if q.Until, err = logparse.ParseTime(until, now); err != nil {
+			return toolError("failed to search logs: until: %v", err)
+		}
+		if !q.Until.After(q.Since) {
+			return toolError("failed to search logs: until must be later than since")
+		}
+	}
+	switch logType := strings.ToLower(req.GetString("type", "both")); logType {
+	case "stdout", "stderr":
+		q.Type = logType
+	case "both", "":
+	default:
+		return toolError("failed to search logs: invalid type %q (valid: stdout, stderr, both)", logType)
+	}
+	limit := int(req.GetFloat("limit", searchLogsDefaultLimit))
+	if limit <= 0 {
+		limit = searchLogsDefaultLimit
+	}
+	limit = min(limit, searchLogsMaxLimit)
+
+	client, err := newClient()
+	if err != nil {
+		return toolError("failed to search logs: %v", err)
+	}
+
+	var lines []string
+	truncated := false
+	err = client.WalkLogs(ctx, slug, q, func(le api.LogEntry) error {
+		e := logparse.Parse(le.Message)
+		if e.Time.IsZero() {
+			e.Time = le.Timestamp
+		}
+		if !filter.Match(e) {
+			return nil
+		}
+		if len(lines) == limit {
+			truncated = true
+			return api.SkipRemainingLogs
+		}
+		lines = append(lines, fmt.Sprintf("%s [%s] %s", le.Timestamp.UTC().Format(time.RFC3339Nano), le.Stream, le.Message))
+		return nil
+	})
+	if err != nil {
+		return toolError("failed to search logs: %v", err)
+	}
+
+	if len(lines) == 0 {
+		return mcp.NewToolResultText("No log lines matched in that time window."), nil
+	}
+	result := strings.Join(lines, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n\nShowing the first %d matching lines; there are more. Narrow the window or the query, or raise limit (max %d).", limit, searchLogsMaxLimit)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
 // --- get_status ---
 
 func getStatusTool() mcp.Tool {
@@ -765,7 +945,7 @@ func getStatusHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to get app status: %v", err)
 	}
 
-	app, err := client.GetApp(slug)
+	app, err := client.GetApp(ctx, slug)
 	if err != nil {
 		return toolError("failed to get app status: %v", err)
 	}
@@ -795,6 +975,9 @@ func setEnvTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Environment variable value"),
 		),
+		mcp.WithBoolean("restart",
+			mcp.Description("Restart the app afterwards and wait until it is running, so it picks up the change (default: false). When setting several variables, prefer bulk_set_env with restart: true for a single restart"),
+		),
 	)
 }
 
@@ -817,18 +1000,25 @@ func setEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return toolError("failed to set env var: %v", err)
 	}
 
-	if err := client.SetEnvVar(slug, key, value); err != nil {
+	if err := client.SetEnvVar(ctx, slug, key, value); err != nil {
 		return toolError("failed to set env var: %v", err)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Set %s on %s.", key, slug)), nil
+	result := fmt.Sprintf("Set %s on %s.", key, slug)
+	if req.GetBool("restart", false) {
+		if err := restartAndWait(ctx, client, slug); err != nil {
+			return toolError("failed to restart app after setting %s (the value was set): %v", key, err)
+		}
+		result += fmt.Sprintf("\nRestarted '%s'; it is running.", slug)
+	}
+	return mcp.NewToolResultText(result), nil
 }
 
 // --- get_env ---
 
 func getEnvTool() mcp.Tool {
 	return mcp.NewTool("get_env",
-		mcp.WithDescription("List all environment variables for an app."),
+		mcp.WithDescription("List all environment variables for an app. Values of sensitive keys (passwords, tokens, keys, DATABASE_URL) are masked and marked sensitive: true; use reveal_env_var if the plaintext is really needed."),
 		mcp.WithString("app",
 			mcp.Required(),
 			mcp.Description("App slug (name) to list env vars for"),
@@ -847,7 +1037,7 @@ func getEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return toolError("failed to get env vars: %v", err)
 	}
 
-	vars, err := client.GetEnvVars(slug)
+	vars, err := client.GetEnvVars(ctx, slug)
 	if err != nil {
 		return toolError("failed to get env vars: %v", err)
 	}
@@ -856,7 +1046,7 @@ func getEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return mcp.NewToolResultText("No environment variables set."), nil
 	}
 
-	data, _ := json.MarshalIndent(vars, "", "  ")
+	data, _ := json.MarshalIndent(maskEnvVars(vars), "", "  ")
 	return mcp.NewToolResultText(string(data)), nil
 }
 
@@ -896,7 +1086,7 @@ func addDomainHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to add domain: %v", err)
 	}
 
-	d, err := client.AddDomain(slug, domain)
+	d, err := client.AddDomain(ctx, slug, domain)
 	if err != nil {
 		return toolError("failed to add domain: %v", err)
 	}
@@ -940,11 +1130,17 @@ Tell the user to configure DNS, then re-run the dig command to confirm.`,
 
 func getDatabaseURLTool() mcp.Tool {
 	return mcp.NewTool("get_database_url",
-		mcp.WithDescription("Get the DATABASE_URL for an app's PostgreSQL database."),
+		mcp.WithDescription("Get the DATABASE_URL for an app's PostgreSQL database, with the password masked. Pass reveal: true only when the full URL is really needed; every reveal is recorded in the user's audit log, and it is refused when the user has disabled revealing."),
 		mcp.WithString("app",
 			mcp.Required(),
 			mcp.Description("App slug (name) to get the database URL for"),
 		),
+		mcp.WithBoolean("reveal",
+			mcp.Description("Return the URL with its password (default: false)"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Why the full URL is needed; recorded in the audit log"),
+		),
 	)
 }
 
@@ -953,21 +1149,32 @@ func getDatabaseURLHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	if err != nil {
 		return toolError("failed to get database url: missing required parameter 'app'")
 	}
+	reveal := req.GetBool("reveal", false)
+	if reveal && revealDisabled {
+		return toolError("failed to get database url: revealing secrets is disabled for this server; omit reveal to get the masked URL")
+	}
 
 	client, err := newClient()
 	if err != nil {
 		return toolError("failed to get database url: %v", err)
 	}
 
-	vars, err := client.GetEnvVars(slug)
+	vars, err := client.GetEnvVars(ctx, slug)
 	if err != nil {
 		return toolError("failed to get database url: %v", err)
 	}
 
 	for _, v := range vars {
-		if v.Key == "DATABASE_URL" {
-			return mcp.NewToolResultText(v.Value), nil
+		if v.Key != "DATABASE_URL" {
+			continue
+		}
+		if !reveal {
+			return mcp.NewToolResultText(secret.MaskURL(v.Value)), nil
 		}
+		if err := auditReveal("get_database_url", slug, v.Key, req.GetString("reason", "")); err != nil {
+			return toolError("failed to get database url: writing audit log: %v", err)
+		}
+		return mcp.NewToolResultText(v.Value), nil
 	}
 
 	return toolError("failed to get database url: no DATABASE_URL found, add a database first with add_database")
@@ -996,7 +1203,7 @@ func restartAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return toolError("failed to restart app: %v", err)
 	}
 
-	if err := client.RestartApp(slug); err != nil {
+	if err := client.RestartApp(ctx, slug); err != nil {
 		return toolError("failed to restart app: %v", err)
 	}
 
@@ -1039,7 +1246,7 @@ func deleteEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to delete env var: %v", err)
 	}
 
-	if err := client.UnsetEnvVar(slug, key); err != nil {
+	if err := client.UnsetEnvVar(ctx, slug, key); err != nil {
 		return toolError("failed to delete env var: %v", err)
 	}
 
@@ -1069,7 +1276,7 @@ func listDomainsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return toolError("failed to list domains: %v", err)
 	}
 
-	domains, err := client.ListDomains(slug)
+	domains, err := client.ListDomains(ctx, slug)
 	if err != nil {
 		return toolError("failed to list domains: %v", err)
 	}
@@ -1118,7 +1325,7 @@ func removeDomainHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		return toolError("failed to remove domain: %v", err)
 	}
 
-	if err := client.RemoveDomain(slug, domain); err != nil {
+	if err := client.RemoveDomain(ctx, slug, domain); err != nil {
 		return toolError("failed to remove domain: %v", err)
 	}
 
@@ -1129,7 +1336,7 @@ func removeDomainHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 
 func getBuildLogsTool() mcp.Tool {
 	return mcp.NewTool("get_build_logs",
-		mcp.WithDescription("Get build/startup logs for an app from log streams (stderr/stdout). Use to diagnose deploy failures."),
+		mcp.WithDescription("Get build/startup logs for an app from log streams (stderr/stdout). Use to diagnose deploy failures. With 'deployment', returns the build log of that deployment and, if it failed, a structured summary of the error block (compiler error, missing module, out of memory) so you don't have to read the whole log."),
 		mcp.WithString("app",
 			mcp.Required(),
 			mcp.Description("App slug (name) to get build logs for"),
@@ -1137,6 +1344,9 @@ func getBuildLogsTool() mcp.Tool {
 		mcp.WithNumber("lines",
 			mcp.Description("Number of recent build log lines to return (default 100)"),
 		),
+		mcp.WithString("deployment",
+			mcp.Description("Deployment ID to get the build log of, or 'latest' for the most recent deployment"),
+		),
 		mcp.WithString("type",
 			mcp.Description("Log stream type: stderr (default), stdout"),
 			mcp.Enum("stdout", "stderr"),
@@ -1165,6 +1375,10 @@ func getBuildLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		return toolError("failed to get build logs: %v", err)
 	}
 
+	if id := strings.TrimSpace(req.GetString("deployment", "")); id != "" {
+		return deploymentBuildLogs(ctx, client, slug, id, lines)
+	}
+
 	streamTypes := []string{"stderr", "stdout"}
 	if logType != "" {
 		streamTypes = []string{logType}
@@ -1172,10 +1386,10 @@ func getBuildLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 
 	var merged []string
 	for _, stream := range streamTypes {
-		logLines, err := client.GetLogs(slug, lines, stream)
+		logLines, err := client.GetLogs(ctx, slug, lines, stream)
 		if err != nil {
-			if isNoRunningAllocationError(err) {
-				return noRunningAllocationResult(client, slug)
+			if api.IsNoRunningAllocation(err) {
+				return noRunningAllocationResult(ctx, client, slug)
 			}
 			return toolError("failed to get build logs: %v", err)
 		}
@@ -1198,6 +1412,58 @@ func getBuildLogsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	return mcp.NewToolResultText(strings.Join(merged, "\n")), nil
 }
 
+// buildLogsResult is the structured result of get_build_logs for a
+// deployment.
+type buildLogsResult struct {
+	App        string            `json:"app"`
+	Deployment api.Deployment    `json:"deployment"`
+	Failed     bool              `json:"failed"`
+	Summary    *buildlog.Summary `json:"summary,omitempty"`
+	TotalLines int               `json:"total_lines"`
+	// Lines are the last lines of the build log.
+	Lines []string `json:"lines"`
+}
+
+func deploymentBuildLogs(ctx context.Context, client *api.Client, slug, id string, lines int) (*mcp.CallToolResult, error) {
+	d, err := client.GetDeployment(ctx, slug, id)
+	if err != nil {
+		return toolError("failed to get build logs: %v", err)
+	}
+	logLines, err := client.GetBuildLogs(ctx, slug, d.ID)
+	if err != nil {
+		return toolError("failed to get build logs: %v", err)
+	}
+
+	res := buildLogsResult{App: slug, Deployment: *d, Failed: d.Failed(), TotalLines: len(logLines), Lines: logLines}
+	if len(logLines) > lines {
+		res.Lines = logLines[len(logLines)-lines:]
+	}
+	if res.Failed {
+		res.Summary = buildlog.Summarize(logLines)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deployment %s of %s: %s (created %s)\n", d.ID, slug, d.Status, d.CreatedAt.Format(time.RFC3339))
+	switch {
+	case res.Summary != nil:
+		fmt.Fprintf(&b, "\nBuild failed: %s (line %d)\n", res.Summary.Title, res.Summary.Line)
+		for _, l := range res.Summary.Excerpt {
+			b.WriteString("  " + l + "\n")
+		}
+		if res.Summary.Hint != "" {
+			b.WriteString("Hint: " + res.Summary.Hint + "\n")
+		}
+	case res.Failed:
+		b.WriteString("\nBuild failed. No error block was found in the log.\n")
+	}
+	if len(logLines) == 0 {
+		b.WriteString("\nNo build logs found.")
+	} else {
+		fmt.Fprintf(&b, "\nLast %d of %d build log lines:\n%s", len(res.Lines), len(logLines), strings.Join(res.Lines, "\n"))
+	}
+	return mcp.NewToolResultStructured(res, b.String()), nil
+}
+
 // --- create_app ---
 
 func createAppTool() mcp.Tool {
@@ -1221,7 +1487,7 @@ func createAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to create app: %v", err)
 	}
 
-	app, err := client.CreateApp(name)
+	app, err := client.CreateApp(ctx, name)
 	if err != nil {
 		return toolError("failed to create app: %v", err)
 	}
@@ -1268,7 +1534,7 @@ func deleteAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to delete app: %v", err)
 	}
 
-	if err := client.DeleteApp(slug); err != nil {
+	if err := client.DeleteApp(ctx, slug); err != nil {
 		return toolError("failed to delete app: %v", err)
 	}
 
@@ -1327,7 +1593,7 @@ func getAppDetailsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 		return toolError("failed to get app details: %v", err)
 	}
 
-	app, err := client.GetApp(slug)
+	app, err := client.GetApp(ctx, slug)
 	if err != nil {
 		return toolError("failed to get app details: %v", err)
 	}
@@ -1434,7 +1700,7 @@ func setEnvVarHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return toolError("failed to set env var: %v", err)
 	}
 
-	if err := client.SetEnvVar(slug, key, value); err != nil {
+	if err := client.SetEnvVar(ctx, slug, key, value); err != nil {
 		return toolError("failed to set env var: %v", err)
 	}
 
@@ -1445,7 +1711,7 @@ func setEnvVarHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 
 func listEnvVarsTool() mcp.Tool {
 	return mcp.NewTool("list_env_vars",
-		mcp.WithDescription("List all environment variables for an app. Alias for get_env with clearer naming."),
+		mcp.WithDescription("List all environment variables for an app. Alias for get_env with clearer naming. Sensitive values are masked."),
 		mcp.WithString("slug",
 			mcp.Required(),
 			mcp.Description("App slug (name) to list env vars for"),
@@ -1464,7 +1730,7 @@ func listEnvVarsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return toolError("failed to list env vars: %v", err)
 	}
 
-	vars, err := client.GetEnvVars(slug)
+	vars, err := client.GetEnvVars(ctx, slug)
 	if err != nil {
 		return toolError("failed to list env vars: %v", err)
 	}
@@ -1473,15 +1739,102 @@ func listEnvVarsHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return mcp.NewToolResultText("No environment variables set."), nil
 	}
 
-	data, _ := json.MarshalIndent(vars, "", "  ")
+	data, _ := json.MarshalIndent(maskEnvVars(vars), "", "  ")
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// maskedEnvVar is an env var as shown to MCP clients.
+type maskedEnvVar struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// maskEnvVars applies the shared masking policy so that secrets never reach
+// the agent's context unless explicitly revealed.
+func maskEnvVars(vars []api.EnvVar) []maskedEnvVar {
+	out := make([]maskedEnvVar, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, maskedEnvVar{
+			Key:       v.Key,
+			Value:     secret.Mask(v.Key, v.Value),
+			Sensitive: secret.IsSensitive(v.Key),
+		})
+	}
+	return out
+}
+
+// --- reveal_env_var ---
+
+func revealEnvVarTool() mcp.Tool {
+	return mcp.NewTool("reveal_env_var",
+		mcp.WithDescription("Return the plaintext value of a single environment variable. Only use this when the actual value is required for the task; get_env already shows masked values. Every call is recorded in the user's audit log."),
+		mcp.WithString("app",
+			mcp.Required(),
+			mcp.Description("App slug (name) the variable belongs to"),
+		),
+		mcp.WithString("key",
+			mcp.Required(),
+			mcp.Description("Environment variable name to reveal"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Why the plaintext value is needed; recorded in the audit log"),
+		),
+	)
+}
+
+func revealEnvVarHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	slug, err := req.RequireString("app")
+	if err != nil {
+		return toolError("failed to reveal env var: missing required parameter 'app'")
+	}
+	key, err := req.RequireString("key")
+	if err != nil {
+		return toolError("failed to reveal env var: missing required parameter 'key'")
+	}
+	if err := api.ValidateEnvKey(key); err != nil {
+		return toolError("failed to reveal env var: %v", err)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return toolError("failed to reveal env var: %v", err)
+	}
+
+	vars, err := client.GetEnvVars(ctx, slug)
+	if err != nil {
+		return toolError("failed to reveal env var: %v", err)
+	}
+
+	for _, v := range vars {
+		if v.Key != key {
+			continue
+		}
+		if err := auditReveal("reveal_env_var", slug, key, req.GetString("reason", "")); err != nil {
+			return toolError("failed to reveal env var: writing audit log: %v", err)
+		}
+		return mcp.NewToolResultText(v.Value), nil
+	}
+	return toolError("failed to reveal env var: %s is not set on %s", key, slug)
+}
+
+// auditReveal records that a tool is about to hand a plaintext secret to the
+// MCP client. Callers must refuse to reveal anything that could not be
+// recorded.
+func auditReveal(action, slug, key, reason string) error {
+	event := config.AuditEvent{Action: action, App: slug, Key: key, Reason: reason}
+	if err := auditLogFunc(event); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "hatch mcp: revealed %s of %s to the MCP client\n", key, slug)
+	return nil
+}
+
 // --- bulk_set_env ---
 
 func bulkSetEnvTool() mcp.Tool {
 	return mcp.NewTool("bulk_set_env",
-		mcp.WithDescription("Set multiple environment variables at once. More efficient than calling set_env repeatedly."),
+		mcp.WithDescription("Set multiple environment variables at once in a single atomic update: either all keys are applied or none are. Reports which keys were added, updated, or unchanged."),
 		mcp.WithString("app",
 			mcp.Required(),
 			mcp.Description("App slug (name) to set variables on"),
@@ -1494,6 +1847,9 @@ func bulkSetEnvTool() mcp.Tool {
 				"type": "string",
 			}),
 		),
+		mcp.WithBoolean("restart",
+			mcp.Description("Restart the app once after the update, if anything changed, and wait until it is running (default: false)"),
+		),
 	)
 }
 
@@ -1518,46 +1874,153 @@ func bulkSetEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return toolError("failed to bulk set env vars: 'vars' cannot be empty")
 	}
 
+	vars := make(map[string]string, len(varsMap))
+	for key, value := range varsMap {
+		valueStr, ok := value.(string)
+		if !ok {
+			return toolError("failed to bulk set env vars: value for %s must be a string", key)
+		}
+		vars[key] = valueStr
+	}
+
 	client, err := newClient()
 	if err != nil {
 		return toolError("failed to bulk set env vars: %v", err)
 	}
 
-	var successKeys []string
-	var errors []string
+	res, err := client.SetEnvVars(ctx, slug, vars, nil)
+	if err != nil {
+		return toolError("failed to bulk set env vars (no changes applied): %v", err)
+	}
 
-	for key, value := range varsMap {
-		valueStr, ok := value.(string)
-		if !ok {
-			errors = append(errors, fmt.Sprintf("%s: value must be a string", key))
-			continue
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Applied %d environment variables to '%s' (%d changed).", len(vars), slug, res.Changed()))
+	for _, group := range []struct {
+		label string
+		keys  []string
+	}{
+		{"Added", res.Added},
+		{"Updated", res.Updated},
+		{"Unchanged", res.Unchanged},
+	} {
+		if len(group.keys) > 0 {
+			result.WriteString(fmt.Sprintf("\n%s: %s", group.label, strings.Join(group.keys, ", ")))
 		}
-
-		if err := client.SetEnvVar(slug, key, valueStr); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", key, err))
+	}
+	if req.GetBool("restart", false) {
+		if res.Changed() == 0 {
+			result.WriteString("\nNothing changed; not restarted.")
 		} else {
-			successKeys = append(successKeys, key)
+			if err := restartAndWait(ctx, client, slug); err != nil {
+				return toolError("failed to restart app after bulk set (the env vars were applied): %v", err)
+			}
+			result.WriteString(fmt.Sprintf("\nRestarted '%s'; it is running.", slug))
 		}
 	}
+	return mcp.NewToolResultText(result.String()), nil
+}
 
-	// Build result message
-	var result strings.Builder
-	if len(successKeys) > 0 {
-		result.WriteString(fmt.Sprintf("Set %d environment variables on '%s': %s",
-			len(successKeys), slug, strings.Join(successKeys, ", ")))
+// restartTimeout bounds the wait for an app to run again after a restart.
+const restartTimeout = 3 * time.Minute
+
+// restartAndWait restarts the app and waits until the restarted instance
+// reports running.
+func restartAndWait(ctx context.Context, client *api.Client, slug string) error {
+	return client.RestartAndWait(ctx, slug, restartTimeout)
+}
+
+// --- copy_env ---
+
+func copyEnvTool() mcp.Tool {
+	return mcp.NewTool("copy_env",
+		mcp.WithDescription("Copy environment variables from one app to another in a single atomic update, e.g. to bootstrap a new app from an existing one. Keys on the target that are not copied are left alone. Reports keys only, never values."),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("App slug to copy from"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("App slug to copy to"),
+		),
+		mcp.WithArray("include",
+			mcp.Description("Only copy keys matching one of these glob patterns, e.g. [\"STRIPE_*\"]. Default: all keys"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Never copy keys matching one of these glob patterns, e.g. [\"DATABASE_URL\"]. Wins over include"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean("skip_existing",
+			mcp.Description("Keep keys that already exist on the target (default: false, overwrite them)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Report what would be copied without changing anything (default: false)"),
+		),
+	)
+}
+
+func copyEnvHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	from, err := req.RequireString("from")
+	if err != nil {
+		return toolError("failed to copy env vars: missing required parameter 'from'")
+	}
+	to, err := req.RequireString("to")
+	if err != nil {
+		return toolError("failed to copy env vars: missing required parameter 'to'")
+	}
+	if from == to {
+		return toolError("failed to copy env vars: 'from' and 'to' are the same app")
 	}
+	dryRun := req.GetBool("dry_run", false)
 
-	if len(errors) > 0 {
-		if result.Len() > 0 {
-			result.WriteString("\n\nErrors:\n")
-		}
-		result.WriteString(redactError(strings.Join(errors, "\n")))
+	client, err := newClient()
+	if err != nil {
+		return toolError("failed to copy env vars: %v", err)
 	}
 
-	if len(successKeys) == 0 && len(errors) > 0 {
-		return mcp.NewToolResultError(redactError(result.String())), nil
+	src, err := client.GetEnvVars(ctx, from)
+	if err != nil {
+		return toolError("failed to copy env vars: reading %s: %v", from, err)
+	}
+	dst, err := client.GetEnvVars(ctx, to)
+	if err != nil {
+		return toolError("failed to copy env vars: reading %s: %v", to, err)
 	}
 
+	plan, err := envcopy.NewPlan(src, dst, envcopy.Options{
+		Include:      req.GetStringSlice("include", nil),
+		Exclude:      req.GetStringSlice("exclude", nil),
+		SkipExisting: req.GetBool("skip_existing", false),
+	})
+	if err != nil {
+		return toolError("failed to copy env vars: %v", err)
+	}
+
+	var result strings.Builder
+	switch {
+	case plan.Changes() == 0:
+		result.WriteString(fmt.Sprintf("Nothing to copy: '%s' already has the selected variables of '%s'.", to, from))
+	case dryRun:
+		result.WriteString(fmt.Sprintf("Dry run: would copy %d environment variables from '%s' to '%s'.", plan.Changes(), from, to))
+	default:
+		if _, err := client.SetEnvVars(ctx, to, plan.Set, nil); err != nil {
+			return toolError("failed to copy env vars (no changes applied): %v", err)
+		}
+		result.WriteString(fmt.Sprintf("Copied %d environment variables from '%s' to '%s'.", plan.Changes(), from, to))
+	}
+	for _, group := range []struct {
+		label string
+		keys  []string
+	}{
+		{"Added", plan.Added},
+		{"Updated", plan.Updated},
+		{"Unchanged", plan.Unchanged},
+		{"Skipped (already set)", plan.Skipped},
+	} {
+		if len(group.keys) > 0 {
+			result.WriteString(fmt.Sprintf("\n%s: %s", group.label, strings.Join(group.keys, ", ")))
+		}
+	}
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -1575,7 +2038,7 @@ func checkEnergyHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return toolError("failed to check energy: %v", err)
 	}
 
-	energy, err := client.GetAccountEnergy()
+	energy, err := client.GetAccountEnergy(ctx)
 	if err != nil {
 		return toolError("failed to check energy: %v", err)
 	}
@@ -1619,7 +2082,7 @@ func getAppEnergyHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		return toolError("failed to get app energy: %v", err)
 	}
 
-	energy, err := client.GetAppEnergy(slug)
+	energy, err := client.GetAppEnergy(ctx, slug)
 	if err != nil {
 		return toolError("failed to get app energy: %v", err)
 	}
@@ -1675,7 +2138,7 @@ func boostAppHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return toolError("failed to boost app: %v", err)
 	}
 
-	checkout, err := client.BoostCheckout(slug, duration)
+	checkout, err := client.BoostCheckout(ctx, slug, duration)
 	if err != nil {
 		return toolError("failed to boost app: %v", err)
 	}
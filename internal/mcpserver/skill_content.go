@@ -78,12 +78,16 @@ The deploy-target contents are extracted to /app/ in the container.
 | ` + "`list_apps`" + ` | List all your deployed apps |
 | ` + "`add_database`" + ` | Provisions PostgreSQL, injects DATABASE_URL |
 | ` + "`add_storage`" + ` | S3-compatible bucket |
-| ` + "`get_logs`" + ` | Returns recent application logs |
+| ` + "`get_logs`" + ` | Returns recent application logs (filter with ` + "`level`" + `, ` + "`grep`" + `, ` + "`field`" + `, ` + "`since`" + `) |
+| ` + "`search_logs`" + ` | Stored logs in a time window, with a search query (for incidents after the fact) |
+| ` + "`get_build_logs`" + ` | Build logs; with ` + "`deployment`" + ` (an ID or ` + "`latest`" + `) returns a structured ` + "`summary`" + ` of why the build failed |
 | ` + "`get_status`" + ` | App running status, URL, region |
-| ` + "`set_env`" + ` | Set environment variables |
-| ` + "`get_env`" + ` | List all environment variables |
+| ` + "`set_env`" + ` | Set environment variables (` + "`restart: true`" + ` restarts the app and waits until it runs) |
+| ` + "`get_env`" + ` | List all environment variables (sensitive values masked) |
+| ` + "`reveal_env_var`" + ` | Plaintext value of one variable, audit-logged |
+| ` + "`copy_env`" + ` | Copy variables from one app to another (include/exclude globs, dry run) |
 | ` + "`add_domain`" + ` | Custom domain setup with DNS instructions |
-| ` + "`get_database_url`" + ` | Get DATABASE_URL for an app |
+| ` + "`get_database_url`" + ` | DATABASE_URL for an app, password masked (` + "`reveal: true`" + ` for the full URL, audit-logged) |
 `
 
 // ClaudeMDContent is the user-facing Hatch deployment guide written by `hatch init`.
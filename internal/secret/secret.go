@@ -0,0 +1,87 @@
+// Package secret holds the masking policy for environment variable values.
+// The CLI and the MCP server both use it so that a value hidden in one is
+// hidden in the other.
+package secret
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// sensitiveKeys are substrings of env var names whose values are masked.
+var sensitiveKeys = []string{"PASSWORD", "SECRET", "TOKEN", "KEY", "DSN", "DATABASE_URL", "API_KEY", "PRIVATE", "CREDENTIAL"}
+
+// IsSensitive reports whether the value of key should be masked.
+func IsSensitive(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, sk := range sensitiveKeys {
+		if strings.Contains(upper, sk) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask hides the value of sensitive keys. Values of 16 characters or more
+// keep their last four so they can still be told apart; a prefix is never
+// shown, since it often gives away the kind of key or part of a URL. Values
+// of other keys are returned unchanged.
+func Mask(key, value string) string {
+	if !IsSensitive(key) {
+		return value
+	}
+	if len(value) >= 16 {
+		return "****" + value[len(value)-4:]
+	}
+	return "****"
+}
+
+// MaskURL hides the password in a connection URL, and query parameters with
+// sensitive names or signatures (as in presigned S3 URLs), keeping the
+// scheme, user, host and database visible. A value that does not parse as
+// a URL is masked entirely.
+func MaskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return "****"
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "****")
+		}
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if IsSensitive(k) || strings.EqualFold(k, "password") || strings.Contains(strings.ToUpper(k), "SIGNATURE") {
+				q.Set(k, "****")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	// Keep the mask readable rather than percent-encoded.
+	return strings.ReplaceAll(u.String(), "%2A%2A%2A%2A", "****")
+}
+
+// fingerprintKey keys Fingerprint. It is random and lives only as long as
+// the process, so a printed fingerprint cannot be checked against guesses
+// offline or matched up with one printed by another run.
+var fingerprintKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key) // never returns an error
+	return key
+}()
+
+// Fingerprint returns a short HMAC-SHA256 of value under a per-run key, so
+// that values printed by the same command can be compared without showing
+// either. Fingerprints are only for display: compare the values themselves
+// to decide whether they differ.
+func Fingerprint(value string) string {
+	mac := hmac.New(sha256.New, fingerprintKey)
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
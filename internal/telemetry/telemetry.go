@@ -44,6 +44,22 @@ func redactArgs(args string) string {
 	return redact(args)
 }
 
+// AnnotationKeysOnly marks a cobra command whose positional arguments may be
+// KEY=VALUE pairs with secret values. Only the keys are reported.
+const AnnotationKeysOnly = "telemetry.keys-only"
+
+// KeysOnly drops the value from every KEY=VALUE argument.
+func KeysOnly(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if key, _, ok := strings.Cut(arg, "="); ok {
+			arg = key + "=<redacted>"
+		}
+		out[i] = arg
+	}
+	return out
+}
+
 // Send fires a telemetry event in a background goroutine.
 // It is fire-and-forget: errors are silently ignored.
 func Send(command, args, errMsg, mode string) {
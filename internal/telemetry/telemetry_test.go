@@ -30,6 +30,16 @@ func TestRedact(t *testing.T) {
 	}
 }
 
+func TestKeysOnly(t *testing.T) {
+	got := KeysOnly([]string{"PORT=8080", "API_KEY=sk_live_abc", "BARE", "EMPTY=", "URL=a=b"})
+	want := []string{"PORT=<redacted>", "API_KEY=<redacted>", "BARE", "EMPTY=<redacted>", "URL=<redacted>"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("KeysOnly()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
 func TestSendFiresHTTPRequest(t *testing.T) {
 	var received Event
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
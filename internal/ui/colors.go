@@ -25,6 +25,8 @@ const (
 	green  = "\033[32m"
 	yellow = "\033[33m"
 	blue   = "\033[34m"
+	purple = "\033[35m"
+	cyan   = "\033[36m"
 	bold   = "\033[1m"
 	dim    = "\033[2m"
 )
@@ -41,6 +43,8 @@ func Red(s string) string    { return colorize(red, s) }
 func Green(s string) string  { return colorize(green, s) }
 func Yellow(s string) string { return colorize(yellow, s) }
 func Blue(s string) string   { return colorize(blue, s) }
+func Purple(s string) string { return colorize(purple, s) }
+func Cyan(s string) string   { return colorize(cyan, s) }
 func Bold(s string) string   { return colorize(bold, s) }
 func Dim(s string) string    { return colorize(dim, s) }
 
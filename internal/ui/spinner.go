@@ -36,16 +36,16 @@ func (s *Spinner) Start() {
 	s.done.Add(1)
 	go func() {
 		defer s.done.Done()
-		i := 0
-		for {
+		ticker := time.NewTicker(80 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], s.message)
+			// Wait for the next frame, but stop right away when asked to
 			select {
 			case <-s.stop:
 				fmt.Fprintf(os.Stderr, "\r\033[K") // Clear the line
 				return
-			default:
-				fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], s.message)
-				i++
-				time.Sleep(80 * time.Millisecond)
+			case <-ticker.C:
 			}
 		}
 	}()